@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/greysquirr3l/glyphic/internal/bip39"
+	"github.com/greysquirr3l/glyphic/internal/security"
+)
+
+// bip39Flags holds the flags shared by the bip39 subcommands
+type bip39Flags struct {
+	language       string
+	seed           bool
+	passphraseFile string
+}
+
+// newBIP39FlagSet creates the flag set for a bip39 subcommand
+func newBIP39FlagSet(name string, stderr io.Writer) (*flag.FlagSet, *bip39Flags) {
+	f := &bip39Flags{}
+	names := make([]string, 0, len(bip39.Languages()))
+	for _, lang := range bip39.Languages() {
+		names = append(names, string(lang))
+	}
+
+	flagSet := flag.NewFlagSet("glyphic bip39 "+name, flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	flagSet.StringVar(&f.language, "language", string(bip39.English), "wordlist language: "+strings.Join(names, ", "))
+	flagSet.BoolVar(&f.seed, "seed", false, "also print the hex PBKDF2-HMAC-SHA512 seed")
+	flagSet.StringVar(&f.passphraseFile, "passphrase-file", "", "file holding the optional BIP-39 passphrase for --seed")
+	return flagSet, f
+}
+
+// printSeed writes the hex seed of mnemonic, reading the passphrase from the
+// configured file, and zeroes the seed afterwards
+func (f *bip39Flags) printSeed(w io.Writer, mnemonic string) error {
+	var passphrase string
+	if f.passphraseFile != "" {
+		data, err := os.ReadFile(f.passphraseFile)
+		if err != nil {
+			return fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		passphrase = strings.TrimRight(string(data), "\r\n")
+		security.SecureZero(data)
+	}
+
+	seed := bip39.Seed(mnemonic, passphrase)
+	defer security.SecureZero(seed)
+
+	_, err := fmt.Fprintln(w, hex.EncodeToString(seed))
+	return err
+}
+
+// runBIP39 implements the "glyphic bip39 generate|validate" subcommands
+func runBIP39(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		_, _ = fmt.Fprintln(stderr, "usage: glyphic bip39 generate|validate [flags]")
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "generate":
+		return runBIP39Generate(args[1:], stdout, stderr)
+	case "validate":
+		return runBIP39Validate(args[1:], stdin, stdout, stderr)
+	default:
+		_, _ = fmt.Fprintf(stderr, "glyphic: unknown bip39 command %q\n", args[0])
+		return exitUsage
+	}
+}
+
+// runBIP39Generate prints a new mnemonic and, with --seed, its seed
+func runBIP39Generate(args []string, stdout, stderr io.Writer) int {
+	flagSet, flags := newBIP39FlagSet("generate", stderr)
+	bits := flagSet.Int("bits", bip39.MaxEntropyBits, "entropy bits: 128, 160, 192, 224 or 256")
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	lang, err := bip39.ParseLanguage(flags.language)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v: --language: %v\n", errInvalidFlag, err)
+		return exitUsage
+	}
+
+	mnemonic, err := bip39.NewMnemonic(nil, *bits, lang)
+	switch {
+	case errors.Is(err, bip39.ErrInvalidEntropy):
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v: --bits: %v\n", errInvalidFlag, err)
+		return exitUsage
+	case errors.Is(err, security.ErrCryptoRandFailed):
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitPRNG
+	case err != nil:
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitFailure
+	}
+
+	if _, err := fmt.Fprintln(stdout, mnemonic); err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: failed to write output: %v\n", err)
+		return exitFailure
+	}
+	if flags.seed {
+		if err := flags.printSeed(stdout, mnemonic); err != nil {
+			_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+			return exitFailure
+		}
+	}
+	return exitOK
+}
+
+// runBIP39Validate checks a mnemonic given as arguments or on stdin and prints
+// its normalised form and, with --seed, its seed
+func runBIP39Validate(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flagSet, flags := newBIP39FlagSet("validate", stderr)
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	lang, err := bip39.ParseLanguage(flags.language)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v: --language: %v\n", errInvalidFlag, err)
+		return exitUsage
+	}
+
+	mnemonic := strings.Join(flagSet.Args(), " ")
+	if flagSet.NArg() == 0 {
+		input, err := io.ReadAll(stdin)
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "glyphic: failed to read input: %v\n", err)
+			return exitFailure
+		}
+		mnemonic = string(input)
+	}
+
+	if err := bip39.Validate(mnemonic, lang); err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitFailure
+	}
+
+	mnemonic = bip39.Normalize(mnemonic)
+	if _, err := fmt.Fprintln(stdout, mnemonic); err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: failed to write output: %v\n", err)
+		return exitFailure
+	}
+	if flags.seed {
+		if err := flags.printSeed(stdout, mnemonic); err != nil {
+			_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+			return exitFailure
+		}
+	}
+	return exitOK
+}
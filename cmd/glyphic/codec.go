@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/greysquirr3l/glyphic/internal/wordlist"
+)
+
+// codecFlags holds the flags shared by encode and decode
+type codecFlags struct {
+	list     string
+	path     string
+	checksum bool
+	hex      bool
+}
+
+// newCodecFlagSet creates the flag set for the encode or decode command
+func newCodecFlagSet(name string, stderr io.Writer) (*flag.FlagSet, *codecFlags) {
+	f := &codecFlags{}
+	flagSet := flag.NewFlagSet("glyphic "+name, flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	flagSet.StringVar(&f.list, "list", "eff-large", "built-in wordlist ID to encode with; both sides must use the same list")
+	flagSet.StringVar(&f.path, "wordlist", "", "wordlist file to encode with instead of --list")
+	flagSet.BoolVar(&f.checksum, "checksum", false, "append or verify a checksum word")
+	return flagSet, f
+}
+
+// codec builds a codec over the selected built-in list or wordlist file
+func (f *codecFlags) codec() (*wordlist.Codec, error) {
+	if f.path == "" {
+		// Built-in lists encode over their frozen word arrays, in any language
+		return wordlist.BuiltinCodec(f.list, f.checksum)
+	}
+
+	manager, err := wordlist.NewManager("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create wordlist manager: %w", err)
+	}
+	const id = "user-codec"
+	if err := manager.AddUserWordlist(f.path, id); err != nil {
+		return nil, fmt.Errorf("failed to add wordlist %s: %w", f.path, err)
+	}
+	list, ok := manager.Loaded(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not available", wordlist.ErrSourceNotFound, f.path)
+	}
+	return wordlist.NewCodec(list, f.checksum)
+}
+
+// readInput returns the contents of the file named by args, or stdin when
+// args is empty or "-"
+func readInput(args []string, stdin io.Reader) ([]byte, error) {
+	switch {
+	case len(args) == 0, len(args) == 1 && args[0] == "-":
+		return io.ReadAll(stdin)
+	case len(args) == 1:
+		return os.ReadFile(args[0])
+	default:
+		return nil, fmt.Errorf("%w: expected at most one input file", errInvalidFlag)
+	}
+}
+
+// runEncode implements "glyphic encode", printing the words for a key
+func runEncode(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flagSet, flags := newCodecFlagSet("encode", stderr)
+	flagSet.BoolVar(&flags.hex, "hex", false, "read the input as hex digits, ignoring whitespace and colons")
+	separator := flagSet.String("separator", " ", "string written between words")
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	data, err := readInput(flagSet.Args(), stdin)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitUsage
+	}
+	if flags.hex {
+		digits := strings.Map(func(r rune) rune {
+			if r == ':' || strings.ContainsRune(" \t\r\n", r) {
+				return -1
+			}
+			return r
+		}, string(data))
+		if data, err = hex.DecodeString(digits); err != nil {
+			_, _ = fmt.Fprintf(stderr, "glyphic: %v: --hex input: %v\n", errInvalidFlag, err)
+			return exitUsage
+		}
+	}
+
+	codec, err := flags.codec()
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitWordlist
+	}
+
+	if _, err := fmt.Fprintln(stdout, strings.Join(codec.Encode(data), *separator)); err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: failed to write output: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// runDecode implements "glyphic decode", recovering a key from its words given
+// as arguments or on stdin
+func runDecode(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flagSet, flags := newCodecFlagSet("decode", stderr)
+	flagSet.BoolVar(&flags.hex, "hex", false, "write the decoded bytes as hex")
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	text := strings.Join(flagSet.Args(), " ")
+	if flagSet.NArg() == 0 {
+		input, err := io.ReadAll(stdin)
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "glyphic: failed to read input: %v\n", err)
+			return exitFailure
+		}
+		text = string(input)
+	}
+
+	codec, err := flags.codec()
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitWordlist
+	}
+
+	data, err := codec.Decode(text)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitFailure
+	}
+
+	if flags.hex {
+		_, err = fmt.Fprintln(stdout, hex.EncodeToString(data))
+	} else {
+		_, err = stdout.Write(data)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: failed to write output: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/greysquirr3l/glyphic/internal/config"
+)
+
+// metaFlags select configuration or must be given explicitly, so they cannot
+// be set from config files or the environment
+var metaFlags = map[string]bool{
+	"config":            true,
+	"profile":           true,
+	"version":           true,
+	"insecure-unpinned": true,
+	"insecure-seed":     true,
+}
+
+// pathFlags hold file paths that may use a leading ~/ in config and env values
+var pathFlags = map[string]bool{
+	"wordlist":     true,
+	"exclude-file": true,
+}
+
+// languageMinWordlists is the built-in --min-wordlists of each language: the
+// number of default lists it ships, so that no default is lowered behind the
+// user's back. Other languages, and "all", keep the flag default.
+var languageMinWordlists = map[string]int{
+	"en": 2, // eff-large and eff-short-2
+	"de": 2, // diceware-de and diceware-de-short
+	"es": 1, // one BIP-39 list each
+	"fr": 1,
+	"it": 1,
+	"ja": 1,
+}
+
+// configurableKeys returns the sorted names of all flags that can be set from config or env
+func configurableKeys(flagSet *flag.FlagSet) []string {
+	var keys []string
+	flagSet.VisitAll(func(f *flag.Flag) {
+		if !metaFlags[f.Name] {
+			keys = append(keys, f.Name)
+		}
+	})
+	return keys
+}
+
+// configTarget resolves the config file path and profile from flags and env.
+// The returned bool reports whether the path was chosen explicitly.
+func configTarget(flags *cliFlags, lookupEnv func(string) (string, bool)) (string, string, bool, error) {
+	profile := flags.profile
+	if profile == "" {
+		profile, _ = lookupEnv(config.EnvName("profile"))
+	}
+
+	if flags.configPath != "" {
+		return flags.configPath, profile, true, nil
+	}
+	if path, ok := lookupEnv(config.EnvName("config")); ok && path != "" {
+		return path, profile, true, nil
+	}
+
+	path, err := config.DefaultPath()
+	return path, profile, false, err
+}
+
+// loadConfigFile loads the config file, treating a missing default file as empty
+func loadConfigFile(path string, explicit bool) (*config.File, error) {
+	file, err := config.Load(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return nil, nil
+	}
+	return file, err
+}
+
+// applyConfig layers the config file and environment beneath the flags that
+// were set explicitly, and returns where every configurable setting came from
+func applyConfig(flagSet *flag.FlagSet, flags *cliFlags, lookupEnv func(string) (string, bool)) (map[string]config.Setting, error) {
+	keys := configurableKeys(flagSet)
+	known := func(key string) bool { return slices.Contains(keys, key) }
+
+	defaults := config.Layer{Source: config.SourceDefault, Origin: "built-in", Values: make(map[string][]string)}
+	for _, key := range keys {
+		if def := flagSet.Lookup(key).DefValue; def != "" {
+			defaults.Values[key] = []string{def}
+		}
+	}
+
+	explicit := config.Layer{Source: config.SourceFlag, Origin: "command line", Values: make(map[string][]string)}
+	flagSet.Visit(func(f *flag.Flag) {
+		if metaFlags[f.Name] {
+			return
+		}
+		if list, ok := f.Value.(*stringList); ok {
+			explicit.Values[f.Name] = slices.Clone(*list)
+		} else {
+			explicit.Values[f.Name] = []string{f.Value.String()}
+		}
+	})
+
+	path, profile, explicitPath, err := configTarget(flags, lookupEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := loadConfigFile(path, explicitPath)
+	if err != nil {
+		return nil, err
+	}
+
+	layers := []config.Layer{defaults}
+	if file != nil {
+		if err := file.CheckKeys(known); err != nil {
+			return nil, err
+		}
+		fileLayers, err := file.Layers(profile)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, fileLayers...)
+	} else if profile != "" {
+		return nil, fmt.Errorf("%w: %q (no config file at %s)", config.ErrUnknownProfile, profile, path)
+	}
+	layers = append(layers, config.EnvLayer(keys, lookupEnv), explicit)
+
+	resolved := config.Resolve(layers...)
+	if err := applyLanguageDefaults(flagSet, flags, resolved); err != nil {
+		return nil, err
+	}
+	for _, key := range slices.Sorted(maps.Keys(resolved)) {
+		setting := resolved[key]
+		if setting.Source == config.SourceDefault || setting.Source == config.SourceFlag {
+			continue
+		}
+
+		values := setting.Values
+		if _, ok := flagSet.Lookup(key).Value.(*stringList); ok && setting.Source == config.SourceEnv {
+			values = filepath.SplitList(values[0])
+		}
+
+		for _, value := range values {
+			if pathFlags[key] {
+				value = expandHome(value)
+			}
+			if err := flagSet.Set(key, value); err != nil {
+				return nil, fmt.Errorf("%w: %s from %s: %w", errInvalidFlag, key, setting.Origin, err)
+			}
+		}
+	}
+
+	return resolved, nil
+}
+
+// applyLanguageDefaults replaces the built-in defaults that depend on the
+// language of the default wordlists, once the language itself is resolved
+func applyLanguageDefaults(flagSet *flag.FlagSet, flags *cliFlags, resolved map[string]config.Setting) error {
+	language := flags.language
+	if setting, ok := resolved["language"]; ok {
+		language = setting.Values[len(setting.Values)-1]
+	}
+
+	n, ok := languageMinWordlists[language]
+	if !ok || resolved["min-wordlists"].Source != config.SourceDefault {
+		return nil
+	}
+	value := strconv.Itoa(n)
+	if err := flagSet.Set("min-wordlists", value); err != nil {
+		return err
+	}
+	resolved["min-wordlists"] = config.Setting{
+		Key:    "min-wordlists",
+		Values: []string{value},
+		Source: config.SourceDefault,
+		Origin: "built-in for " + language,
+	}
+	return nil
+}
+
+// expandHome replaces a leading ~/ with the user's home directory
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, rest)
+}
+
+// runConfig implements the "glyphic config show|validate|init" subcommands
+func runConfig(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		_, _ = fmt.Fprintln(stderr, "usage: glyphic config show|validate|init [flags]")
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "show":
+		return runConfigShow(args[1:], stdout, stderr)
+	case "validate":
+		return runConfigValidate(args[1:], stdout, stderr)
+	case "init":
+		return runConfigInit(args[1:], stdout, stderr)
+	default:
+		_, _ = fmt.Fprintf(stderr, "glyphic: unknown config command %q\n", args[0])
+		return exitUsage
+	}
+}
+
+// runConfigShow prints every configurable setting with the layer it came from
+func runConfigShow(args []string, stdout, stderr io.Writer) int {
+	flagSet, flags := newFlagSet(stderr)
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	resolved, err := applyConfig(flagSet, flags, os.LookupEnv)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitUsage
+	}
+
+	path, profile, _, _ := configTarget(flags, os.LookupEnv)
+	_, _ = fmt.Fprintf(stdout, "Config:  %s\n", path)
+	if profile != "" {
+		_, _ = fmt.Fprintf(stdout, "Profile: %s\n", profile)
+	}
+	_, _ = fmt.Fprintln(stdout)
+
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SETTING\tVALUE\tSOURCE\tORIGIN")
+	for _, key := range configurableKeys(flagSet) {
+		setting, ok := resolved[key]
+		if !ok {
+			setting = config.Setting{Source: config.SourceDefault, Origin: "built-in"}
+		}
+		value := flagSet.Lookup(key).Value.String()
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", key, value, setting.Source, setting.Origin)
+	}
+	if err := tw.Flush(); err != nil {
+		return exitFailure
+	}
+
+	return exitOK
+}
+
+// runConfigValidate checks the config file and every profile in it
+func runConfigValidate(args []string, stdout, stderr io.Writer) int {
+	flagSet, flags := newFlagSet(stderr)
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	path, _, _, err := configTarget(flags, os.LookupEnv)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitUsage
+	}
+
+	file, err := config.Load(path)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitUsage
+	}
+
+	if err := file.CheckKeys(func(key string) bool {
+		return slices.Contains(configurableKeys(flagSet), key)
+	}); err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitUsage
+	}
+
+	// Validate the default selection and every profile, ignoring env and flags
+	failed := false
+	noEnv := func(string) (string, bool) { return "", false }
+	for _, profile := range append([]string{""}, file.ProfileNames()...) {
+		name := "default"
+		if profile != "" {
+			name = "profile " + profile
+		}
+
+		checkSet, checkFlags := newFlagSet(io.Discard)
+		checkFlags.configPath = path
+		checkFlags.profile = profile
+
+		if err := validateLayers(checkSet, checkFlags, noEnv); err != nil {
+			_, _ = fmt.Fprintf(stderr, "%s: %v\n", name, err)
+			failed = true
+			continue
+		}
+		_, _ = fmt.Fprintf(stdout, "%s: ok\n", name)
+	}
+
+	if failed {
+		return exitUsage
+	}
+	return exitOK
+}
+
+// validateLayers applies config layers to a fresh flag set and validates the resulting options
+func validateLayers(flagSet *flag.FlagSet, flags *cliFlags, lookupEnv func(string) (string, bool)) error {
+	if _, err := applyConfig(flagSet, flags, lookupEnv); err != nil {
+		return err
+	}
+	if _, err := flags.generatorOptions(); err != nil {
+		return err
+	}
+	_, err := flags.revealOptions()
+	return err
+}
+
+// runConfigInit writes the default config template
+func runConfigInit(args []string, stdout, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("glyphic config init", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	configPath := flagSet.String("config", "", "config file to create (default $XDG_CONFIG_HOME/glyphic/config.toml)")
+	force := flagSet.Bool("force", false, "overwrite an existing config file")
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	path := *configPath
+	if path == "" {
+		var err error
+		if path, err = config.DefaultPath(); err != nil {
+			_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+			return exitFailure
+		}
+	}
+
+	if err := config.Init(path, *force); err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		if errors.Is(err, config.ErrConfigExists) {
+			return exitUsage
+		}
+		return exitFailure
+	}
+
+	_, _ = fmt.Fprintf(stdout, "Wrote %s\n", path)
+	return exitOK
+}
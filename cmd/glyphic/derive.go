@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+
+	"github.com/greysquirr3l/glyphic/internal/generator"
+	"github.com/greysquirr3l/glyphic/internal/security"
+	"github.com/greysquirr3l/glyphic/internal/wordlist"
+)
+
+// deriveWordlist is the only list derivation version 1 draws from
+const deriveWordlist = "eff-large"
+
+// runDerive implements "glyphic derive", printing the password for a site
+// derived from a master passphrase. Config files and environment variables are
+// ignored so the same flags always give the same password.
+func runDerive(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	defaults := generator.DefaultOptions
+	flags := &cliFlags{strategy: generator.StrategyDiceware.String(), placement: generator.PlaceEnd.String(), count: 1, minWordlists: 1}
+
+	flagSet := flag.NewFlagSet("glyphic derive", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	site := flagSet.String("site", "", "site or host name (case-insensitive)")
+	user := flagSet.String("user", "", "account name on the site")
+	counter := flagSet.Uint("counter", 1, "bump to rotate the password")
+	flagSet.IntVar(&flags.words, "words", defaults.WordCount, "number of words per password (3-12)")
+	flagSet.StringVar(&flags.capitalize, "capitalize", defaults.Capitalization.String(), "capitalization: none, first, random, all, alternating")
+	flagSet.StringVar(&flags.separator, "separator", defaults.Separator.String(), "separator: none, space, dash, underscore, custom")
+	flagSet.StringVar(&flags.customSeparator, "custom-separator", "", "separator string when --separator custom")
+	flagSet.BoolVar(&flags.numbers, "numbers", defaults.AddNumbers, "append digits")
+	flagSet.IntVar(&flags.numberCount, "number-count", defaults.NumberCount, "number of digits to append (1-4)")
+	flagSet.BoolVar(&flags.special, "special", defaults.AddSpecial, "append special characters")
+	flagSet.IntVar(&flags.specialCount, "special-count", defaults.SpecialCount, "number of special characters to append (1-4)")
+	flagSet.StringVar(&flags.policy, "policy", "", "password policy to satisfy: "+strings.Join(generator.PolicyNames(), ", "))
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	if *counter > 1<<32-1 {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v: --counter must fit in 32 bits\n", errInvalidFlag)
+		return exitUsage
+	}
+	opts, err := flags.generatorOptions()
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitUsage
+	}
+
+	gen, err := newDeriveGenerator(ctx)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitWordlist
+	}
+
+	master, err := readMaster(stdin, stderr)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: failed to read master passphrase: %v\n", err)
+		return exitFailure
+	}
+	defer security.SecureZero(master)
+
+	password, err := gen.Derive(master, generator.Site{Name: *site, Username: *user, Counter: uint32(*counter)}, opts)
+	switch {
+	case errors.Is(err, generator.ErrEmptyMaster), errors.Is(err, generator.ErrEmptySite):
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitUsage
+	case err != nil:
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitCodeFor(err)
+	}
+
+	if _, err := fmt.Fprintln(stdout, password); err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: failed to write output: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// newDeriveGenerator builds a generator over the pinned list of derivation
+// version 1 with exclusions disabled, so embedded exclusion updates never
+// change derived passwords
+func newDeriveGenerator(ctx context.Context) (*generator.Generator, error) {
+	manager, err := wordlist.NewManager("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create wordlist manager: %w", err)
+	}
+	if err := manager.UseSources(deriveWordlist); err != nil {
+		return nil, err
+	}
+	if err := manager.EnsureWordlists(ctx); err != nil {
+		return nil, fmt.Errorf("failed to fetch wordlists: %w", err)
+	}
+	if err := manager.LoadAll(); err != nil {
+		return nil, fmt.Errorf("failed to load wordlists: %w", err)
+	}
+	return generator.New(manager, wordlist.NewExclusionList(false)), nil
+}
+
+// readMaster reads the master passphrase without echo from a terminal, or the
+// first line of stdin otherwise
+func readMaster(stdin io.Reader, stderr io.Writer) ([]byte, error) {
+	if file, ok := stdin.(*os.File); ok && term.IsTerminal(file.Fd()) {
+		_, _ = fmt.Fprint(stderr, "Master passphrase: ")
+		master, err := term.ReadPassword(file.Fd())
+		_, _ = fmt.Fprintln(stderr)
+		return master, err
+	}
+
+	line, err := bufio.NewReader(stdin).ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		security.SecureZero(line)
+		return nil, err
+	}
+	return bytes.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/greysquirr3l/glyphic/internal/generator"
+	"github.com/greysquirr3l/glyphic/internal/tui"
+	"github.com/greysquirr3l/glyphic/internal/wordlist"
+)
+
+// errInvalidFlag indicates a flag value that cannot be mapped onto options
+var errInvalidFlag = errors.New("invalid flag value")
+
+// stringList is a repeatable string flag
+type stringList []string
+
+// String implements flag.Value
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+// Set implements flag.Value
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// cliFlags holds the raw command-line flag values
+type cliFlags struct {
+	strategy         string
+	words            int
+	length           int
+	classes          string
+	noAmbiguous      bool
+	pinLength        int
+	noRepeat         bool
+	noSequence       bool
+	tokenBits        int
+	tokenEncoding    string
+	syllables        int
+	template         string
+	substitute       string
+	substituteAll    bool
+	placement        string
+	targetEntropy    float64
+	dice             bool
+	capitalize       string
+	separator        string
+	customSeparator  string
+	numbers          bool
+	numberCount      int
+	special          bool
+	specialCount     int
+	count            int
+	noReveal         bool
+	quiet            bool
+	color            string
+	speed            string
+	entropy          bool
+	wordlists        stringList
+	noDefaults       bool
+	language         string
+	foldDiacritics   bool
+	minWordlists     int
+	weightBySize     bool
+	policy           string
+	excludeFiles     stringList
+	noExclusions     bool
+	wordlistTTL      time.Duration
+	insecureUnpinned bool
+	insecureSeed     string
+	version          bool
+	configPath       string
+	profile          string
+}
+
+// newFlagSet creates the flag set for the generate command
+func newFlagSet(output io.Writer) (*flag.FlagSet, *cliFlags) {
+	f := &cliFlags{}
+	defaults := generator.DefaultOptions
+
+	fs := flag.NewFlagSet("glyphic", flag.ContinueOnError)
+	fs.SetOutput(output)
+
+	fs.StringVar(&f.strategy, "strategy", defaults.Strategy.String(), "password strategy: diceware, charset, pin, token, pronounceable")
+	fs.IntVar(&f.words, "words", defaults.WordCount, "number of words per password (3-12)")
+	fs.IntVar(&f.length, "length", defaults.Length, "characters per charset password (8-128)")
+	fs.StringVar(&f.classes, "classes", defaults.Classes.String(), "charset classes, each used at least once: lower, upper, digits, symbols")
+	fs.BoolVar(&f.noAmbiguous, "no-ambiguous", defaults.NoAmbiguous, "leave look-alike characters ("+generator.AmbiguousChars+") out of charset passwords")
+	fs.IntVar(&f.pinLength, "pin-length", defaults.PINLength, "digits per PIN (4-12)")
+	fs.BoolVar(&f.noRepeat, "no-repeat", defaults.NoRepeat, "forbid a PIN digit directly followed by itself")
+	fs.BoolVar(&f.noSequence, "no-sequence", defaults.NoSequence, "forbid three ascending or descending PIN digits in a row")
+	fs.IntVar(&f.tokenBits, "token-bits", defaults.TokenBits, "random bits per token (64-1024, multiple of 8)")
+	fs.StringVar(&f.tokenEncoding, "token-encoding", defaults.TokenEncoding.String(), "token encoding: hex, base32, base64url")
+	fs.IntVar(&f.syllables, "syllables", defaults.Syllables, "syllables per pronounceable word (1-6)")
+	fs.StringVar(&f.template, "template", defaults.Template, "sentence template such as \"{adj} {noun} {verb} {adv}\", filled from tagged wordlists")
+	fs.Float64Var(&f.targetEntropy, "target-entropy", 0, "choose the word count (and digit/special counts when enabled) to reach this many bits")
+	fs.BoolVar(&f.dice, "dice", false, "build the passphrase from physical dice rolls read from stdin instead of crypto/rand")
+	fs.StringVar(&f.capitalize, "capitalize", defaults.Capitalization.String(), "capitalization: none, first, random, all, alternating")
+	fs.StringVar(&f.separator, "separator", defaults.Separator.String(), "separator: none, space, dash, underscore, custom")
+	fs.StringVar(&f.customSeparator, "custom-separator", "", "separator string when --separator custom")
+	fs.StringVar(&f.substitute, "substitute", "", "letter substitutions after capitalization: "+strings.Join(generator.SubstitutionNames(), ", ")+" or a table such as a=4@,e=3")
+	fs.BoolVar(&f.substituteAll, "substitute-all", false, "replace every letter in the substitution table instead of choosing at random (adds no entropy)")
+	fs.StringVar(&f.placement, "placement", generator.PlaceEnd.String(), "where digits and special characters go: end, between, anywhere")
+	fs.BoolVar(&f.numbers, "numbers", defaults.AddNumbers, "append random digits")
+	fs.IntVar(&f.numberCount, "number-count", defaults.NumberCount, "number of digits to append (1-4)")
+	fs.BoolVar(&f.special, "special", defaults.AddSpecial, "append random special characters")
+	fs.IntVar(&f.specialCount, "special-count", defaults.SpecialCount, "number of special characters to append (1-4)")
+	fs.IntVar(&f.count, "count", 1, "number of passwords to generate")
+	fs.BoolVar(&f.noReveal, "no-reveal", false, "skip the reveal animation")
+	fs.BoolVar(&f.quiet, "quiet", false, "suppress animations and informational output")
+	fs.StringVar(&f.color, "color", tui.DefaultRevealOptions.Scheme.Name, "color scheme: "+strings.Join(tui.SchemeNames(), ", "))
+	fs.StringVar(&f.speed, "speed", "normal", "animation speed: slow, normal, fast")
+	fs.BoolVar(&f.entropy, "entropy", false, "show entropy calculation")
+	fs.Var(&f.wordlists, "wordlist", "additional wordlist file (repeatable)")
+	fs.BoolVar(&f.noDefaults, "no-defaults", false, "do not load the default wordlists")
+	fs.StringVar(&f.language, "language", wordlist.DefaultLanguage, "language of the default wordlists: "+strings.Join(wordlist.Languages(), ", ")+" or all; es, fr, it and ja have one 2048-word BIP-39 list each, worth 11 bits per word against 12.9 for en and de, and no --dice support")
+	fs.BoolVar(&f.foldDiacritics, "fold-diacritics", false, "strip accents from wordlist letters so passphrases are easy to type (é becomes e)")
+	fs.IntVar(&f.minWordlists, "min-wordlists", defaults.MinWordlists, "minimum number of different wordlists to draw from")
+	fs.BoolVar(&f.weightBySize, "weight-by-size", defaults.WeightBySize, "pick larger wordlists proportionally more often")
+	fs.StringVar(&f.policy, "policy", "", "password policy to satisfy: "+strings.Join(generator.PolicyNames(), ", "))
+	fs.Var(&f.excludeFiles, "exclude-file", "additional exclusion list file (repeatable)")
+	fs.BoolVar(&f.noExclusions, "no-exclusions", false, "disable all word exclusions")
+	fs.DurationVar(&f.wordlistTTL, "wordlist-ttl", wordlist.DefaultTTL, "how long downloaded wordlists are used before they are revalidated, 0 for every run")
+	fs.BoolVar(&f.insecureUnpinned, "insecure-unpinned", false, "allow default wordlists without a pinned SHA-256 checksum")
+	fs.StringVar(&f.insecureSeed, "insecure-seed", "", "derive all randomness from this seed for reproducible audits (never for real passwords)")
+	fs.BoolVar(&f.version, "version", false, "print version information and exit")
+	fs.StringVar(&f.configPath, "config", "", "config file (default $XDG_CONFIG_HOME/glyphic/config.toml)")
+	fs.StringVar(&f.profile, "profile", "", "config profile to apply")
+
+	return fs, f
+}
+
+// generatorOptions maps the flags onto generator options and validates them
+func (f *cliFlags) generatorOptions() (generator.Options, error) {
+	capitalization, err := generator.ParseCapitalization(f.capitalize)
+	if err != nil {
+		return generator.Options{}, fmt.Errorf("%w: --capitalize: %w", errInvalidFlag, err)
+	}
+
+	separator, err := generator.ParseSeparator(f.separator)
+	if err != nil {
+		return generator.Options{}, fmt.Errorf("%w: --separator: %w", errInvalidFlag, err)
+	}
+	if separator == generator.SepCustom && f.customSeparator == "" {
+		return generator.Options{}, fmt.Errorf("%w: --separator custom requires --custom-separator", errInvalidFlag)
+	}
+
+	strategy, err := generator.ParseStrategy(f.strategy)
+	if err != nil {
+		return generator.Options{}, fmt.Errorf("%w: --strategy: %w", errInvalidFlag, err)
+	}
+
+	if f.count < 1 {
+		return generator.Options{}, fmt.Errorf("%w: --count must be at least 1", errInvalidFlag)
+	}
+
+	opts := generator.Options{
+		WordCount:      f.words,
+		Capitalization: capitalization,
+		AddNumbers:     f.numbers,
+		NumberCount:    f.numberCount,
+		AddSpecial:     f.special,
+		SpecialCount:   f.specialCount,
+		Separator:      separator,
+		CustomSep:      f.customSeparator,
+		MinWordlists:   f.minWordlists,
+		WeightBySize:   f.weightBySize,
+
+		TargetEntropyBits: f.targetEntropy,
+
+		Strategy:    strategy,
+		Length:      f.length,
+		NoAmbiguous: f.noAmbiguous,
+		PINLength:   f.pinLength,
+		NoRepeat:    f.noRepeat,
+		NoSequence:  f.noSequence,
+		TokenBits:   f.tokenBits,
+		Syllables:   f.syllables,
+		Template:    f.template,
+
+		SubstituteAll: f.substituteAll,
+		Language:      f.wordLanguage(),
+	}
+
+	if opts.Placement, err = generator.ParsePlacement(f.placement); err != nil {
+		return generator.Options{}, fmt.Errorf("%w: --placement: %w", errInvalidFlag, err)
+	}
+	if f.substitute != "" {
+		if opts.Substitutions, err = generator.ParseSubstitutions(f.substitute); err != nil {
+			return generator.Options{}, fmt.Errorf("%w: --substitute: %w", errInvalidFlag, err)
+		}
+	}
+
+	// Classes and encodings are only parsed for the strategy that uses them,
+	// so a config file may set them for all strategies
+	switch strategy {
+	case generator.StrategyCharset:
+		if opts.Classes, err = generator.ParseCharClasses(f.classes); err != nil {
+			return generator.Options{}, fmt.Errorf("%w: --classes: %w", errInvalidFlag, err)
+		}
+	case generator.StrategyToken:
+		if opts.TokenEncoding, err = generator.ParseTokenEncoding(f.tokenEncoding); err != nil {
+			return generator.Options{}, fmt.Errorf("%w: --token-encoding: %w", errInvalidFlag, err)
+		}
+	}
+
+	if f.policy != "" {
+		if opts.Policy, err = generator.LookupPolicy(f.policy); err != nil {
+			return generator.Options{}, fmt.Errorf("%w: --policy: %w", errInvalidFlag, err)
+		}
+	}
+
+	if err := opts.Validate(); err != nil {
+		return generator.Options{}, err
+	}
+
+	return opts, nil
+}
+
+// wordLanguage returns the --language value as a wordlist language, empty
+// for all languages
+func (f *cliFlags) wordLanguage() string {
+	if strings.EqualFold(f.language, "all") {
+		return ""
+	}
+	return f.language
+}
+
+// revealOptions maps the flags onto reveal animation options
+func (f *cliFlags) revealOptions() (tui.RevealOptions, error) {
+	scheme, ok := tui.LookupScheme(f.color)
+	if !ok {
+		return tui.RevealOptions{}, fmt.Errorf("%w: --color: unknown color scheme %q", errInvalidFlag, f.color)
+	}
+
+	speed, err := tui.ParseSpeed(f.speed)
+	if err != nil {
+		return tui.RevealOptions{}, fmt.Errorf("%w: --speed: %w", errInvalidFlag, err)
+	}
+
+	opts := tui.DefaultRevealOptions
+	opts.Scheme = scheme
+	opts.Speed = speed
+	opts.ShowEntropy = f.entropy
+
+	return opts, nil
+}
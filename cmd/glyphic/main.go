@@ -0,0 +1,471 @@
+// Command glyphic generates Diceware passphrases with a Matrix-style reveal animation.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/greysquirr3l/glyphic/internal/config"
+	"github.com/greysquirr3l/glyphic/internal/generator"
+	"github.com/greysquirr3l/glyphic/internal/security"
+	"github.com/greysquirr3l/glyphic/internal/tui"
+	"github.com/greysquirr3l/glyphic/internal/wordlist"
+	"github.com/greysquirr3l/glyphic/pkg/version"
+)
+
+// Exit codes
+const (
+	exitOK       = 0 // success
+	exitFailure  = 1 // generation or output failure
+	exitUsage    = 2 // invalid flags or options
+	exitWordlist = 3 // wordlists could not be fetched, loaded or filtered
+	exitPRNG     = 4 // crypto/rand failed validation
+)
+
+// validatePRNG checks crypto/rand before any subcommand runs; tests replace it
+// to simulate a failing PRNG
+var validatePRNG = security.ValidatePRNG
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run executes glyphic with the given arguments and returns the process exit code
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if err := validatePRNG(); err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitPRNG
+	}
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "config":
+			return runConfig(args[1:], stdout, stderr)
+		case "wordlist":
+			return runWordlist(ctx, args[1:], stdout, stderr)
+		case "encode":
+			return runEncode(args[1:], stdin, stdout, stderr)
+		case "decode":
+			return runDecode(args[1:], stdin, stdout, stderr)
+		case "derive":
+			return runDerive(ctx, args[1:], stdin, stdout, stderr)
+		case "bip39":
+			return runBIP39(args[1:], stdin, stdout, stderr)
+		}
+	}
+
+	fs, flags := newFlagSet(stderr)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	if flags.version {
+		info := version.GetBuildInfo()
+		_, _ = fmt.Fprintf(stdout, "glyphic %s (%s, built %s, %s)\n", info.Version, info.GitCommit, info.BuildTime, info.GoVersion)
+		return exitOK
+	}
+
+	settings, err := applyConfig(fs, flags, os.LookupEnv)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitUsage
+	}
+
+	opts, err := flags.generatorOptions()
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitUsage
+	}
+
+	revealOpts, err := flags.revealOptions()
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitUsage
+	}
+
+	gen, err := newGenerator(ctx, flags, opts.UsesWordlists(), stderr)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitWordlist
+	}
+	if settings["min-wordlists"].Source == config.SourceDefault && opts.UsesWordlists() && opts.Template == "" {
+		if opts.MinWordlists, err = confirmMinWordlists(gen, opts, flags, stdin, stderr); err != nil {
+			_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+			return exitCodeFor(err)
+		}
+	}
+
+	if flags.insecureSeed != "" {
+		_, _ = fmt.Fprintln(stderr, "glyphic: WARNING: --insecure-seed makes every password reproducible from the seed; never use them")
+		source := security.NewInsecureSeededSource(flags.insecureSeed)
+		gen.SetRandomSource(source)
+		revealOpts.Rand = source
+	}
+
+	if flags.dice {
+		return runDice(gen, opts, flags, revealOpts, stdin, stdout, stderr)
+	}
+
+	if opts.TargetEntropyBits > 0 {
+		if opts, err = gen.ResolveTarget(opts); err != nil {
+			_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+			return exitCodeFor(err)
+		}
+		if !flags.quiet {
+			if err := printTarget(stderr, gen, opts); err != nil {
+				_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+				return exitCodeFor(err)
+			}
+		}
+	}
+
+	// Passphrases are always estimated, so that weak ones are reported
+	var entropy *generator.EntropyBreakdown
+	if flags.entropy || opts.UsesWordlists() {
+		entropy, err = gen.EntropyBreakdown(opts)
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+			return exitCodeFor(err)
+		}
+	}
+	if entropy != nil && entropy.Weak() && !flags.quiet {
+		_, _ = fmt.Fprintf(stderr, "glyphic: warning: passphrases carry %.1f bits, below the %d-bit minimum; %s\n", entropy.Total, generator.MinPassphraseBits, strengthenHint(opts))
+	}
+	if flags.entropy {
+		revealOpts.EntropyBits = entropy.Total
+		for _, warning := range entropy.Warnings {
+			_, _ = fmt.Fprintf(stderr, "glyphic: warning: %s\n", warning)
+		}
+	}
+
+	animate := flags.count == 1 && !flags.noReveal && !flags.quiet && isTerminal(stdout)
+	if animate {
+		password, err := gen.Generate(opts)
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+			return exitCodeFor(err)
+		}
+		if err := tui.Reveal(password, revealOpts); err != nil {
+			_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+			return exitFailure
+		}
+		return exitOK
+	}
+
+	if flags.entropy {
+		printEntropy(stderr, entropy)
+	}
+
+	out := bufio.NewWriter(stdout)
+	batch := generator.BatchOptions{Count: flags.count}
+	if flags.insecureSeed != "" {
+		batch.Workers = 1 // keep the output order reproducible
+	}
+	showProgress := !flags.quiet && flags.count > generator.DefaultChunkSize && isTerminal(stderr)
+	if showProgress {
+		batch.Progress = func(done int) {
+			_, _ = fmt.Fprintf(stderr, "\rGenerated %d/%d", done, flags.count)
+		}
+	}
+
+	written, err := gen.WriteBatch(ctx, out, opts, batch)
+	if showProgress {
+		_, _ = fmt.Fprintln(stderr)
+	}
+	if flushErr := out.Flush(); err == nil && flushErr != nil {
+		err = fmt.Errorf("failed to write output: %w", flushErr)
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		_, _ = fmt.Fprintf(stderr, "glyphic: interrupted after %d passwords\n", written)
+		return exitFailure
+	case err != nil:
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitCodeFor(err)
+	}
+
+	return exitOK
+}
+
+// runDice builds passphrases from physical dice rolls read line by line from
+// stdin, prompting on stderr and asking again after an invalid or unusable roll
+func runDice(gen *generator.Generator, opts generator.Options, flags *cliFlags, revealOpts tui.RevealOptions, stdin io.Reader, stdout, stderr io.Writer) int {
+	slots, err := gen.DicePlan(opts)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitCodeFor(err)
+	}
+
+	if flags.entropy {
+		var bits float64
+		for _, slot := range slots {
+			bits += slot.Bits
+		}
+		revealOpts.EntropyBits = bits
+		printEntropy(stderr, &generator.EntropyBreakdown{Words: bits, Total: bits, Exact: true})
+	}
+
+	in := bufio.NewScanner(stdin)
+	for range flags.count {
+		rolls := make([]string, len(slots))
+		for i, slot := range slots {
+			for {
+				_, _ = fmt.Fprintf(stderr, "Word %d/%d (%s): roll %d dice: ", i+1, len(slots), slot.List.Source.ID, slot.Dice)
+				if !in.Scan() {
+					_, _ = fmt.Fprintln(stderr)
+					_, _ = fmt.Fprintln(stderr, "glyphic: dice input ended before every word was rolled")
+					return exitFailure
+				}
+				if _, err := gen.DiceWord(slot, in.Text()); err != nil {
+					_, _ = fmt.Fprintf(stderr, "glyphic: %v; roll again\n", err)
+					continue
+				}
+				rolls[i] = in.Text()
+				break
+			}
+		}
+
+		password, err := gen.GenerateFromDice(opts, rolls)
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+			return exitCodeFor(err)
+		}
+
+		if flags.count == 1 && !flags.noReveal && !flags.quiet && isTerminal(stdout) {
+			if err := tui.Reveal(password, revealOpts); err != nil {
+				_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+				return exitFailure
+			}
+			continue
+		}
+		if _, err := fmt.Fprintln(stdout, password); err != nil {
+			_, _ = fmt.Fprintf(stderr, "glyphic: failed to write output: %v\n", err)
+			return exitFailure
+		}
+	}
+	return exitOK
+}
+
+// printTarget reports the word and suffix counts, or the strategy length,
+// chosen for a target entropy and the bits they achieve
+func printTarget(w io.Writer, gen *generator.Generator, opts generator.Options) error {
+	bits, err := gen.EstimateEntropy(opts)
+	if err != nil {
+		return err
+	}
+
+	var parts []string
+	switch opts.Strategy {
+	case generator.StrategyCharset:
+		parts = append(parts, fmt.Sprintf("%d characters", opts.Length))
+	case generator.StrategyPIN:
+		parts = append(parts, fmt.Sprintf("%d digits", opts.PINLength))
+	case generator.StrategyToken:
+		parts = append(parts, fmt.Sprintf("%d-bit token", opts.TokenBits))
+	case generator.StrategyPronounceable:
+		parts = append(parts, fmt.Sprintf("%d pronounceable words", opts.WordCount))
+	default:
+		parts = append(parts, fmt.Sprintf("%d words", opts.WordCount))
+	}
+	if opts.AddNumbers {
+		parts = append(parts, fmt.Sprintf("%d digits", opts.NumberCount))
+	}
+	if opts.AddSpecial {
+		parts = append(parts, fmt.Sprintf("%d special characters", opts.SpecialCount))
+	}
+
+	_, err = fmt.Fprintf(w, "Target %.1f bits: %s, %.1f bits achieved\n", opts.TargetEntropyBits, strings.Join(parts, ", "), bits)
+	return err
+}
+
+// strengthenHint suggests how to raise the entropy of a weak passphrase
+func strengthenHint(opts generator.Options) string {
+	if opts.Template != "" {
+		return "add slots such as {num}, or load a --wordlist with more tagged words"
+	}
+	return "add words with --words or set --target-entropy"
+}
+
+// printEntropy writes the entropy total followed by its non-zero components
+func printEntropy(w io.Writer, b *generator.EntropyBreakdown) {
+	_, _ = fmt.Fprintf(w, "Entropy: %.1f bits\n", b.Total)
+	components := []struct {
+		name string
+		bits float64
+	}{
+		{"words", b.Words},
+		{"capitalization", b.Capitalization},
+		{"digits", b.Digits},
+		{"specials", b.Specials},
+		{"substitutions", b.Substitutions},
+		{"placement", b.Placement},
+		{"characters", b.Characters},
+	}
+	for _, c := range components {
+		if c.bits > 0 {
+			_, _ = fmt.Fprintf(w, "  %-15s %5.1f bits\n", c.name+":", c.bits)
+		}
+	}
+	if b.SyllableBits > 0 {
+		_, _ = fmt.Fprintf(w, "  %-15s %5.1f bits\n", "per syllable:", b.SyllableBits)
+	}
+}
+
+// newGenerator builds the wordlist manager, exclusion list and generator from
+// flags. Without useWords no wordlists are fetched or loaded.
+func newGenerator(ctx context.Context, flags *cliFlags, useWords bool, stderr io.Writer) (*generator.Generator, error) {
+	manager, err := wordlist.NewManager("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create wordlist manager: %w", err)
+	}
+	manager.SetAllowUnpinned(flags.insecureUnpinned)
+	manager.SetLanguage(flags.wordLanguage())
+	manager.SetFoldDiacritics(flags.foldDiacritics)
+	manager.SetTTL(flags.wordlistTTL)
+	if err := manager.LoadState(); err != nil {
+		return nil, fmt.Errorf("failed to load wordlist state: %w", err)
+	}
+
+	exclusions := wordlist.NewExclusionList(!flags.noExclusions)
+	if !flags.noExclusions {
+		for _, path := range flags.excludeFiles {
+			if err := exclusions.LoadFile(path); err != nil {
+				return nil, fmt.Errorf("failed to load exclusion file %s: %w", path, err)
+			}
+		}
+	}
+	if !useWords {
+		return generator.New(manager, exclusions), nil
+	}
+
+	if !flags.noDefaults {
+		if len(manager.ListSources()) == 0 && !flags.quiet {
+			_, _ = fmt.Fprintf(stderr, "glyphic: no default wordlists in language %q; add lists with --wordlist\n", flags.language)
+		}
+		if unpinned := manager.UnpinnedSources(); len(unpinned) > 0 && !flags.insecureUnpinned && !flags.quiet {
+			_, _ = fmt.Fprintf(stderr, "glyphic: skipping wordlists without a pinned checksum: %s (use --insecure-unpinned to allow)\n", strings.Join(unpinned, ", "))
+		}
+		if err := manager.EnsureWordlists(ctx); err != nil {
+			return nil, fmt.Errorf("failed to fetch wordlists: %w", err)
+		}
+		if err := manager.LoadAll(); err != nil {
+			return nil, fmt.Errorf("failed to load wordlists: %w", err)
+		}
+	}
+
+	seen := make(map[string]int)
+	for _, path := range flags.wordlists {
+		id := userWordlistID(path, seen)
+		if err := manager.AddUserWordlist(path, id); err != nil {
+			return nil, fmt.Errorf("failed to add wordlist %s: %w", path, err)
+		}
+	}
+
+	return generator.New(manager, exclusions), nil
+}
+
+// confirmMinWordlists asks on the terminal before drawing from fewer lists
+// than the built-in --min-wordlists of the language, which happens when lists
+// are disabled or could not be fetched. Templates draw from tagged words
+// rather than selected lists and skip it. Without a terminal to ask on, the
+// default is enforced like an explicit minimum.
+func confirmMinWordlists(gen *generator.Generator, opts generator.Options, flags *cliFlags, stdin io.Reader, stderr io.Writer) (int, error) {
+	available := gen.AvailableLists(opts, flags.dice)
+	if available == 0 || available >= opts.MinWordlists {
+		return opts.MinWordlists, nil
+	}
+
+	short := fmt.Errorf("%w: need %d, have %d (use --min-wordlists %d to draw from fewer)",
+		generator.ErrNotEnoughWordlists, opts.MinWordlists, available, available)
+	if !isInteractive(stdin, stderr) {
+		return 0, short
+	}
+
+	_, _ = fmt.Fprintf(stderr, "glyphic: only %d wordlists are available, fewer than the default --min-wordlists of %d; draw from %d? [y/N] ", available, opts.MinWordlists, available)
+	answer, _ := bufio.NewReader(stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return available, nil
+	default:
+		return 0, short
+	}
+}
+
+// userWordlistID derives a unique wordlist ID from a file path, dropping the
+// format and compression extensions
+func userWordlistID(path string, seen map[string]int) string {
+	id := "user-" + listBaseName(filepath.Base(path))
+
+	seen[id]++
+	if n := seen[id]; n > 1 {
+		id = fmt.Sprintf("%s-%d", id, n)
+	}
+	return id
+}
+
+// listBaseName strips the compression and format extensions from the file
+// name of a wordlist, "fruit" for "fruit.csv.gz"
+func listBaseName(base string) string {
+	for _, ext := range []string{".gz", ".zst"} {
+		if strings.EqualFold(filepath.Ext(base), ext) {
+			base = strings.TrimSuffix(base, filepath.Ext(base))
+		}
+	}
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// exitCodeFor maps a generation error onto an exit code
+func exitCodeFor(err error) int {
+	switch {
+	case errors.Is(err, generator.ErrNotEnoughWordlists),
+		errors.Is(err, generator.ErrNoWordsAvailable),
+		errors.Is(err, generator.ErrUnknownTag),
+		errors.Is(err, wordlist.ErrInsufficientLists):
+		return exitWordlist
+	case errors.Is(err, generator.ErrPolicyUnsatisfiable),
+		errors.Is(err, generator.ErrTargetUnreachable),
+		errors.Is(err, generator.ErrDiceUnsupported):
+		return exitUsage
+	case errors.Is(err, security.ErrCryptoRandFailed):
+		return exitPRNG
+	default:
+		return exitFailure
+	}
+}
+
+// isInteractive reports whether stdin and stderr are both terminals, so a
+// question can be asked and answered; tests replace it
+var isInteractive = func(stdin io.Reader, stderr io.Writer) bool {
+	in, ok := stdin.(*os.File)
+	return ok && isTerminal(in) && isTerminal(stderr)
+}
+
+// isTerminal reports whether w is a character device such as a TTY
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
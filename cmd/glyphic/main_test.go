@@ -0,0 +1,994 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/greysquirr3l/glyphic/internal/generator"
+	"github.com/greysquirr3l/glyphic/internal/security"
+	"github.com/greysquirr3l/glyphic/internal/tui"
+	"github.com/greysquirr3l/glyphic/internal/wordlist"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeWordlists creates n small wordlist files and returns their --wordlist flags
+func writeWordlists(t *testing.T, n int) []string {
+	t.Helper()
+
+	lists := [][]string{
+		{"apple", "banana", "cherry", "date", "elderberry"},
+		{"falcon", "goose", "hawk", "ibis", "jay"},
+		{"kale", "lettuce", "mustard", "napa", "okra"},
+	}
+
+	dir := t.TempDir()
+	var args []string
+	for i := range n {
+		path := filepath.Join(dir, "list"+string(rune('a'+i))+".txt")
+		require.NoError(t, os.WriteFile(path, []byte(strings.Join(lists[i], "\n")), 0600))
+		args = append(args, "--wordlist", path)
+	}
+	return args
+}
+
+func runGlyphic(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	return runGlyphicHome(t, t.TempDir(), args...)
+}
+
+// runGlyphicHome runs glyphic with HOME set to home
+func runGlyphicHome(t *testing.T, home string, args ...string) (int, string, string) {
+	t.Helper()
+	return runGlyphicInput(t, home, "", args...)
+}
+
+// runGlyphicInput runs glyphic with HOME set to home and input on stdin
+func runGlyphicInput(t *testing.T, home, input string, args ...string) (int, string, string) {
+	t.Helper()
+	t.Setenv("HOME", home)
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader(input), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunExitCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"help", []string{"--help"}, exitOK},
+		{"version", []string{"--version"}, exitOK},
+		{"unknown flag", []string{"--bogus"}, exitUsage},
+		{"word count out of range", []string{"--words", "2"}, exitUsage},
+		{"unknown capitalization", []string{"--capitalize", "shouting"}, exitUsage},
+		{"unknown separator", []string{"--separator", "comma"}, exitUsage},
+		{"custom separator missing", []string{"--separator", "custom"}, exitUsage},
+		{"unknown color", []string{"--color", "plaid"}, exitUsage},
+		{"unknown speed", []string{"--speed", "ludicrous"}, exitUsage},
+		{"zero count", []string{"--count", "0"}, exitUsage},
+		{"unknown policy", []string{"--policy", "nist"}, exitUsage},
+		{"no wordlists", []string{"--no-defaults", "--no-reveal"}, exitWordlist},
+		{"missing wordlist file", []string{"--no-defaults", "--wordlist", "/nonexistent/list.txt"}, exitWordlist},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, _ := runGlyphic(t, tt.args...)
+			assert.Equal(t, tt.want, code)
+		})
+	}
+}
+
+func TestRunValidatesPRNG(t *testing.T) {
+	failing := func() error { return errors.New("PRNG validation failed: test") }
+	t.Cleanup(func() { validatePRNG = security.ValidatePRNG })
+	validatePRNG = failing
+
+	for _, args := range [][]string{
+		{"--no-reveal"},
+		{"bip39", "generate"},
+		{"encode", "--hex", "00"},
+		{"derive", "--site", "example.com"},
+		{"wordlist", "list"},
+	} {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			code, stdout, stderr := runGlyphic(t, args...)
+			assert.Equal(t, exitPRNG, code)
+			assert.Empty(t, stdout)
+			assert.Contains(t, stderr, "PRNG validation failed")
+		})
+	}
+}
+
+func TestRunGeneratesPasswords(t *testing.T) {
+	args := append(writeWordlists(t, 3),
+		"--no-defaults", "--no-reveal",
+		"--count", "5",
+		"--words", "4",
+		"--capitalize", "none",
+		"--separator", "underscore",
+		"--numbers", "--number-count", "3",
+	)
+
+	code, stdout, stderr := runGlyphic(t, args...)
+	require.Equal(t, exitOK, code, stderr)
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	assert.Len(t, lines, 5)
+	for _, line := range lines {
+		assert.Regexp(t, `^[a-z]+(_[a-z]+){3}\d{3}$`, line)
+	}
+}
+
+func TestRunLargeBatch(t *testing.T) {
+	args := append(writeWordlists(t, 3), "--no-defaults", "--count", "5000", "--words", "3")
+
+	code, stdout, stderr := runGlyphic(t, args...)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, 5000, strings.Count(stdout, "\n"))
+}
+
+func TestRunInterrupted(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	args := append(writeWordlists(t, 3), "--no-defaults", "--count", "100000")
+	var stdout, stderr bytes.Buffer
+	code := run(ctx, args, strings.NewReader(""), &stdout, &stderr)
+	assert.Equal(t, exitFailure, code)
+	assert.Contains(t, stderr.String(), "interrupted after")
+}
+
+func TestRunEntropyGoesToStderr(t *testing.T) {
+	args := append(writeWordlists(t, 3), "--no-defaults", "--quiet", "--entropy")
+
+	code, stdout, stderr := runGlyphic(t, args...)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stderr, "Entropy:")
+	assert.Contains(t, stderr, "words:")
+	assert.Len(t, strings.Split(strings.TrimSpace(stdout), "\n"), 1)
+}
+
+func TestRunTargetEntropy(t *testing.T) {
+	lists := writeWordlists(t, 3)
+
+	// Five-word lists carry log2(5) bits per word, so 40 bits takes 18 words
+	args := append(lists, "--no-defaults", "--no-reveal", "--target-entropy", "40", "--separator", "space")
+	code, stdout, stderr := runGlyphic(t, args...)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stderr, "Target 40.0 bits: 18 words, 41.8 bits achieved")
+	assert.Len(t, strings.Fields(stdout), 18)
+
+	args = append(lists, "--no-defaults", "--no-reveal", "--target-entropy", "200")
+	code, _, stderr = runGlyphic(t, args...)
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "target entropy cannot be reached")
+}
+
+func TestRunPolicy(t *testing.T) {
+	lists := writeWordlists(t, 3)
+
+	args := append(lists, "--no-defaults", "--quiet", "--policy", "pci-dss")
+	code, _, stderr := runGlyphic(t, args...)
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "requires digit characters")
+
+	args = append(args, "--numbers", "--count", "20")
+	code, stdout, stderr := runGlyphic(t, args...)
+	require.Equal(t, exitOK, code, stderr)
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		assert.NoError(t, generator.PolicyPCIDSS.Check(line))
+	}
+}
+
+func TestRunInsecureSeed(t *testing.T) {
+	lists := writeWordlists(t, 3)
+	seeded := func(seed string) string {
+		args := slices.Concat(lists, []string{"--no-defaults", "--no-reveal", "--count", "20", "--insecure-seed", seed})
+		code, stdout, stderr := runGlyphic(t, args...)
+		require.Equal(t, exitOK, code, stderr)
+		assert.Contains(t, stderr, "WARNING")
+		return stdout
+	}
+
+	first := seeded("audit")
+	assert.Equal(t, first, seeded("audit"))
+	assert.NotEqual(t, first, seeded("other"))
+}
+
+func TestRunStrategies(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		pattern string
+		bits    string
+	}{
+		{name: "charset", args: []string{"--strategy", "charset", "--length", "16", "--classes", "lower,upper,digits", "--no-ambiguous"}, pattern: `^[a-zA-HJ-NP-Z2-9]{16}$`, bits: "characters:"},
+		{name: "pin", args: []string{"--strategy", "pin", "--no-repeat", "--no-sequence"}, pattern: `^[0-9]{6}$`},
+		{name: "token", args: []string{"--strategy", "token", "--token-bits", "128"}, pattern: `^[0-9a-f]{32}$`, bits: "128.0 bits"},
+		{name: "base64url", args: []string{"--strategy", "token", "--token-bits", "96", "--token-encoding", "base64url"}, pattern: `^[A-Za-z0-9_-]{16}$`, bits: "96.0 bits"},
+		{name: "pronounceable", args: []string{"--strategy", "pronounceable", "--words", "2", "--capitalize", "none", "--numbers"}, pattern: `^[a-z]{4,6}-[a-z]{4,6}[0-9]{2}$`, bits: "per syllable:"},
+		{name: "pin target", args: []string{"--strategy", "pin", "--target-entropy", "30"}, pattern: `^[0-9]{10}$`, bits: "33.2 bits"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No wordlists are needed, so --no-defaults is not required
+			args := append([]string{"--no-reveal", "--count", "5", "--entropy"}, tt.args...)
+			code, stdout, stderr := runGlyphic(t, args...)
+			require.Equal(t, exitOK, code, stderr)
+
+			lines := strings.Split(strings.TrimSpace(stdout), "\n")
+			assert.Len(t, lines, 5)
+			for _, line := range lines {
+				assert.Regexp(t, tt.pattern, line)
+			}
+			assert.Contains(t, stderr, "Entropy:")
+			if tt.bits != "" {
+				assert.Contains(t, stderr, tt.bits)
+			}
+		})
+	}
+
+	code, _, stderr := runGlyphic(t, "--strategy", "pin", "--numbers")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "only applies to diceware")
+
+	code, _, stderr = runGlyphic(t, "--strategy", "charset", "--classes", "lower,emoji")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "--classes")
+
+	code, _, _ = runGlyphic(t, "--strategy", "hex")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestRunTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tagged.txt")
+	require.NoError(t, os.WriteFile(path, []byte("brave adj\ncalm adj\notter noun\nheron noun\nswims verb\nslowly adv\nloudly adv\n"), 0600))
+
+	code, stdout, stderr := runGlyphic(t, "--wordlist", path, "--no-defaults", "--no-reveal", "--no-exclusions", "--count", "5",
+		"--template", "{num} {adj} {noun}s {verb} {adv}", "--capitalize", "none", "--entropy")
+	require.Equal(t, exitOK, code, stderr)
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	assert.Len(t, lines, 5)
+	for _, line := range lines {
+		assert.Regexp(t, `^[0-9]{1,2} (brave|calm) (otter|heron)s swims (slowly|loudly)$`, line)
+	}
+	assert.Contains(t, stderr, "Entropy: 9.6 bits")
+
+	code, _, stderr = runGlyphic(t, "--wordlist", path, "--no-defaults", "--no-reveal", "--template", "{adj} {pronoun}")
+	assert.Equal(t, exitWordlist, code)
+	assert.Contains(t, stderr, "{pronoun}")
+
+	code, _, _ = runGlyphic(t, "--wordlist", path, "--no-defaults", "--no-reveal", "--template", "{adj")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestRunTemplateDefaults(t *testing.T) {
+	if len(wordlist.EmbeddedIDs(wordlist.DefaultLanguage)) == 0 {
+		t.Skip("slim build has no embedded wordlists")
+	}
+
+	// eff-large is tagged by its embedded sidecar
+	code, stdout, stderr := runGlyphic(t, "--no-reveal", "--template", "{adj} {noun} {verb} {adv}", "--capitalize", "none", "--count", "5")
+	require.Equal(t, exitOK, code, stderr)
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		assert.Regexp(t, `^[a-z]+ [a-z]+ [a-z]+ [a-z]+ly$`, line)
+	}
+	assert.NotContains(t, stderr, "--min-wordlists")
+
+	// The sidecar pools carry about 30 bits for four slots, below the minimum
+	// for passphrases
+	assert.Contains(t, stderr, "warning: passphrases carry 30.1 bits, below the 50-bit minimum")
+	code, _, stderr = runGlyphic(t, "--no-reveal", "--quiet", "--template", "{adj} {noun} {verb} {adv}")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Empty(t, stderr)
+	code, _, stderr = runGlyphic(t, "--no-reveal", "--template", "{num} {adj} {noun} {verb} {adv} {adj} {noun}")
+	require.Equal(t, exitOK, code, stderr)
+	assert.NotContains(t, stderr, "warning")
+}
+
+func TestRunTransforms(t *testing.T) {
+	dir := t.TempDir()
+	var args []string
+	for name, data := range map[string]string{"a.txt": "bat\ncat\n", "b.txt": "dog\nhog\n", "c.txt": "eel\nelk\n"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+		args = append(args, "--wordlist", path)
+	}
+	args = append(args, "--no-defaults", "--no-reveal", "--no-exclusions", "--words", "3", "--min-wordlists", "3", "--capitalize", "none", "--separator", "space", "--count", "5")
+
+	code, stdout, stderr := runGlyphic(t, append(args, "--substitute", "leet", "--substitute-all")...)
+	require.Equal(t, exitOK, code, stderr)
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		assert.Regexp(t, `^(847|c47|d09|h09|33l|3lk)( (847|c47|d09|h09|33l|3lk)){2}$`, line)
+	}
+
+	code, stdout, stderr = runGlyphic(t, append(args, "--placement", "between", "--numbers", "--number-count", "1", "--entropy")...)
+	require.Equal(t, exitOK, code, stderr)
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		assert.Regexp(t, `^[0-9]?[a-z]{3}[0-9]? [0-9]?[a-z]{3}[0-9]? [0-9]?[a-z]{3}[0-9]?$`, line)
+	}
+	assert.Contains(t, stderr, "placement:")
+
+	code, _, stderr = runGlyphic(t, append(args, "--substitute", "a=4,e=4")...)
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "--substitute")
+
+	code, _, _ = runGlyphic(t, append(args, "--placement", "middle")...)
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestRunWordlistFormats(t *testing.T) {
+	dir := t.TempDir()
+	lists := map[string]string{
+		"animals.json": `{"name": "Animals", "words": [{"word": "otter", "tags": "noun"}, {"word": "heron", "tags": "noun"}]}`,
+		"traits.csv":   "word,tags,frequency\nbrave,adj,10\ncalm,adj,20\n",
+	}
+	var args []string
+	for name, data := range lists {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+		args = append(args, "--wordlist", path)
+	}
+
+	code, stdout, stderr := runGlyphic(t, append(args, "--no-defaults", "--no-reveal", "--no-exclusions", "--template", "{adj} {noun}", "--capitalize", "none")...)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Regexp(t, `^(brave|calm) (otter|heron)$`, strings.TrimSpace(stdout))
+
+	zw, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	defer func() { _ = zw.Close() }()
+	compressed := filepath.Join(dir, "traits.csv.zst")
+	require.NoError(t, os.WriteFile(compressed, zw.EncodeAll([]byte(lists["traits.csv"]), nil), 0600))
+	args = []string{"--wordlist", filepath.Join(dir, "animals.json"), "--wordlist", compressed}
+
+	code, stdout, stderr = runGlyphic(t, append(args, "--no-defaults", "--no-reveal", "--no-exclusions", "--template", "{adj} {noun}", "--capitalize", "none")...)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Regexp(t, `^(brave|calm) (otter|heron)$`, strings.TrimSpace(stdout))
+}
+
+func TestRunLanguage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tr.txt")
+	require.NoError(t, os.WriteFile(path, []byte("istanbul\nırmak\n"), 0600))
+	args := []string{"--no-defaults", "--no-reveal", "--no-exclusions", "--wordlist", path, "--min-wordlists", "1", "--words", "3", "--separator", "space"}
+
+	// Turkish case rules keep the dot on the capital i
+	code, stdout, stderr := runGlyphic(t, append(args, "--language", "tr", "--capitalize", "all")...)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Regexp(t, `^(İSTANBUL|IRMAK)( (İSTANBUL|IRMAK)){2}$`, strings.TrimSpace(stdout))
+
+	// Folded ı becomes i, which Turkish capitalizes with a dot
+	code, stdout, stderr = runGlyphic(t, append(args, "--language", "tr", "--fold-diacritics", "--capitalize", "first")...)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Regexp(t, `^(İstanbul|İrmak)( (İstanbul|İrmak)){2}$`, strings.TrimSpace(stdout))
+
+	if len(wordlist.EmbeddedIDs("es")) == 0 {
+		t.Skip("slim build has no embedded wordlists")
+	}
+
+	code, stdout, stderr = runGlyphic(t, "--language", "es", "--min-wordlists", "1", "--no-reveal", "--capitalize", "none", "--count", "20", "--entropy")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stderr, "66.0 bits") // six words of 2048
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		assert.Regexp(t, `^\p{Ll}+(-\p{Ll}+){5}$`, line)
+	}
+
+	code, stdout, stderr = runGlyphic(t, "--language", "es", "--fold-diacritics", "--min-wordlists", "1", "--no-reveal", "--count", "20")
+	require.Equal(t, exitOK, code, stderr)
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		assert.Regexp(t, `^[A-Z][a-z]+(-[A-Z][a-z]+){5}$`, line)
+	}
+
+	// Each language defaults to the lists it ships, and an explicit minimum
+	// is enforced
+	for _, language := range []string{"de", "es", "fr", "it", "ja"} {
+		code, _, stderr = runGlyphic(t, "--language", language, "--no-reveal")
+		require.Equal(t, exitOK, code, stderr)
+		assert.NotContains(t, stderr, "--min-wordlists")
+	}
+	code, _, stderr = runGlyphic(t, "--language", "es", "--no-reveal", "--min-wordlists", "3")
+	assert.Equal(t, exitWordlist, code)
+	assert.Contains(t, stderr, "need 3, have 1")
+
+	code, _, stderr = runGlyphic(t, "--language", "xx", "--no-reveal")
+	assert.Equal(t, exitWordlist, code)
+	assert.Contains(t, stderr, `no default wordlists in language "xx"`)
+}
+
+func TestRunDice(t *testing.T) {
+	dir := t.TempDir()
+	lists := map[string]string{
+		"animals.txt": "1 ant\n2 bee\n3 cat\n4 dog\n5 eel\n6 fox\n",
+		"trees.txt":   "1 oak\n2 elm\n3 ash\n4 yew\n5 fir\n6 bay\n",
+	}
+	var args []string
+	for name, data := range lists {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+		args = append(args, "--wordlist", path)
+	}
+	args = append(args, "--no-defaults", "--no-reveal", "--dice", "--words", "3", "--min-wordlists", "2", "--no-exclusions", "--entropy")
+
+	// The 9 is rejected and rolled again
+	code, stdout, stderr := runGlyphicInput(t, t.TempDir(), "1\n9\n6\n4\n", args...)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "Ant-Bay-Dog\n", stdout)
+	assert.Contains(t, stderr, "Word 1/3 (user-animals): roll 1 dice")
+	assert.Contains(t, stderr, "roll again")
+	assert.Contains(t, stderr, "Entropy: 7.8 bits")
+
+	code, _, stderr = runGlyphicInput(t, t.TempDir(), "1\n", args...)
+	assert.Equal(t, exitFailure, code)
+	assert.Contains(t, stderr, "dice input ended")
+
+	code, _, stderr = runGlyphicInput(t, t.TempDir(), "", append(args, "--numbers")...)
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "physical dice")
+}
+
+func TestRunDiceDefaults(t *testing.T) {
+	if len(wordlist.EmbeddedIDs(wordlist.DefaultLanguage)) == 0 {
+		t.Skip("slim build has no embedded wordlists")
+	}
+
+	// Every other option at its default: six words alternating between the
+	// embedded eff-large and eff-short-2 dice indexes
+	code, stdout, stderr := runGlyphicInput(t, t.TempDir(), "11111\n1111\n11111\n1111\n11111\n1111\n", "--dice", "--no-reveal")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "Abacus-Aardvark-Abacus-Aardvark-Abacus-Aardvark\n", stdout)
+	assert.Contains(t, stderr, "Word 2/6 (eff-short-2): roll 4 dice")
+}
+
+func TestRunOfflineWithEmbeddedWordlists(t *testing.T) {
+	embedded := len(wordlist.EmbeddedIDs(wordlist.DefaultLanguage))
+	if embedded < 2 {
+		t.Skip("slim build has no embedded wordlists")
+	}
+
+	code, stdout, stderr := runGlyphic(t, "--no-reveal", "--min-wordlists", fmt.Sprint(embedded), "--count", "3")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Len(t, strings.Split(strings.TrimSpace(stdout), "\n"), 3)
+}
+
+func TestRunFreshHome(t *testing.T) {
+	if len(wordlist.EmbeddedIDs(wordlist.DefaultLanguage)) == 0 {
+		t.Skip("slim build has no embedded wordlists")
+	}
+
+	// Every English default list is compiled in
+	var english []string
+	for _, source := range wordlist.DefaultSources {
+		if source.Language == wordlist.DefaultLanguage {
+			english = append(english, source.ID)
+		}
+	}
+	assert.ElementsMatch(t, english, wordlist.EmbeddedIDs(wordlist.DefaultLanguage))
+
+	// No cache, config or network: the default options draw from all of them
+	home := t.TempDir()
+	code, stdout, stderr := runGlyphicHome(t, home, "--no-reveal", "--entropy")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Regexp(t, `^[A-Z][a-z]+(-[A-Z][a-z]+){5}$`, strings.TrimSpace(stdout))
+	assert.Contains(t, stderr, "Entropy: 69.8 bits") // three words from each list
+	assert.NotContains(t, stderr, "pinned checksum")
+	assert.NotContains(t, stderr, "warning")
+	code, _, stderr = runGlyphicHome(t, home, "--no-reveal", "--min-wordlists", fmt.Sprint(len(english)))
+	require.Equal(t, exitOK, code, stderr)
+
+	// Nothing was downloaded
+	cached, err := filepath.Glob(filepath.Join(home, ".local", "share", "glyphic", "wordlists", "*"))
+	require.NoError(t, err)
+	assert.Empty(t, cached)
+
+	code, _, stderr = runGlyphic(t, "--no-reveal", "--words", "3")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stderr, "below the 50-bit minimum; add words with --words or set --target-entropy")
+}
+
+func TestLanguageMinWordlists(t *testing.T) {
+	// The per-language defaults match the lists each language ships
+	for language, n := range languageMinWordlists {
+		shipped := 0
+		for _, source := range wordlist.DefaultSources {
+			if source.Language == language {
+				shipped++
+			}
+		}
+		assert.Equal(t, shipped, n, language)
+	}
+}
+
+func TestRunConfirmMinWordlists(t *testing.T) {
+	if len(wordlist.EmbeddedIDs(wordlist.DefaultLanguage)) < 2 {
+		t.Skip("slim build has no embedded wordlists")
+	}
+
+	home := t.TempDir()
+	code, _, stderr := runGlyphicHome(t, home, "wordlist", "disable", "eff-short-2")
+	require.Equal(t, exitOK, code, stderr)
+
+	// Without a terminal the default minimum is enforced
+	code, _, stderr = runGlyphicHome(t, home, "--no-reveal")
+	assert.Equal(t, exitWordlist, code)
+	assert.Contains(t, stderr, "need 2, have 1 (use --min-wordlists 1 to draw from fewer)")
+
+	interactive := isInteractive
+	isInteractive = func(io.Reader, io.Writer) bool { return true }
+	t.Cleanup(func() { isInteractive = interactive })
+
+	code, stdout, stderr := runGlyphicInput(t, home, "y\n", "--no-reveal")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stderr, "draw from 1? [y/N]")
+	assert.Regexp(t, `^[A-Z][a-z]+(-[A-Z][a-z]+){5}$`, strings.TrimSpace(stdout))
+
+	code, _, stderr = runGlyphicInput(t, home, "\n", "--no-reveal")
+	assert.Equal(t, exitWordlist, code)
+	assert.Contains(t, stderr, "need 2, have 1")
+
+	// An explicit minimum is never lowered, not even on request
+	code, _, _ = runGlyphicInput(t, home, "y\n", "--no-reveal", "--min-wordlists", "2")
+	assert.Equal(t, exitWordlist, code)
+}
+
+func TestRunNotEnoughWordlists(t *testing.T) {
+	args := append(writeWordlists(t, 2), "--no-defaults", "--no-reveal", "--min-wordlists", "3")
+
+	code, _, stderr := runGlyphic(t, args...)
+	assert.Equal(t, exitWordlist, code)
+	assert.Contains(t, stderr, "wordlists")
+}
+
+func TestGeneratorOptions(t *testing.T) {
+	fs, flags := newFlagSet(&bytes.Buffer{})
+	err := fs.Parse([]string{
+		"--words", "8",
+		"--capitalize", "alternating",
+		"--separator", "custom", "--custom-separator", "::",
+		"--special", "--special-count", "3",
+		"--min-wordlists", "2",
+		"--weight-by-size",
+	})
+	require.NoError(t, err)
+
+	opts, err := flags.generatorOptions()
+	require.NoError(t, err)
+	assert.Equal(t, generator.Options{
+		WordCount:      8,
+		Capitalization: generator.CapAlternating,
+		NumberCount:    generator.DefaultOptions.NumberCount,
+		AddSpecial:     true,
+		SpecialCount:   3,
+		Separator:      generator.SepCustom,
+		CustomSep:      "::",
+		MinWordlists:   2,
+		WeightBySize:   true,
+		Length:         generator.DefaultOptions.Length,
+		PINLength:      generator.DefaultOptions.PINLength,
+		TokenBits:      generator.DefaultOptions.TokenBits,
+		Syllables:      generator.DefaultOptions.Syllables,
+		Language:       wordlist.DefaultLanguage,
+	}, opts)
+}
+
+func TestRevealOptions(t *testing.T) {
+	fs, flags := newFlagSet(&bytes.Buffer{})
+	require.NoError(t, fs.Parse([]string{"--color", "nord", "--speed", "fast", "--entropy"}))
+
+	opts, err := flags.revealOptions()
+	require.NoError(t, err)
+	assert.Equal(t, tui.NordScheme, opts.Scheme)
+	assert.Equal(t, tui.SpeedFast, opts.Speed)
+	assert.True(t, opts.ShowEntropy)
+}
+
+func TestUserWordlistID(t *testing.T) {
+	seen := make(map[string]int)
+	assert.Equal(t, "user-words", userWordlistID("/a/words.txt", seen))
+	assert.Equal(t, "user-words-2", userWordlistID("/b/words.txt", seen))
+	assert.Equal(t, "user-other", userWordlistID("other", seen))
+	assert.Equal(t, "user-fruit", userWordlistID("/c/fruit.csv.gz", seen))
+}
+
+// writeConfig writes a config file into a fresh XDG_CONFIG_HOME
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	path := filepath.Join(dir, "glyphic", "config.toml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestApplyConfigPrecedence(t *testing.T) {
+	writeConfig(t, `
+words = 7
+color = "nord"
+separator = "underscore"
+speed = "slow"
+
+[profiles.work]
+words = 8
+speed = "fast"
+`)
+	env := map[string]string{"GLYPHIC_WORDS": "9", "GLYPHIC_SEPARATOR": "space"}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	fs, flags := newFlagSet(&bytes.Buffer{})
+	require.NoError(t, fs.Parse([]string{"--profile", "work", "--separator", "none"}))
+
+	resolved, err := applyConfig(fs, flags, lookup)
+	require.NoError(t, err)
+
+	assert.Equal(t, 9, flags.words)
+	assert.Equal(t, "GLYPHIC_WORDS", resolved["words"].Origin)
+	assert.Equal(t, "none", flags.separator)
+	assert.Equal(t, "flag", resolved["separator"].Source.String())
+	assert.Equal(t, "fast", flags.speed)
+	assert.Equal(t, "profile", resolved["speed"].Source.String())
+	assert.Equal(t, "nord", flags.color)
+	assert.Equal(t, "config", resolved["color"].Source.String())
+	assert.Equal(t, generator.DefaultOptions.NumberCount, flags.numberCount)
+	assert.Equal(t, "default", resolved["number-count"].Source.String())
+}
+
+func TestApplyConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		args   []string
+	}{
+		{"unknown key", "bogus = 1", nil},
+		{"bad value", "words = \"many\"", nil},
+		{"unknown profile", "words = 6", []string{"--profile", "ssh"}},
+		{"meta key not configurable", "version = true", nil},
+		{"unpinned opt-in not configurable", "insecure-unpinned = true", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeConfig(t, tt.config)
+			fs, flags := newFlagSet(&bytes.Buffer{})
+			require.NoError(t, fs.Parse(tt.args))
+
+			_, err := applyConfig(fs, flags, func(string) (string, bool) { return "", false })
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestApplyConfigEnvWordlists(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	lookup := func(name string) (string, bool) {
+		if name == "GLYPHIC_WORDLIST" {
+			return "/a.txt" + string(os.PathListSeparator) + "/b.txt", true
+		}
+		return "", false
+	}
+
+	fs, flags := newFlagSet(&bytes.Buffer{})
+	require.NoError(t, fs.Parse(nil))
+	_, err := applyConfig(fs, flags, lookup)
+	require.NoError(t, err)
+	assert.Equal(t, stringList{"/a.txt", "/b.txt"}, flags.wordlists)
+}
+
+func TestConfigCommands(t *testing.T) {
+	t.Run("init then validate", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		code, stdout, stderr := runGlyphic(t, "config", "init")
+		require.Equal(t, exitOK, code, stderr)
+		assert.Contains(t, stdout, "config.toml")
+
+		code, _, _ = runGlyphic(t, "config", "init")
+		assert.Equal(t, exitUsage, code)
+
+		code, stdout, stderr = runGlyphic(t, "config", "validate")
+		require.Equal(t, exitOK, code, stderr)
+		assert.Contains(t, stdout, "profile work: ok")
+	})
+
+	t.Run("validate reports bad profile", func(t *testing.T) {
+		writeConfig(t, "[profiles.broken]\nwords = 40\n")
+		code, _, stderr := runGlyphic(t, "config", "validate")
+		assert.Equal(t, exitUsage, code)
+		assert.Contains(t, stderr, "profile broken")
+	})
+
+	t.Run("show reports sources", func(t *testing.T) {
+		writeConfig(t, "words = 7\nwordlist-ttl = \"168h\"\n")
+		t.Setenv("GLYPHIC_COLOR", "fire")
+		code, stdout, stderr := runGlyphic(t, "config", "show", "--speed", "fast")
+		require.Equal(t, exitOK, code, stderr)
+		assert.Regexp(t, `words\s+7\s+config`, stdout)
+		assert.Regexp(t, `wordlist-ttl\s+168h0m0s\s+config`, stdout)
+		assert.Regexp(t, `color\s+fire\s+env\s+GLYPHIC_COLOR`, stdout)
+		assert.Regexp(t, `speed\s+fast\s+flag`, stdout)
+		assert.Regexp(t, `capitalize\s+first\s+default`, stdout)
+		assert.Regexp(t, `min-wordlists\s+2\s+default\s+built-in for en`, stdout)
+	})
+
+	t.Run("show language defaults", func(t *testing.T) {
+		writeConfig(t, "language = \"es\"\n")
+		code, stdout, stderr := runGlyphic(t, "config", "show")
+		require.Equal(t, exitOK, code, stderr)
+		assert.Regexp(t, `min-wordlists\s+1\s+default\s+built-in for es`, stdout)
+
+		code, stdout, stderr = runGlyphic(t, "config", "show", "--language", "all")
+		require.Equal(t, exitOK, code, stderr)
+		assert.Regexp(t, `(?m)^min-wordlists\s+3\s+default\s+built-in\s*$`, stdout)
+
+		code, stdout, stderr = runGlyphic(t, "config", "show", "--min-wordlists", "3")
+		require.Equal(t, exitOK, code, stderr)
+		assert.Regexp(t, `min-wordlists\s+3\s+flag`, stdout)
+	})
+
+	t.Run("unknown subcommand", func(t *testing.T) {
+		code, _, _ := runGlyphic(t, "config", "frobnicate")
+		assert.Equal(t, exitUsage, code)
+	})
+}
+
+func TestWordlistVerify(t *testing.T) {
+	t.Run("empty cache", func(t *testing.T) {
+		code, stdout, stderr := runGlyphic(t, "wordlist", "verify")
+		require.Equal(t, exitOK, code, stderr)
+		assert.Regexp(t, `eff-large\s+missing`, stdout)
+		if slices.Contains(wordlist.EmbeddedIDs(wordlist.DefaultLanguage), "eff-large") {
+			assert.Contains(t, stdout, "embedded copy in use")
+		}
+	})
+
+	t.Run("tampered cache", func(t *testing.T) {
+		home := t.TempDir()
+		cacheDir := filepath.Join(home, ".local", "share", "glyphic", "wordlists")
+		require.NoError(t, os.MkdirAll(cacheDir, 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(cacheDir, "eff-large.txt"), []byte("11111\tabacus\n"), 0600))
+
+		code, stdout, _ := runGlyphicHome(t, home, "wordlist", "verify")
+		assert.Equal(t, exitWordlist, code)
+		assert.Regexp(t, `eff-large\s+mismatch`, stdout)
+	})
+
+	t.Run("unknown subcommand", func(t *testing.T) {
+		code, _, _ := runGlyphic(t, "wordlist", "frobnicate")
+		assert.Equal(t, exitUsage, code)
+	})
+}
+
+func TestWordlistEnable(t *testing.T) {
+	home := t.TempDir()
+
+	code, stdout, stderr := runGlyphicHome(t, home, "wordlist", "disable", "eff-short-2", "bip39-fr")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "eff-short-2 disabled\nbip39-fr disabled\n", stdout)
+
+	code, stdout, stderr = runGlyphicHome(t, home, "wordlist", "list")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Regexp(t, `LIST\s+WORDS\s+CATEGORY\s+LANGUAGE\s+CACHED\s+STATUS`, stdout)
+	assert.Regexp(t, `eff-short-2\s+1296\s+\S+\s+en\s+\S+\s+disabled`, stdout)
+	assert.Regexp(t, `eff-large\s+7776\s+\S+\s+en\s+\S+\s+enabled`, stdout)
+	assert.Regexp(t, `bip39-fr\s+2048\s+\S+\s+fr\s+\S+\s+disabled`, stdout)
+
+	code, stdout, _ = runGlyphicHome(t, home, "wordlist", "list", "--language", "fr")
+	require.Equal(t, exitOK, code)
+	assert.NotContains(t, stdout, "eff-large")
+
+	if len(wordlist.EmbeddedIDs(wordlist.DefaultLanguage)) >= 2 {
+		// Only eff-large is left to draw from
+		code, _, _ = runGlyphicHome(t, home, "--no-reveal", "--min-wordlists", "2")
+		assert.NotEqual(t, exitOK, code)
+		code, _, stderr = runGlyphicHome(t, home, "--no-reveal", "--min-wordlists", "1")
+		assert.Equal(t, exitOK, code, stderr)
+	}
+
+	code, _, stderr = runGlyphicHome(t, home, "wordlist", "enable", "eff-short-2")
+	require.Equal(t, exitOK, code, stderr)
+	_, stdout, _ = runGlyphicHome(t, home, "wordlist", "list")
+	assert.Regexp(t, `eff-short-2\s.*\senabled`, stdout)
+
+	code, _, stderr = runGlyphicHome(t, home, "wordlist", "disable", "no-such-list")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "no-such-list")
+
+	code, _, _ = runGlyphicHome(t, home, "wordlist", "enable")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestWordlistAddRemove(t *testing.T) {
+	home := t.TempDir()
+	const sum = "addd35536511597a02fa0a9ff1e5284677b8883b83e986e43f15a3db996b903e"
+
+	tests := []struct {
+		name   string
+		args   []string
+		stderr string
+	}{
+		{name: "no url", args: []string{"--sha256", sum}, stderr: "usage"},
+		{name: "unpinned", args: []string{"--url", "https://example.com/Team-Words.txt.gz"}, stderr: "no pinned checksum: team-words"},
+		{name: "plain http", args: []string{"--url", "http://example.com/team.txt", "--sha256", sum}, stderr: "https"},
+		{name: "bad key", args: []string{"--url", "https://example.com/team.txt", "--minisign-key", "bm90IGEga2V5"}, stderr: "public key"},
+		{name: "default id", args: []string{"--url", "https://example.com/eff-large.txt", "--sha256", sum}, stderr: "already exists"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, stderr := runGlyphicHome(t, home, append([]string{"wordlist", "add"}, tt.args...)...)
+			assert.Equal(t, exitUsage, code)
+			assert.Contains(t, stderr, tt.stderr)
+		})
+	}
+
+	// A registered source is listed with the defaults and can be removed
+	cacheDir := filepath.Join(home, ".local", "share", "glyphic", "wordlists")
+	registry := `{"version": 1, "sources": [{"id": "team", "name": "Team", "url": "https://example.com/team.txt",
+		"sha256": "` + sum + `", "word_count": 3, "category": "custom", "language": "en"}]}`
+	require.NoError(t, os.WriteFile(filepath.Join(cacheDir, "sources.json"), []byte(registry), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(cacheDir, "team.txt"), []byte("otter\nheron\nbadger\n"), 0600))
+
+	code, stdout, stderr := runGlyphicHome(t, home, "wordlist", "list")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Regexp(t, `team\s+3\s+custom\s+en\s+just now\s+enabled`, stdout)
+
+	code, stdout, stderr = runGlyphicHome(t, home, "wordlist", "disable", "team")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "team disabled\n", stdout)
+
+	code, stdout, stderr = runGlyphicHome(t, home, "wordlist", "remove", "team")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "team removed\n", stdout)
+	assert.NoFileExists(t, filepath.Join(cacheDir, "team.txt"))
+
+	_, stdout, _ = runGlyphicHome(t, home, "wordlist", "list")
+	assert.NotContains(t, stdout, "team")
+
+	code, _, _ = runGlyphicHome(t, home, "wordlist", "remove", "eff-large")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		age  time.Duration
+		want string
+	}{
+		{age: 10 * time.Second, want: "just now"},
+		{age: 5 * time.Minute, want: "5m ago"},
+		{age: 3*time.Hour + 59*time.Minute, want: "3h ago"},
+		{age: 50 * time.Hour, want: "2d ago"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, formatAge(tt.age))
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	list := writeWordlists(t, 1)[1]
+
+	code, stdout, stderr := runGlyphicInput(t, t.TempDir(), "de:ad:be:ef\n", "encode", "--wordlist", list, "--checksum", "--hex", "--separator", "-")
+	require.Equal(t, exitOK, code, stderr)
+	words := strings.TrimSpace(stdout)
+	assert.Regexp(t, `^[a-z]+(-[a-z]+)+$`, words)
+
+	// Case and separators may change when the words are read back
+	spoken := strings.ToUpper(strings.ReplaceAll(words, "-", " "))
+	code, stdout, stderr = runGlyphic(t, "decode", "--wordlist", list, "--checksum", "--hex", spoken)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "deadbeef\n", stdout)
+
+	code, stdout, stderr = runGlyphicInput(t, t.TempDir(), words, "decode", "--wordlist", list, "--checksum")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "\xde\xad\xbe\xef", stdout)
+
+	code, _, stderr = runGlyphic(t, "decode", "--wordlist", list, "--checksum", "kiwi", "apple")
+	assert.Equal(t, exitFailure, code)
+	assert.Contains(t, stderr, "unknown word")
+
+	code, _, _ = runGlyphicInput(t, t.TempDir(), "xyz", "encode", "--wordlist", list, "--hex")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestEncodeBuiltinList(t *testing.T) {
+	// Built-in lists need no download, even in slim builds
+	code, stdout, stderr := runGlyphicInput(t, t.TempDir(), "0000", "encode", "--hex")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "abnormal imminent\n", stdout)
+
+	// Lists of other languages can be named too
+	code, stdout, stderr = runGlyphicInput(t, t.TempDir(), "0000", "encode", "--hex", "--list", "bip39-it")
+	require.Equal(t, exitOK, code, stderr)
+	words := strings.Fields(stdout)
+	require.NotEmpty(t, words)
+	code, stdout, stderr = runGlyphicInput(t, t.TempDir(), strings.Join(words, " "), "decode", "--hex", "--list", "bip39-it")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "0000\n", stdout)
+
+	code, _, stderr = runGlyphicInput(t, t.TempDir(), "0000", "encode", "--hex", "--list", "team")
+	assert.Equal(t, exitWordlist, code)
+	assert.Contains(t, stderr, "no codec word array")
+}
+
+func TestBIP39(t *testing.T) {
+	code, stdout, stderr := runGlyphic(t, "bip39", "generate", "--bits", "128")
+	require.Equal(t, exitOK, code, stderr)
+	mnemonic := strings.TrimSpace(stdout)
+	assert.Len(t, strings.Fields(mnemonic), 12)
+
+	code, stdout, stderr = runGlyphicInput(t, t.TempDir(), mnemonic+"\n", "bip39", "validate")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, mnemonic+"\n", stdout)
+
+	// Trezor reference vector with passphrase "TREZOR"
+	passphrase := filepath.Join(t.TempDir(), "passphrase")
+	require.NoError(t, os.WriteFile(passphrase, []byte("TREZOR\n"), 0600))
+	code, stdout, stderr = runGlyphicInput(t, t.TempDir(), "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo  wrong\n",
+		"bip39", "validate", "--seed", "--passphrase-file", passphrase)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong\n"+
+		"ac27495480225222079d7be181583751e86f571027b0497b5b5d11218e0a8a13332572917f0f8e5a589620c6f15b11c61dee327651a14c34e18231052e48c069\n", stdout)
+
+	// Capitals validate, but the seed is derived from the mnemonic as typed
+	code, stdout, stderr = runGlyphic(t, "bip39", "validate", "--seed", "--passphrase-file", passphrase,
+		"zoo", "zoo", "zoo", "zoo", "zoo", "zoo", "zoo", "zoo", "zoo", "zoo", "zoo", "WRONG")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo WRONG\n"+
+		"39d6c63962c998eec44a689f117862b2fe0be05bee863d4609f40075beb0212ff0c8037794a6046844a0af0b9c4e222bc4f6fb412068d6b0cd5b2b1a1656fb22\n", stdout)
+
+	code, _, stderr = runGlyphic(t, "bip39", "validate", "zoo", "zoo", "zoo", "zoo", "zoo", "zoo", "zoo", "zoo", "zoo", "zoo", "zoo", "zoo")
+	assert.Equal(t, exitFailure, code)
+	assert.Contains(t, stderr, "checksum")
+
+	code, _, _ = runGlyphic(t, "bip39", "generate", "--bits", "100")
+	assert.Equal(t, exitUsage, code)
+	code, _, _ = runGlyphic(t, "bip39", "generate", "--language", "latin")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestDerive(t *testing.T) {
+	if !slices.Contains(wordlist.EmbeddedIDs(wordlist.DefaultLanguage), "eff-large") {
+		t.Skip("slim build has no embedded wordlists")
+	}
+
+	// Published test vectors from docs/derive-v1.md
+	vectors := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--site", "example.com", "--user", "alice"}, "Easiness-Mulberry-Caress-Spud-Devourer-Stuffed"},
+		{[]string{"--site", "example.com", "--user", "alice", "--counter", "2"}, "Onstage-Setback-Deepen-Dill-Riverboat-Bunkhouse"},
+		{[]string{"--site", "Example.COM", "--user", "alice", "--words", "4", "--capitalize", "random", "--numbers", "--special"}, "Easiness-mUlBErry-CAREsS-spUD48,"},
+		{[]string{"--site", "aws.amazon.com", "--user", "root", "--policy", "aws-iam", "--numbers", "--special"}, "Myth-Flatly-Certainty-Fence-Sinless-Hatbox58%"},
+		{[]string{"--site", "example.com", "--user", "alice", "--words", "3", "--capitalize", "random", "--policy", "ad"}, "KOsher-seveNtY-ExerT"},
+		{[]string{"--site", "example.com", "--user", "alice", "--words", "3", "--separator", "none", "--policy", "pci-dss", "--numbers"}, "BunchFavoringProbing88"},
+	}
+	for _, v := range vectors {
+		code, stdout, stderr := runGlyphicInput(t, t.TempDir(), "correct horse battery staple\n", append([]string{"derive"}, v.args...)...)
+		require.Equal(t, exitOK, code, stderr)
+		assert.Equal(t, v.want+"\n", stdout, v.args)
+	}
+
+	code, _, stderr := runGlyphicInput(t, t.TempDir(), "\n", "derive", "--site", "example.com")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "master passphrase must not be empty")
+
+	code, _, _ = runGlyphicInput(t, t.TempDir(), "secret\n", "derive")
+	assert.Equal(t, exitUsage, code)
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/greysquirr3l/glyphic/internal/wordlist"
+)
+
+// runWordlist implements the "glyphic wordlist" subcommands
+func runWordlist(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		_, _ = fmt.Fprintln(stderr, "usage: glyphic wordlist verify|update|list|enable|disable|add|remove [flags]")
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "verify":
+		return runWordlistVerify(args[1:], stdout, stderr)
+	case "update":
+		return runWordlistUpdate(ctx, args[1:], stdout, stderr)
+	case "list":
+		return runWordlistList(args[1:], stdout, stderr)
+	case "enable", "disable":
+		return runWordlistEnable(args[0], args[1:], stdout, stderr)
+	case "add":
+		return runWordlistAdd(ctx, args[1:], stdout, stderr)
+	case "remove":
+		return runWordlistRemove(args[1:], stdout, stderr)
+	default:
+		_, _ = fmt.Fprintf(stderr, "glyphic: unknown wordlist command %q\n", args[0])
+		return exitUsage
+	}
+}
+
+// runWordlistVerify re-hashes every cached wordlist, of every language unless
+// one is given, against the embedded manifest
+func runWordlistVerify(args []string, stdout, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("glyphic wordlist verify", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	allowUnpinned := flagSet.Bool("insecure-unpinned", false, "do not fail on cached lists without a pinned checksum")
+	language := flagSet.String("language", "", "only verify lists in this language")
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	manager, err := wordlist.NewManager("")
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitWordlist
+	}
+	manager.SetLanguage(*language)
+
+	_, _ = fmt.Fprintf(stdout, "Manifest version %d\n\n", wordlist.ManifestVersion)
+
+	failed := false
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "LIST\tSTATUS\tDETAIL")
+	for _, result := range manager.VerifyCache() {
+		var detail string
+		switch result.Status {
+		case wordlist.CacheOK:
+			detail = "sha256 " + result.SHA256
+			if result.Embedded {
+				detail += " (embedded copy in use)"
+			}
+		case wordlist.CacheMissing:
+			detail = "not cached"
+			if result.Embedded {
+				detail = "not cached, embedded copy in use"
+			}
+		case wordlist.CacheUnpinned:
+			detail = "sha256 " + result.SHA256 + " (no pinned checksum)"
+			if result.SHA256 == "" {
+				detail = "not cached, no pinned checksum to verify a download against"
+			}
+			failed = failed || !*allowUnpinned
+		case wordlist.CacheMismatch, wordlist.CacheUnreadable:
+			detail = result.Err.Error()
+			failed = true
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", result.Source.ID, result.Status, detail)
+	}
+	if err := tw.Flush(); err != nil {
+		return exitFailure
+	}
+
+	if failed {
+		return exitWordlist
+	}
+	return exitOK
+}
+
+// runWordlistUpdate re-downloads every wordlist, of every language unless one
+// is given, over HTTPS into the cache
+func runWordlistUpdate(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("glyphic wordlist update", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	allowUnpinned := flagSet.Bool("insecure-unpinned", false, "also download lists without a pinned checksum")
+	language := flagSet.String("language", "", "only download lists in this language")
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	manager, err := wordlist.NewManager("")
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitWordlist
+	}
+	manager.SetAllowUnpinned(*allowUnpinned)
+	manager.SetLanguage(*language)
+	if err := manager.LoadState(); err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitWordlist
+	}
+
+	if err := manager.Update(ctx); err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitWordlist
+	}
+
+	_, _ = fmt.Fprintln(stdout, "All wordlists updated")
+	return exitOK
+}
+
+// runWordlistList prints every wordlist, of every language unless one is
+// given, with its size, cache age and whether it is enabled
+func runWordlistList(args []string, stdout, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("glyphic wordlist list", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	language := flagSet.String("language", "", "only list lists in this language")
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	manager, err := wordlist.NewManager("")
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitWordlist
+	}
+	manager.SetLanguage(*language)
+	if err := manager.LoadState(); err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitWordlist
+	}
+
+	now := time.Now()
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "LIST\tWORDS\tCATEGORY\tLANGUAGE\tCACHED\tSTATUS")
+	for _, info := range manager.SourceInfo() {
+		cached := "-"
+		switch {
+		case !info.CachedAt.IsZero():
+			cached = formatAge(now.Sub(info.CachedAt))
+		case info.Embedded:
+			cached = "embedded"
+		}
+		status := "enabled"
+		if !info.Enabled {
+			status = "disabled"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\n",
+			info.Source.ID, info.Source.WordCount, info.Source.Category, info.Source.Language, cached, status)
+	}
+	if err := tw.Flush(); err != nil {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// runWordlistEnable enables or disables the named wordlists, as picked by
+// command, for every later run
+func runWordlistEnable(command string, args []string, stdout, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("glyphic wordlist "+command, flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if flagSet.NArg() == 0 {
+		_, _ = fmt.Fprintf(stderr, "usage: glyphic wordlist %s <list>...\n", command)
+		return exitUsage
+	}
+
+	manager, err := wordlist.NewManager("")
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitWordlist
+	}
+	// Lists of every language can be named
+	manager.SetLanguage("")
+	if err := manager.LoadState(); err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitWordlist
+	}
+
+	for _, id := range flagSet.Args() {
+		if err := manager.EnableSource(id, command == "enable"); err != nil {
+			_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+			if errors.Is(err, wordlist.ErrSourceNotFound) {
+				return exitUsage
+			}
+			return exitWordlist
+		}
+		_, _ = fmt.Fprintf(stdout, "%s %sd\n", id, command)
+	}
+	return exitOK
+}
+
+// runWordlistAdd registers a remote wordlist pinned by checksum or signing
+// key, downloading and verifying it at once
+func runWordlistAdd(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("glyphic wordlist add", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	var source wordlist.WordlistSource
+	flagSet.StringVar(&source.URL, "url", "", "HTTPS URL of the wordlist (required)")
+	flagSet.StringVar(&source.SHA256, "sha256", "", "expected SHA-256 checksum of the wordlist")
+	minisignKey := flagSet.String("minisign-key", "", "minisign or base64 Ed25519 public key, or a .pub file, that signs the wordlist")
+	flagSet.StringVar(&source.SignatureURL, "signature-url", "", "HTTPS URL of the detached signature (default: URL with .minisig or .sig)")
+	flagSet.StringVar(&source.ID, "id", "", "wordlist ID (default: file name from the URL)")
+	flagSet.StringVar(&source.Name, "name", "", "display name (default: from the wordlist header)")
+	flagSet.StringVar(&source.Language, "language", "", "language of the wordlist (default: from the header, or en)")
+	flagSet.StringVar(&source.Category, "category", "", "category (default: from the header, or custom)")
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if source.URL == "" || flagSet.NArg() > 0 {
+		_, _ = fmt.Fprintln(stderr, "usage: glyphic wordlist add --url URL --sha256 SUM|--minisign-key KEY [flags]")
+		return exitUsage
+	}
+
+	if *minisignKey != "" {
+		key := *minisignKey
+		// A key may also be given as the path of a minisign .pub file
+		if data, err := os.ReadFile(key); err == nil {
+			key = string(data)
+		}
+		parsed, err := wordlist.ParsePublicKey(key)
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+			return exitUsage
+		}
+		source.PublicKey = parsed.String()
+	}
+	source.SHA256 = strings.ToLower(source.SHA256)
+	if source.ID == "" {
+		if u, err := url.Parse(source.URL); err == nil {
+			source.ID = strings.ToLower(listBaseName(path.Base(u.Path)))
+		}
+	}
+
+	manager, err := wordlist.NewManager("")
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitWordlist
+	}
+	manager.SetLanguage("")
+	if err := manager.LoadState(); err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitWordlist
+	}
+
+	added, err := manager.AddSource(ctx, source)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		if errors.Is(err, wordlist.ErrInvalidManifest) || errors.Is(err, wordlist.ErrUnpinnedSource) ||
+			errors.Is(err, wordlist.ErrDuplicateSource) {
+			return exitUsage
+		}
+		return exitWordlist
+	}
+
+	_, _ = fmt.Fprintf(stdout, "%s added: %d words, language %s\n", added.ID, added.WordCount, added.Language)
+	return exitOK
+}
+
+// runWordlistRemove unregisters wordlists added with "glyphic wordlist add"
+// and deletes them from the cache
+func runWordlistRemove(args []string, stdout, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("glyphic wordlist remove", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if flagSet.NArg() == 0 {
+		_, _ = fmt.Fprintln(stderr, "usage: glyphic wordlist remove <list>...")
+		return exitUsage
+	}
+
+	manager, err := wordlist.NewManager("")
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+		return exitWordlist
+	}
+
+	for _, id := range flagSet.Args() {
+		if err := manager.RemoveSource(id); err != nil {
+			_, _ = fmt.Fprintf(stderr, "glyphic: %v\n", err)
+			if errors.Is(err, wordlist.ErrSourceNotFound) {
+				return exitUsage
+			}
+			return exitWordlist
+		}
+		_, _ = fmt.Fprintf(stdout, "%s removed\n", id)
+	}
+	return exitOK
+}
+
+// formatAge formats the age of a cached file in its largest whole unit
+func formatAge(age time.Duration) string {
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age/time.Minute))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age/time.Hour))
+	default:
+		return fmt.Sprintf("%dd ago", int(age/(24*time.Hour)))
+	}
+}
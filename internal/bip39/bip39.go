@@ -0,0 +1,307 @@
+// Package bip39 implements BIP-39 mnemonic generation, validation and seed
+// derivation over the official 2048-word lists, which are embedded and verified
+// against pinned SHA-256 checksums.
+package bip39
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/greysquirr3l/glyphic/internal/security"
+)
+
+// Entropy bounds from the BIP-39 specification
+const (
+	MinEntropyBits = 128
+	MaxEntropyBits = 256
+
+	seedIterations = 2048
+	seedLength     = 64
+)
+
+// Language identifies an official BIP-39 wordlist
+type Language string
+
+// Official wordlists
+const (
+	English            Language = "english"
+	ChineseSimplified  Language = "chinese-simplified"
+	ChineseTraditional Language = "chinese-traditional"
+	Czech              Language = "czech"
+	French             Language = "french"
+	Italian            Language = "italian"
+	Japanese           Language = "japanese"
+	Korean             Language = "korean"
+	Spanish            Language = "spanish"
+)
+
+var (
+	// ErrUnknownLanguage indicates a language without an embedded wordlist
+	ErrUnknownLanguage = errors.New("unknown BIP-39 language")
+
+	// ErrWordlistChecksum indicates an embedded wordlist that does not match its pinned checksum
+	ErrWordlistChecksum = errors.New("BIP-39 wordlist checksum mismatch")
+
+	// ErrInvalidEntropy indicates an entropy length BIP-39 does not allow
+	ErrInvalidEntropy = errors.New("entropy must be 128 to 256 bits in steps of 32")
+
+	// ErrInvalidMnemonic indicates a mnemonic with the wrong number of words
+	ErrInvalidMnemonic = errors.New("mnemonic must have 12, 15, 18, 21 or 24 words")
+
+	// ErrUnknownWord indicates a mnemonic word that is not in the wordlist
+	ErrUnknownWord = errors.New("word not in BIP-39 wordlist")
+
+	// ErrChecksum indicates a mnemonic whose checksum bits do not match its entropy
+	ErrChecksum = errors.New("mnemonic checksum mismatch")
+)
+
+//go:embed wordlists/*.txt
+var wordlistFiles embed.FS
+
+// wordlistSHA256 pins the SHA-256 of every embedded wordlist file
+var wordlistSHA256 = map[Language]string{
+	English:            "2f5eed53a4727b4bf8880d8f3f199efc90e58503646d9ff8eff3a2ed3b24dbda",
+	ChineseSimplified:  "5c5942792bd8340cb8b27cd592f1015edf56a8c5b26276ee18a482428e7c5726",
+	ChineseTraditional: "417b26b3d8500a4ae3d59717d7011952db6fc2fb84b807f3f94ac734e89c1b5f",
+	Czech:              "7e80e161c3e93d9554c2efb78d4e3cebf8fc727e9c52e03b83b94406bdcc95fc",
+	French:             "ebc3959ab7801a1df6bac4fa7d970652f1df76b683cd2f4003c941c63d517e59",
+	Italian:            "d392c49fdb700a24cd1fceb237c1f65dcc128f6b34a8aacb58b59384b5c648c2",
+	Japanese:           "2eed0aef492291e061633d7ad8117f1a2b03eb80a29d0e4e3117ac2528d05ffd",
+	Korean:             "9e95f86c167de88f450f0aaf89e87f6624a57f973c67b516e338e8e8b8897f60",
+	Spanish:            "46846a5a0139d1e3cb77293e521c2865f7bcdb82c44e8d0a06a2cd0ecba48c0b",
+}
+
+// wordlist is a verified, NFKD-normalised wordlist with a reverse index
+type wordlist struct {
+	words []string
+	index map[string]int
+}
+
+// wordlists caches verified wordlists by language
+var wordlists sync.Map // Language -> *wordlist
+
+// Languages returns the supported languages in sorted order
+func Languages() []Language {
+	langs := make([]Language, 0, len(wordlistSHA256))
+	for lang := range wordlistSHA256 {
+		langs = append(langs, lang)
+	}
+	slices.Sort(langs)
+	return langs
+}
+
+// ParseLanguage returns the language with the given name
+func ParseLanguage(name string) (Language, error) {
+	lang := Language(strings.ToLower(strings.ReplaceAll(name, "_", "-")))
+	if _, ok := wordlistSHA256[lang]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownLanguage, name)
+	}
+	return lang, nil
+}
+
+// Words returns the 2048 words of a language in index order, NFKD-normalised
+func Words(lang Language) ([]string, error) {
+	wl, err := load(lang)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(wl.words), nil
+}
+
+// File returns the embedded wordlist file of lang, one word per line, after
+// checking it against its pinned checksum
+func File(lang Language) ([]byte, error) {
+	want, ok := wordlistSHA256[lang]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownLanguage, lang)
+	}
+
+	data, err := wordlistFiles.ReadFile("wordlists/" + strings.ReplaceAll(string(lang), "-", "_") + ".txt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s wordlist: %w", lang, err)
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return nil, fmt.Errorf("%w: %s: got %s", ErrWordlistChecksum, lang, got)
+	}
+	return data, nil
+}
+
+// load reads and verifies the wordlist of lang the first time it is used
+func load(lang Language) (*wordlist, error) {
+	if wl, ok := wordlists.Load(lang); ok {
+		return wl.(*wordlist), nil
+	}
+
+	data, err := File(lang)
+	if err != nil {
+		return nil, err
+	}
+
+	words := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(words) != 2048 {
+		return nil, fmt.Errorf("%w: %s has %d words", ErrWordlistChecksum, lang, len(words))
+	}
+
+	wl := &wordlist{words: words, index: make(map[string]int, len(words))}
+	for i, word := range words {
+		words[i] = norm.NFKD.String(word)
+		wl.index[words[i]] = i
+	}
+
+	actual, _ := wordlists.LoadOrStore(lang, wl)
+	return actual.(*wordlist), nil
+}
+
+// separator returns the string placed between words. Japanese mnemonics use
+// the ideographic space, which NFKD turns back into a plain space.
+func separator(lang Language) string {
+	if lang == Japanese {
+		return "　"
+	}
+	return " "
+}
+
+// NewMnemonic returns a mnemonic for bits of entropy drawn from src, or from
+// crypto/rand when src is nil. The entropy is held in locked memory where the
+// platform allows it and zeroed before returning.
+func NewMnemonic(src security.RandomSource, bits int, lang Language) (string, error) {
+	if bits < MinEntropyBits || bits > MaxEntropyBits || bits%32 != 0 {
+		return "", fmt.Errorf("%w: got %d", ErrInvalidEntropy, bits)
+	}
+
+	buf, err := security.NewSecureBuffer(bits / 8)
+	if err != nil {
+		return "", err
+	}
+	_ = buf.Lock() // best effort: RLIMIT_MEMLOCK may forbid it
+	defer func() { _ = buf.Destroy() }()
+
+	if err := security.RandomBytes(src, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to draw entropy: %w", err)
+	}
+	return EntropyToMnemonic(buf.Bytes(), lang)
+}
+
+// EntropyToMnemonic encodes entropy as a mnemonic: the entropy is followed by
+// the first len/32 bits of its SHA-256 and split into 11-bit word indices
+func EntropyToMnemonic(entropy []byte, lang Language) (string, error) {
+	bits := len(entropy) * 8
+	if bits < MinEntropyBits || bits > MaxEntropyBits || bits%32 != 0 {
+		return "", fmt.Errorf("%w: got %d", ErrInvalidEntropy, bits)
+	}
+
+	wl, err := load(lang)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(entropy)
+	defer security.SecureZero(sum[:])
+
+	data := append(slices.Clone(entropy), sum[0])
+	defer security.SecureZero(data)
+
+	words := make([]string, (bits+bits/32)/11)
+	for i := range words {
+		words[i] = wl.words[readBits(data, i*11, 11)]
+	}
+	return strings.Join(words, separator(lang)), nil
+}
+
+// MnemonicToEntropy validates a mnemonic and returns its entropy. The caller
+// should zero the result with security.SecureZero when done.
+func MnemonicToEntropy(mnemonic string, lang Language) ([]byte, error) {
+	wl, err := load(lang)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every official word is lowercase, so case differences are typing mistakes
+	words := strings.Fields(strings.ToLower(Normalize(mnemonic)))
+	switch len(words) {
+	case 12, 15, 18, 21, 24:
+	default:
+		return nil, fmt.Errorf("%w: got %d", ErrInvalidMnemonic, len(words))
+	}
+
+	total := len(words) * 11
+	checksumBits := total / 33
+	data := make([]byte, (total+7)/8)
+	defer security.SecureZero(data)
+
+	for i, word := range words {
+		idx, ok := wl.index[word]
+		if !ok {
+			return nil, fmt.Errorf("%w: word %d (%s)", ErrUnknownWord, i+1, lang)
+		}
+		writeBits(data, i*11, 11, idx)
+	}
+
+	entropy := slices.Clone(data[:(total-checksumBits)/8])
+	sum := sha256.Sum256(entropy)
+	defer security.SecureZero(sum[:])
+
+	if readBits(data, total-checksumBits, checksumBits) != int(sum[0]>>(8-checksumBits)) {
+		security.SecureZero(entropy)
+		return nil, ErrChecksum
+	}
+	return entropy, nil
+}
+
+// Validate reports whether mnemonic is a valid mnemonic in lang
+func Validate(mnemonic string, lang Language) error {
+	entropy, err := MnemonicToEntropy(mnemonic, lang)
+	security.SecureZero(entropy)
+	return err
+}
+
+// Normalize returns the NFKD form of a mnemonic with words separated by single
+// spaces. Case is kept, since the seed depends on it.
+func Normalize(mnemonic string) string {
+	return strings.Join(strings.Fields(norm.NFKD.String(mnemonic)), " ")
+}
+
+// Seed derives the 64-byte BIP-39 seed with PBKDF2-HMAC-SHA512 over 2048
+// iterations, using "mnemonic" plus the passphrase as salt. Like the reference
+// implementation it applies NFKD and nothing else, so a mnemonic typed with
+// capitals gives a different seed. The mnemonic is not validated. The caller
+// should zero the result with security.SecureZero.
+func Seed(mnemonic, passphrase string) []byte {
+	password := []byte(norm.NFKD.String(mnemonic))
+	salt := []byte("mnemonic" + norm.NFKD.String(passphrase))
+	defer security.SecureZero(password)
+	defer security.SecureZero(salt)
+
+	return pbkdf2.Key(password, salt, seedIterations, seedLength, sha512.New)
+}
+
+// readBits returns n bits of data starting at bit offset, most significant first
+func readBits(data []byte, offset, n int) int {
+	v := 0
+	for i := range n {
+		bit := offset + i
+		v = v<<1 | int(data[bit/8]>>(7-bit%8)&1)
+	}
+	return v
+}
+
+// writeBits stores the low n bits of v at bit offset, most significant first
+func writeBits(data []byte, offset, n, v int) {
+	for i := range n {
+		bit := offset + i
+		if v>>(n-1-i)&1 == 1 {
+			data[bit/8] |= 1 << (7 - bit%8)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package bip39
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/greysquirr3l/glyphic/internal/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Trezor reference vectors from the BIP-39 specification, seeded with passphrase "TREZOR"
+var vectors = []struct {
+	entropy  string
+	mnemonic string
+	seed     string
+}{
+	{
+		entropy:  "00000000000000000000000000000000",
+		mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		seed:     "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+	},
+	{
+		entropy:  "ffffffffffffffffffffffffffffffff",
+		mnemonic: "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+		seed:     "ac27495480225222079d7be181583751e86f571027b0497b5b5d11218e0a8a13332572917f0f8e5a589620c6f15b11c61dee327651a14c34e18231052e48c069",
+	},
+	{
+		entropy:  "0460ef47585604c5660618db2e6a7e7f",
+		mnemonic: "afford alter spike radar gate glance object seek swamp infant panel yellow",
+		seed:     "65f93a9f36b6c85cbe634ffc1f99f2b82cbb10b31edc7f087b4f6cb9e976e9faf76ff41f8f27c99afdf38f7a303ba1136ee48a4c1e7fcd3dba7aa876113a36e4",
+	},
+	{
+		entropy:  "0000000000000000000000000000000000000000000000000000000000000000",
+		mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art",
+		seed:     "bda85446c68413707090a52022edd26a1c9462295029f2e60cd7c4f2bbd3097170af7a4d73245cafa9c3cca8d561a7c3de6f5d4a10be8ed2a5e608d68f92fcc8",
+	},
+	{
+		entropy:  "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		mnemonic: "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote",
+		seed:     "dd48c104698c30cfe2b6142103248622fb7bb0ff692eebb00089b32d22484e1613912f0a5b694407be899ffd31ed3992c456cdf60f5d4564b8ba3f05a69890ad",
+	},
+}
+
+func TestVectors(t *testing.T) {
+	for _, v := range vectors {
+		t.Run(v.mnemonic[:20], func(t *testing.T) {
+			entropy, err := hex.DecodeString(v.entropy)
+			require.NoError(t, err)
+
+			mnemonic, err := EntropyToMnemonic(entropy, English)
+			require.NoError(t, err)
+			assert.Equal(t, v.mnemonic, mnemonic)
+
+			decoded, err := MnemonicToEntropy(mnemonic, English)
+			require.NoError(t, err)
+			assert.Equal(t, entropy, decoded)
+
+			assert.Equal(t, v.seed, hex.EncodeToString(Seed(mnemonic, "TREZOR")))
+		})
+	}
+}
+
+func TestWordlists(t *testing.T) {
+	for _, lang := range Languages() {
+		t.Run(string(lang), func(t *testing.T) {
+			words, err := Words(lang)
+			require.NoError(t, err)
+			assert.Len(t, words, 2048)
+			for _, word := range words {
+				assert.True(t, norm.NFKD.IsNormalString(word), word)
+			}
+		})
+	}
+
+	_, err := Words("klingon")
+	assert.ErrorIs(t, err, ErrUnknownLanguage)
+}
+
+func TestParseLanguage(t *testing.T) {
+	lang, err := ParseLanguage("Chinese_Simplified")
+	require.NoError(t, err)
+	assert.Equal(t, ChineseSimplified, lang)
+
+	_, err = ParseLanguage("latin")
+	assert.ErrorIs(t, err, ErrUnknownLanguage)
+}
+
+func TestNewMnemonic(t *testing.T) {
+	for _, bits := range []int{128, 160, 192, 224, 256} {
+		for _, lang := range Languages() {
+			mnemonic, err := NewMnemonic(nil, bits, lang)
+			require.NoError(t, err)
+			assert.Len(t, strings.Fields(Normalize(mnemonic)), bits*3/32, lang)
+			assert.NoError(t, Validate(mnemonic, lang))
+		}
+	}
+
+	for _, bits := range []int{0, 96, 129, 288} {
+		_, err := NewMnemonic(nil, bits, English)
+		assert.ErrorIs(t, err, ErrInvalidEntropy)
+	}
+}
+
+func TestNewMnemonicSeeded(t *testing.T) {
+	first, err := NewMnemonic(security.NewInsecureSeededSource("bip39"), 128, English)
+	require.NoError(t, err)
+	second, err := NewMnemonic(security.NewInsecureSeededSource("bip39"), 128, English)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+}
+
+func TestJapaneseSeparator(t *testing.T) {
+	mnemonic, err := EntropyToMnemonic(make([]byte, 16), Japanese)
+	require.NoError(t, err)
+	assert.Contains(t, mnemonic, "　")
+	assert.NotContains(t, mnemonic, " ")
+
+	// NFKD turns the ideographic space into a plain one
+	assert.NoError(t, Validate(strings.ReplaceAll(mnemonic, "　", " "), Japanese))
+}
+
+func TestValidate(t *testing.T) {
+	valid := vectors[2].mnemonic
+
+	tests := []struct {
+		name     string
+		mnemonic string
+		lang     Language
+		want     error
+	}{
+		{"valid", valid, English, nil},
+		{"extra whitespace", "  " + strings.ReplaceAll(valid, " ", " \t\n ") + " ", English, nil},
+		{"wrong checksum", strings.Replace(valid, "yellow", "zoo", 1), English, ErrChecksum},
+		{"unknown word", strings.Replace(valid, "afford", "glyphic", 1), English, ErrUnknownWord},
+		{"too few words", "abandon abandon abandon", English, ErrInvalidMnemonic},
+		{"13 words", valid + " zoo", English, ErrInvalidMnemonic},
+		{"wrong language", valid, Italian, ErrUnknownWord},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.mnemonic, tt.lang)
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestSeedKeepsCase(t *testing.T) {
+	// Words are matched ignoring case, but like the reference implementation
+	// the seed is derived from the NFKD form only
+	const mixed = "Abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon About"
+	require.NoError(t, Validate(mixed, English))
+	assert.Equal(t, "8f23bbc15e59d6d10a5f459417255a2311e0686905456afe81918f4640d9de8dc187dbe97d3f1b5bcd35e0a1a64ac77fec99a34c538ff062fa114f26f109611e",
+		hex.EncodeToString(Seed(mixed, "TREZOR")))
+	assert.Equal(t, vectors[0].seed, hex.EncodeToString(Seed(strings.ToLower(mixed), "TREZOR")))
+	assert.Equal(t, mixed, Normalize("  "+strings.ReplaceAll(mixed, " ", "\t ")+"\n"))
+}
+
+func TestValidateNormalizesComposedInput(t *testing.T) {
+	// Accented words must match whether typed precomposed or decomposed
+	mnemonic, err := EntropyToMnemonic(make([]byte, 16), Spanish) // "ábaco ábaco ..."
+	require.NoError(t, err)
+
+	composed := norm.NFC.String(mnemonic)
+	decomposed := norm.NFD.String(mnemonic)
+	require.NotEqual(t, composed, decomposed, "vector needs an accented word")
+	assert.NoError(t, Validate(composed, Spanish))
+	assert.NoError(t, Validate(decomposed, Spanish))
+	assert.Equal(t, Seed(composed, "contraseña"), Seed(decomposed, norm.NFD.String("contraseña")))
+}
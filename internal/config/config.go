@@ -0,0 +1,365 @@
+// Package config loads glyphic settings from an XDG config file with named
+// profiles and layers them with environment variables and command-line flags.
+//
+// Settings are kept as raw strings keyed by their flag name so that every
+// layer is parsed and validated by the same code path. Precedence, lowest
+// first, is: defaults < config file < selected profile < environment < flags.
+package config
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+var (
+	// ErrInvalidConfig indicates the config file could not be parsed or has invalid values
+	ErrInvalidConfig = errors.New("invalid config")
+
+	// ErrUnknownProfile indicates the requested profile is not defined in the config file
+	ErrUnknownProfile = errors.New("unknown profile")
+
+	// ErrUnknownSetting indicates a config key that does not correspond to any setting
+	ErrUnknownSetting = errors.New("unknown setting")
+
+	// ErrConfigExists indicates Init would overwrite an existing config file
+	ErrConfigExists = errors.New("config file already exists")
+)
+
+// EnvPrefix is the prefix for environment variable overrides
+const EnvPrefix = "GLYPHIC_"
+
+// Source identifies the layer a setting was resolved from
+type Source int
+
+const (
+	SourceDefault Source = iota // built-in default
+	SourceConfig                // top level of the config file
+	SourceProfile               // a [profiles.NAME] table in the config file
+	SourceEnv                   // GLYPHIC_* environment variable
+	SourceFlag                  // command-line flag
+)
+
+// String returns the layer name
+func (s Source) String() string {
+	switch s {
+	case SourceDefault:
+		return "default"
+	case SourceConfig:
+		return "config"
+	case SourceProfile:
+		return "profile"
+	case SourceEnv:
+		return "env"
+	case SourceFlag:
+		return "flag"
+	default:
+		return "unknown"
+	}
+}
+
+// Layer is a set of raw setting values from a single source
+type Layer struct {
+	Source Source
+	Origin string              // Human-readable location, e.g. a file path or variable name
+	Values map[string][]string // Setting key -> raw values (repeatable settings may hold several)
+}
+
+// Setting is a resolved setting value and where it came from
+type Setting struct {
+	Key    string
+	Values []string
+	Source Source
+	Origin string
+}
+
+// File is a parsed config file
+type File struct {
+	Path     string                         // Location the file was loaded from
+	Profile  string                         // Default profile selected by the top-level "profile" key
+	Base     map[string][]string            // Top-level settings
+	Profiles map[string]map[string][]string // Named profile settings
+}
+
+// DefaultPath returns $XDG_CONFIG_HOME/glyphic/config.toml, falling back to
+// ~/.config/glyphic/config.toml
+func DefaultPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" && filepath.IsAbs(dir) {
+		return filepath.Join(dir, "glyphic", "config.toml"), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "glyphic", "config.toml"), nil
+}
+
+// Load reads and parses the config file at path. A missing file is reported
+// with an error satisfying errors.Is(err, fs.ErrNotExist).
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	return Parse(data, path)
+}
+
+// Parse parses TOML config data; path is only used in error messages
+func Parse(data []byte, path string) (*File, error) {
+	var raw map[string]any
+	if _, err := toml.Decode(string(data), &raw); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidConfig, path, err)
+	}
+
+	file := &File{
+		Path:     path,
+		Base:     make(map[string][]string),
+		Profiles: make(map[string]map[string][]string),
+	}
+
+	for key, value := range raw {
+		switch key {
+		case "profile":
+			name, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s: profile must be a string", ErrInvalidConfig, path)
+			}
+			file.Profile = name
+
+		case "profiles":
+			profiles, ok := value.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s: profiles must be a table", ErrInvalidConfig, path)
+			}
+			for name, table := range profiles {
+				settings, ok := table.(map[string]any)
+				if !ok {
+					return nil, fmt.Errorf("%w: %s: profiles.%s must be a table", ErrInvalidConfig, path, name)
+				}
+				values, err := flatten(settings)
+				if err != nil {
+					return nil, fmt.Errorf("%w: %s: profiles.%s.%w", ErrInvalidConfig, path, name, err)
+				}
+				file.Profiles[name] = values
+			}
+
+		default:
+			values, err := toStrings(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %s: %w", ErrInvalidConfig, path, key, err)
+			}
+			file.Base[key] = values
+		}
+	}
+
+	return file, nil
+}
+
+// flatten converts a table of scalar or array values into raw strings
+func flatten(table map[string]any) (map[string][]string, error) {
+	values := make(map[string][]string, len(table))
+	for key, value := range table {
+		converted, err := toStrings(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		values[key] = converted
+	}
+	return values, nil
+}
+
+// toStrings converts a TOML value into its raw string form
+func toStrings(value any) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case bool:
+		return []string{strconv.FormatBool(v)}, nil
+	case int64:
+		return []string{strconv.FormatInt(v, 10)}, nil
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}, nil
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, elem := range v {
+			if _, nested := elem.([]any); nested {
+				return nil, errors.New("nested arrays are not supported")
+			}
+			converted, err := toStrings(elem)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, converted[0])
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", value)
+	}
+}
+
+// ProfileNames returns the sorted names of all profiles in the file
+func (f *File) ProfileNames() []string {
+	return slices.Sorted(maps.Keys(f.Profiles))
+}
+
+// Layers returns the config layers for the named profile. An empty name
+// selects the file's default profile, if any.
+func (f *File) Layers(profile string) ([]Layer, error) {
+	layers := []Layer{{
+		Source: SourceConfig,
+		Origin: f.Path,
+		Values: f.Base,
+	}}
+
+	profile = cmp.Or(profile, f.Profile)
+	if profile == "" {
+		return layers, nil
+	}
+
+	values, ok := f.Profiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (defined: %s)", ErrUnknownProfile, profile, strings.Join(f.ProfileNames(), ", "))
+	}
+
+	return append(layers, Layer{
+		Source: SourceProfile,
+		Origin: fmt.Sprintf("%s [profiles.%s]", f.Path, profile),
+		Values: values,
+	}), nil
+}
+
+// CheckKeys verifies that every key in the file, including those in
+// unselected profiles, is accepted by known
+func (f *File) CheckKeys(known func(key string) bool) error {
+	var errs []error
+
+	for _, key := range slices.Sorted(maps.Keys(f.Base)) {
+		if !known(key) {
+			errs = append(errs, fmt.Errorf("%w: %s: %q", ErrUnknownSetting, f.Path, key))
+		}
+	}
+	for _, name := range f.ProfileNames() {
+		for _, key := range slices.Sorted(maps.Keys(f.Profiles[name])) {
+			if !known(key) {
+				errs = append(errs, fmt.Errorf("%w: %s: profiles.%s: %q", ErrUnknownSetting, f.Path, name, key))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// EnvName returns the environment variable name for a setting key,
+// e.g. "min-wordlists" -> "GLYPHIC_MIN_WORDLISTS"
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
+// EnvLayer builds a layer from GLYPHIC_* variables for the given keys
+func EnvLayer(keys []string, lookup func(string) (string, bool)) Layer {
+	layer := Layer{
+		Source: SourceEnv,
+		Origin: "environment",
+		Values: make(map[string][]string),
+	}
+
+	for _, key := range keys {
+		if value, ok := lookup(EnvName(key)); ok {
+			layer.Values[key] = []string{value}
+		}
+	}
+
+	return layer
+}
+
+// Resolve merges layers in order; later layers override earlier ones per key
+func Resolve(layers ...Layer) map[string]Setting {
+	resolved := make(map[string]Setting)
+
+	for _, layer := range layers {
+		for key, values := range layer.Values {
+			origin := layer.Origin
+			if layer.Source == SourceEnv {
+				origin = EnvName(key)
+			}
+			resolved[key] = Setting{
+				Key:    key,
+				Values: values,
+				Source: layer.Source,
+				Origin: origin,
+			}
+		}
+	}
+
+	return resolved
+}
+
+// Init writes the commented default config template to path. Existing files
+// are only replaced when force is set.
+func Init(path string, force bool) error {
+	if !force {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%w: %s", ErrConfigExists, path)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, []byte(Template), 0600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	return nil
+}
+
+// Template is the default config file written by Init
+const Template = `# glyphic configuration
+#
+# Keys match the long command-line flags. Precedence, lowest first:
+#   defaults < this file < selected profile < GLYPHIC_* env vars < flags
+#
+# Select a profile with --profile NAME or GLYPHIC_PROFILE=NAME.
+
+# Profile applied when none is given on the command line
+# profile = "work"
+
+words = 6
+capitalize = "first"
+separator = "dash"
+color = "matrix"
+speed = "normal"
+
+# Defaults to the number of lists the language ships: 2 for en and de, 1 for
+# es, fr, it and ja, 3 for all
+# language = "en"
+# min-wordlists = 2
+
+# wordlist = ["~/wordlists/team.txt"]
+# exclude-file = ["~/wordlists/banned.txt"]
+
+[profiles.work]
+words = 8
+numbers = true
+number-count = 2
+
+[profiles.wifi]
+words = 4
+capitalize = "none"
+separator = "space"
+
+[profiles.ssh]
+words = 10
+special = true
+special-count = 2
+`
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `
+profile = "work"
+words = 6
+capitalize = "first"
+wordlist = ["~/a.txt", "~/b.txt"]
+
+[profiles.work]
+words = 8
+numbers = true
+
+[profiles.wifi]
+separator = "space"
+`
+
+func TestParse(t *testing.T) {
+	file, err := Parse([]byte(testConfig), "config.toml")
+	require.NoError(t, err)
+
+	assert.Equal(t, "work", file.Profile)
+	assert.Equal(t, []string{"6"}, file.Base["words"])
+	assert.Equal(t, []string{"first"}, file.Base["capitalize"])
+	assert.Equal(t, []string{"~/a.txt", "~/b.txt"}, file.Base["wordlist"])
+	assert.Equal(t, []string{"wifi", "work"}, file.ProfileNames())
+	assert.Equal(t, []string{"8"}, file.Profiles["work"]["words"])
+	assert.Equal(t, []string{"true"}, file.Profiles["work"]["numbers"])
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"malformed toml", "words = "},
+		{"profile not a string", "profile = 3"},
+		{"profiles not a table", "profiles = 3"},
+		{"profile entry not a table", "[profiles]\nwork = 3"},
+		{"nested table", "[colors]\nscrambled = \"#00FF00\""},
+		{"nested array", "wordlist = [[\"a\"]]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data), "config.toml")
+			assert.ErrorIs(t, err, ErrInvalidConfig)
+		})
+	}
+}
+
+func TestLayers(t *testing.T) {
+	file, err := Parse([]byte(testConfig), "config.toml")
+	require.NoError(t, err)
+
+	t.Run("default profile from file", func(t *testing.T) {
+		layers, err := file.Layers("")
+		require.NoError(t, err)
+		require.Len(t, layers, 2)
+		assert.Equal(t, SourceConfig, layers[0].Source)
+		assert.Equal(t, SourceProfile, layers[1].Source)
+		assert.Equal(t, "config.toml [profiles.work]", layers[1].Origin)
+	})
+
+	t.Run("explicit profile", func(t *testing.T) {
+		layers, err := file.Layers("wifi")
+		require.NoError(t, err)
+		require.Len(t, layers, 2)
+		assert.Equal(t, []string{"space"}, layers[1].Values["separator"])
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		_, err := file.Layers("ssh")
+		assert.ErrorIs(t, err, ErrUnknownProfile)
+	})
+
+	t.Run("no profile", func(t *testing.T) {
+		file.Profile = ""
+		layers, err := file.Layers("")
+		require.NoError(t, err)
+		assert.Len(t, layers, 1)
+	})
+}
+
+func TestCheckKeys(t *testing.T) {
+	file, err := Parse([]byte(testConfig+"\n[profiles.ssh]\nbogus = 1\n"), "config.toml")
+	require.NoError(t, err)
+
+	known := map[string]bool{"words": true, "capitalize": true, "wordlist": true, "numbers": true, "separator": true}
+	err = file.CheckKeys(func(key string) bool { return known[key] })
+	assert.ErrorIs(t, err, ErrUnknownSetting)
+	assert.Contains(t, err.Error(), "profiles.ssh")
+
+	known["bogus"] = true
+	assert.NoError(t, file.CheckKeys(func(key string) bool { return known[key] }))
+}
+
+func TestResolvePrecedence(t *testing.T) {
+	env := map[string]string{"GLYPHIC_WORDS": "5", "GLYPHIC_SEPARATOR": "none"}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	resolved := Resolve(
+		Layer{Source: SourceDefault, Origin: "built-in", Values: map[string][]string{"words": {"6"}, "separator": {"dash"}, "color": {"matrix"}}},
+		Layer{Source: SourceConfig, Origin: "config.toml", Values: map[string][]string{"words": {"7"}, "color": {"nord"}}},
+		Layer{Source: SourceProfile, Origin: "config.toml [profiles.work]", Values: map[string][]string{"words": {"8"}}},
+		EnvLayer([]string{"words", "separator", "color"}, lookup),
+		Layer{Source: SourceFlag, Origin: "command line", Values: map[string][]string{"words": {"4"}}},
+	)
+
+	assert.Equal(t, Setting{Key: "words", Values: []string{"4"}, Source: SourceFlag, Origin: "command line"}, resolved["words"])
+	assert.Equal(t, Setting{Key: "separator", Values: []string{"none"}, Source: SourceEnv, Origin: "GLYPHIC_SEPARATOR"}, resolved["separator"])
+	assert.Equal(t, Setting{Key: "color", Values: []string{"nord"}, Source: SourceConfig, Origin: "config.toml"}, resolved["color"])
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "GLYPHIC_WORDS", EnvName("words"))
+	assert.Equal(t, "GLYPHIC_MIN_WORDLISTS", EnvName("min-wordlists"))
+}
+
+func TestSourceString(t *testing.T) {
+	assert.Equal(t, "default", SourceDefault.String())
+	assert.Equal(t, "config", SourceConfig.String())
+	assert.Equal(t, "profile", SourceProfile.String())
+	assert.Equal(t, "env", SourceEnv.String())
+	assert.Equal(t, "flag", SourceFlag.String())
+	assert.Equal(t, "unknown", Source(99).String())
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Run("XDG_CONFIG_HOME", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", dir)
+		path, err := DefaultPath()
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "glyphic", "config.toml"), path)
+	})
+
+	t.Run("home fallback", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", "")
+		t.Setenv("HOME", home)
+		path, err := DefaultPath()
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(home, ".config", "glyphic", "config.toml"), path)
+	})
+}
+
+func TestLoadAndInit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "glyphic", "config.toml")
+
+	_, err := Load(path)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	require.NoError(t, Init(path, false))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	file, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ssh", "wifi", "work"}, file.ProfileNames())
+
+	assert.ErrorIs(t, Init(path, false), ErrConfigExists)
+	assert.NoError(t, Init(path, true))
+}
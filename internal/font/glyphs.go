@@ -108,6 +108,7 @@ var MatrixGlyphs = struct {
 type GlyphSet struct {
 	Mode   TerminalMode
 	Glyphs []rune
+	Rand   security.RandomSource // source for SelectRandomGlyph, crypto/rand when nil
 }
 
 // GetGlyphSet returns the appropriate glyph set for the terminal mode
@@ -131,13 +132,13 @@ func GetGlyphSet(mode TerminalMode) *GlyphSet {
 	}
 }
 
-// SelectRandomGlyph returns a cryptographically random glyph
+// SelectRandomGlyph returns a random glyph drawn from g.Rand
 func (g *GlyphSet) SelectRandomGlyph() (rune, error) {
 	if len(g.Glyphs) == 0 {
 		return 0, ErrNoGlyphsAvailable
 	}
 
-	idx, err := security.SecureRandomIndex(len(g.Glyphs))
+	idx, err := security.RandomIndex(g.Rand, len(g.Glyphs))
 	if err != nil {
 		return 0, err
 	}
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"unicode/utf8"
 
+	"github.com/greysquirr3l/glyphic/internal/security"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -119,6 +120,19 @@ func TestGlyphSetSelectRandomGlyph(t *testing.T) {
 	assert.Greater(t, len(seen), 10, "should generate varied glyphs")
 }
 
+func TestGlyphSetSelectRandomGlyphGolden(t *testing.T) {
+	gs := GetGlyphSet(TerminalDumb)
+	gs.Rand = security.NewInsecureSeededSource("golden")
+
+	var glyphs []rune
+	for range 12 {
+		glyph, err := gs.SelectRandomGlyph()
+		require.NoError(t, err)
+		glyphs = append(glyphs, glyph)
+	}
+	assert.Equal(t, "+?+~+||_/:_<", string(glyphs))
+}
+
 func TestGlyphSetCount(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,184 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"runtime"
+	"sync"
+
+	"github.com/greysquirr3l/glyphic/internal/security"
+)
+
+// ErrInvalidBatch indicates invalid batch options
+var ErrInvalidBatch = errors.New("invalid batch options")
+
+// DefaultChunkSize is the number of passwords a worker generates per chunk
+const DefaultChunkSize = 1024
+
+// BatchOptions controls WriteBatch
+type BatchOptions struct {
+	Count     int            // Number of passwords to write
+	Workers   int            // Concurrent generators (default GOMAXPROCS)
+	ChunkSize int            // Passwords per chunk (default DefaultChunkSize)
+	Progress  func(done int) // Called after each chunk is written, from the calling goroutine
+}
+
+// Stream yields passwords one at a time until the consumer stops or ctx is
+// cancelled. An error is yielded once and ends the sequence.
+func (g *Generator) Stream(ctx context.Context, opts Options) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		if err := opts.Validate(); err != nil {
+			yield("", fmt.Errorf("invalid options: %w", err))
+			return
+		}
+
+		for {
+			if err := ctx.Err(); err != nil {
+				yield("", err)
+				return
+			}
+
+			password, err := g.Generate(opts)
+			if !yield(password, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// chunk is one batch of newline-terminated passwords produced by a worker
+type chunk struct {
+	buf   []byte
+	count int
+	err   error
+}
+
+// WriteBatch generates batch.Count passwords across a pool of workers and writes
+// them to w, one per line, in no particular order. At most two chunks per worker
+// are held in memory at once, and every buffer is zeroed once it has been written.
+// It returns the number of passwords written.
+func (g *Generator) WriteBatch(ctx context.Context, w io.Writer, opts Options, batch BatchOptions) (int, error) {
+	if err := opts.Validate(); err != nil {
+		return 0, fmt.Errorf("invalid options: %w", err)
+	}
+	if batch.Count < 0 {
+		return 0, fmt.Errorf("%w: count must not be negative", ErrInvalidBatch)
+	}
+	if batch.Count == 0 {
+		return 0, nil
+	}
+
+	workers := batch.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	chunkSize := batch.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	chunks := (batch.Count + chunkSize - 1) / chunkSize
+	workers = min(workers, chunks)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Buffers circulate between workers and the writer, bounding memory use
+	buffers := make(chan []byte, 2*workers)
+	for range cap(buffers) {
+		buffers <- nil
+	}
+
+	sizes := make(chan int)
+	results := make(chan chunk, workers)
+
+	go func() {
+		defer close(sizes)
+		for i := range chunks {
+			size := min(chunkSize, batch.Count-i*chunkSize)
+			select {
+			case sizes <- size:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for size := range sizes {
+				var buf []byte
+				select {
+				case buf = <-buffers:
+				case <-ctx.Done():
+					return
+				}
+
+				result := g.fillChunk(buf[:0], opts, size)
+				select {
+				case results <- result:
+				case <-ctx.Done():
+					security.SecureZero(result.buf)
+					return
+				}
+				if result.err != nil {
+					return
+				}
+			}
+		})
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	written := 0
+	var err error
+	for result := range results {
+		if err == nil {
+			err = result.err
+		}
+		if err == nil {
+			if _, werr := w.Write(result.buf); werr != nil {
+				err = fmt.Errorf("failed to write passwords: %w", werr)
+			} else {
+				written += result.count
+				if batch.Progress != nil {
+					batch.Progress(written)
+				}
+			}
+		}
+
+		security.SecureZero(result.buf)
+		if err != nil {
+			cancel()
+		}
+
+		// Never blocks: each result holds a buffer taken from the channel
+		buffers <- result.buf
+	}
+
+	// Workers only stop early on an error or cancellation
+	if err == nil && written < batch.Count {
+		err = ctx.Err()
+	}
+
+	return written, err
+}
+
+// fillChunk appends count newline-terminated passwords to buf
+func (g *Generator) fillChunk(buf []byte, opts Options, count int) chunk {
+	for i := range count {
+		password, err := g.Generate(opts)
+		if err != nil {
+			return chunk{buf: buf, count: i, err: err}
+		}
+		buf = append(buf, password...)
+		buf = append(buf, '\n')
+	}
+	return chunk{buf: buf, count: count}
+}
@@ -0,0 +1,201 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStream(t *testing.T) {
+	gen := setupTestGenerator(t)
+	opts := Options{WordCount: 3, MinWordlists: 3, Separator: SepDash}
+
+	t.Run("yields until consumer stops", func(t *testing.T) {
+		var passwords []string
+		for password, err := range gen.Stream(context.Background(), opts) {
+			require.NoError(t, err)
+			passwords = append(passwords, password)
+			if len(passwords) == 25 {
+				break
+			}
+		}
+		assert.Len(t, passwords, 25)
+		for _, password := range passwords {
+			assert.Len(t, strings.Split(password, "-"), 3)
+		}
+	})
+
+	t.Run("stops on cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		count := 0
+		var last error
+		for _, err := range gen.Stream(ctx, opts) {
+			if err != nil {
+				last = err
+				continue
+			}
+			count++
+			if count == 3 {
+				cancel()
+			}
+		}
+		assert.Equal(t, 3, count)
+		assert.ErrorIs(t, last, context.Canceled)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		calls := 0
+		for _, err := range gen.Stream(context.Background(), Options{WordCount: 1}) {
+			calls++
+			assert.ErrorIs(t, err, ErrInvalidWordCount)
+		}
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestWriteBatch(t *testing.T) {
+	gen := setupTestGenerator(t)
+	opts := Options{WordCount: 3, MinWordlists: 3, Separator: SepUnderscore}
+
+	tests := []struct {
+		name  string
+		batch BatchOptions
+	}{
+		{"single chunk", BatchOptions{Count: 10}},
+		{"uneven chunks", BatchOptions{Count: 1001, Workers: 4, ChunkSize: 100}},
+		{"more workers than chunks", BatchOptions{Count: 5, Workers: 16, ChunkSize: 2}},
+		{"single worker", BatchOptions{Count: 300, Workers: 1, ChunkSize: 7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var progress []int
+			tt.batch.Progress = func(done int) {
+				mu.Lock()
+				defer mu.Unlock()
+				progress = append(progress, done)
+			}
+
+			var out bytes.Buffer
+			written, err := gen.WriteBatch(context.Background(), &out, opts, tt.batch)
+			require.NoError(t, err)
+			assert.Equal(t, tt.batch.Count, written)
+
+			lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+			assert.Len(t, lines, tt.batch.Count)
+			for _, line := range lines {
+				assert.Regexp(t, `^[a-z]+_[a-z]+_[a-z]+$`, line)
+			}
+
+			require.NotEmpty(t, progress)
+			assert.IsIncreasing(t, progress)
+			assert.Equal(t, tt.batch.Count, progress[len(progress)-1])
+		})
+	}
+
+	t.Run("zero count", func(t *testing.T) {
+		var out bytes.Buffer
+		written, err := gen.WriteBatch(context.Background(), &out, opts, BatchOptions{})
+		require.NoError(t, err)
+		assert.Zero(t, written)
+		assert.Zero(t, out.Len())
+	})
+
+	t.Run("negative count", func(t *testing.T) {
+		_, err := gen.WriteBatch(context.Background(), &bytes.Buffer{}, opts, BatchOptions{Count: -1})
+		assert.ErrorIs(t, err, ErrInvalidBatch)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := gen.WriteBatch(context.Background(), &bytes.Buffer{}, Options{WordCount: 1}, BatchOptions{Count: 1})
+		assert.ErrorIs(t, err, ErrInvalidWordCount)
+	})
+}
+
+func TestWriteBatchCancellation(t *testing.T) {
+	gen := setupTestGenerator(t)
+	opts := Options{WordCount: 3, MinWordlists: 3, Separator: SepDash}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var out bytes.Buffer
+	written, err := gen.WriteBatch(ctx, &out, opts, BatchOptions{
+		Count:     1_000_000,
+		Workers:   4,
+		ChunkSize: 100,
+		Progress: func(done int) {
+			if done >= 500 {
+				cancel()
+			}
+		},
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.GreaterOrEqual(t, written, 500)
+	assert.Less(t, written, 1_000_000)
+	assert.Equal(t, written, strings.Count(out.String(), "\n"))
+}
+
+// failingWriter fails after accepting a number of writes
+type failingWriter struct {
+	writes int
+}
+
+var errWriteFailed = errors.New("disk full")
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.writes == 0 {
+		return 0, errWriteFailed
+	}
+	w.writes--
+	return len(p), nil
+}
+
+func TestWriteBatchWriterError(t *testing.T) {
+	gen := setupTestGenerator(t)
+	opts := Options{WordCount: 3, MinWordlists: 3}
+
+	written, err := gen.WriteBatch(context.Background(), &failingWriter{writes: 2}, opts, BatchOptions{
+		Count:     10_000,
+		Workers:   2,
+		ChunkSize: 10,
+	})
+	assert.ErrorIs(t, err, errWriteFailed)
+	assert.Equal(t, 20, written)
+}
+
+func TestWriteBatchGenerationError(t *testing.T) {
+	gen := setupTestGenerator(t)
+	gen.exclusions.Add("apple", "banana", "cherry", "date", "elderberry")
+	opts := Options{WordCount: 3, MinWordlists: 3}
+
+	written, err := gen.WriteBatch(context.Background(), &bytes.Buffer{}, opts, BatchOptions{Count: 100, Workers: 2, ChunkSize: 10})
+	assert.ErrorIs(t, err, ErrNoWordsAvailable)
+	assert.Zero(t, written)
+}
+
+func BenchmarkWriteBatch(b *testing.B) {
+	gen, opts := setupBenchmarkGenerator(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for b.Loop() {
+		if _, err := gen.WriteBatch(context.Background(), discard{}, opts, BatchOptions{Count: 10_000}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// discard is an io.Writer that drops everything without zero-copy shortcuts
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) { return len(p), nil }
@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/greysquirr3l/glyphic/internal/security"
+)
+
+// DeriveVersion identifies the site password derivation described in
+// docs/derive-v1.md. Any change that alters derived passwords needs a new version.
+const DeriveVersion = 1
+
+// Argon2id parameters of derivation version 1
+const (
+	deriveTime    = 3
+	deriveMemory  = 64 * 1024 // KiB
+	deriveThreads = 4
+	deriveKeyLen  = 32
+)
+
+var (
+	ErrEmptyMaster = errors.New("master passphrase must not be empty")
+	ErrEmptySite   = errors.New("site must not be empty")
+)
+
+// Site identifies one derived password
+type Site struct {
+	Name     string // site or host name, compared case-insensitively
+	Username string // account name, compared exactly
+	Counter  uint32 // bumped to rotate the password
+}
+
+// normalized returns the site with NFC-normalised, trimmed fields and the name
+// lowercased
+func (s Site) normalized() Site {
+	return Site{
+		Name:     strings.ToLower(strings.TrimSpace(norm.NFC.String(s.Name))),
+		Username: strings.TrimSpace(norm.NFC.String(s.Username)),
+		Counter:  s.Counter,
+	}
+}
+
+// salt encodes the version, the length-prefixed name and username and the
+// counter, so no two sites share a salt
+func (s Site) salt() []byte {
+	s = s.normalized()
+	salt := fmt.Appendf(nil, "glyphic-derive-v%d", DeriveVersion)
+	for _, field := range []string{s.Name, s.Username} {
+		salt = binary.BigEndian.AppendUint32(salt, uint32(len(field)))
+		salt = append(salt, field...)
+	}
+	return binary.BigEndian.AppendUint32(salt, s.Counter)
+}
+
+// DeriveKey stretches the NFC-normalised master passphrase into a 32-byte key
+// for site with Argon2id. The caller should zero the key with
+// security.SecureZero when done.
+func DeriveKey(master []byte, site Site) ([]byte, error) {
+	if len(master) == 0 {
+		return nil, ErrEmptyMaster
+	}
+	if site.normalized().Name == "" {
+		return nil, ErrEmptySite
+	}
+
+	password := norm.NFC.Append(nil, master...) // a copy, unlike NFC.Bytes
+	defer security.SecureZero(password)
+
+	return argon2.IDKey(password, site.salt(), deriveTime, deriveMemory, deriveThreads, deriveKeyLen), nil
+}
+
+// Derive returns the password for site. The derived key seeds a ChaCha20
+// keystream that replaces the random source for one generation, so the same
+// master, site, options and wordlists always give the same password.
+func (g *Generator) Derive(master []byte, site Site, opts Options) (string, error) {
+	key, err := DeriveKey(master, site)
+	if err != nil {
+		return "", err
+	}
+	defer security.SecureZero(key)
+
+	src, err := security.NewKeyedSource(key)
+	if err != nil {
+		return "", err
+	}
+	return g.generate(src, opts)
+}
@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSiteSalt(t *testing.T) {
+	salt := Site{Name: " Example.COM ", Username: "alice", Counter: 1}.salt()
+	assert.Equal(t, "glyphic-derive-v1"+
+		"\x00\x00\x00\x0bexample.com"+
+		"\x00\x00\x00\x05alice"+
+		"\x00\x00\x00\x01", string(salt))
+
+	// Length prefixes keep shifted boundaries apart
+	assert.NotEqual(t, Site{Name: "ab", Username: "c"}.salt(), Site{Name: "a", Username: "bc"}.salt())
+}
+
+func TestDeriveKey(t *testing.T) {
+	master := []byte("correct horse battery staple")
+	site := Site{Name: "example.com", Username: "alice", Counter: 1}
+
+	key, err := DeriveKey(master, site)
+	require.NoError(t, err)
+	assert.Equal(t, "262263a7ff62e2879485fb90f77d9946fe0ed119c125b82dae81f387e42cd9a4", hex.EncodeToString(key))
+	assert.Equal(t, "correct horse battery staple", string(master), "master must not be zeroed")
+
+	aws, err := DeriveKey(master, Site{Name: "aws.amazon.com", Username: "root", Counter: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "75df9ec3993116c56dcccf8ca0e96e23665f32b406d35f26a92f2118d848f334", hex.EncodeToString(aws))
+
+	// Site case and surrounding space do not matter; everything else does
+	same, err := DeriveKey(master, Site{Name: "  EXAMPLE.com", Username: "alice", Counter: 1})
+	require.NoError(t, err)
+	assert.Equal(t, key, same)
+
+	for _, other := range []Site{
+		{Name: "example.org", Username: "alice", Counter: 1},
+		{Name: "example.com", Username: "Alice", Counter: 1},
+		{Name: "example.com", Username: "alice", Counter: 2},
+	} {
+		different, err := DeriveKey(master, other)
+		require.NoError(t, err)
+		assert.NotEqual(t, key, different, other)
+	}
+
+	_, err = DeriveKey(nil, site)
+	assert.ErrorIs(t, err, ErrEmptyMaster)
+	_, err = DeriveKey(master, Site{Name: "  "})
+	assert.ErrorIs(t, err, ErrEmptySite)
+}
+
+func TestDerive(t *testing.T) {
+	gen := setupTestGenerator(t)
+	master := []byte("correct horse battery staple")
+	site := Site{Name: "example.com", Username: "alice", Counter: 1}
+	opts := Options{WordCount: 4, Capitalization: CapRandom, Separator: SepDash, MinWordlists: 3, AddNumbers: true, NumberCount: 2}
+
+	password, err := gen.Derive(master, site, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "elderbeRrY-LettUCE-HaWk-dATe83", password)
+
+	again, err := gen.Derive(master, site, opts)
+	require.NoError(t, err)
+	assert.Equal(t, password, again)
+
+	// Deriving leaves the generator's own random source alone
+	random, err := gen.Generate(opts)
+	require.NoError(t, err)
+	assert.NotEmpty(t, random)
+}
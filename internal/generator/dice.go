@@ -0,0 +1,125 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/greysquirr3l/glyphic/internal/wordlist"
+)
+
+var (
+	ErrDiceUnsupported = errors.New("option cannot be used with physical dice")
+	ErrDiceRollCount   = errors.New("wrong number of dice rolls")
+)
+
+// DiceSlot is one word of a passphrase built from physical dice rolls
+type DiceSlot struct {
+	List *wordlist.Wordlist
+	Dice int     // dice rolled for the word
+	Bits float64 // log2 of the rolls that give a usable word
+}
+
+// DicePlan returns the wordlist and number of dice for every word of a
+// passphrase built from physical dice. Lists with a dice index are taken in ID
+// order and used round-robin, so no software randomness is involved. Options
+// that need random draws, policies and target entropy are rejected.
+func (g *Generator) DicePlan(opts Options) ([]DiceSlot, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid options: %w", err)
+	}
+
+	switch {
+	case !opts.UsesWordlists():
+		return nil, fmt.Errorf("%w: the %s strategy", ErrDiceUnsupported, opts.Strategy)
+	case opts.Template != "":
+		return nil, fmt.Errorf("%w: passphrase templates", ErrDiceUnsupported)
+	case opts.Capitalization == CapRandom:
+		return nil, fmt.Errorf("%w: random capitalization", ErrDiceUnsupported)
+	case opts.Substitutions != nil && !opts.SubstituteAll:
+		return nil, fmt.Errorf("%w: random substitutions", ErrDiceUnsupported)
+	case opts.AddNumbers:
+		return nil, fmt.Errorf("%w: random digits", ErrDiceUnsupported)
+	case opts.AddSpecial:
+		return nil, fmt.Errorf("%w: random special characters", ErrDiceUnsupported)
+	case opts.Policy != nil:
+		return nil, fmt.Errorf("%w: password policies", ErrDiceUnsupported)
+	case opts.TargetEntropyBits > 0:
+		return nil, fmt.Errorf("%w: target entropy", ErrDiceUnsupported)
+	}
+
+	numLists := opts.listCount()
+	lists := g.manager.DiceLists()
+	if len(lists) < numLists {
+		return nil, fmt.Errorf("%w: need %d with a dice index, have %d", ErrNotEnoughWordlists, numLists, len(lists))
+	}
+	lists = lists[:numLists]
+
+	slots := make([]DiceSlot, opts.WordCount)
+	for i := range slots {
+		list := lists[i%numLists]
+		usable := g.usableRolls(list)
+		if usable == 0 {
+			return nil, fmt.Errorf("%w: wordlist %s has no available words after filtering", ErrNoWordsAvailable, list.Source.ID)
+		}
+		slots[i] = DiceSlot{List: list, Dice: list.DiceCount(), Bits: math.Log2(float64(usable))}
+	}
+	return slots, nil
+}
+
+// usableRolls counts the rolls of list whose word survives exclusion filtering
+func (g *Generator) usableRolls(list *wordlist.Wordlist) int {
+	pool := g.pool(list)
+	usable := 0
+	for _, word := range list.Dice {
+		if _, found := slices.BinarySearch(pool, word); found {
+			usable++
+		}
+	}
+	return usable
+}
+
+// DiceWord returns the word a roll gives for slot. Rolls of excluded words
+// return wordlist.ErrUnusableRoll, so the dice are rolled again.
+func (g *Generator) DiceWord(slot DiceSlot, roll string) (string, error) {
+	word, err := slot.List.DiceWord(roll)
+	if err != nil {
+		return "", err
+	}
+	if _, found := slices.BinarySearch(g.pool(slot.List), word); !found {
+		return "", fmt.Errorf("%w: %q is excluded", wordlist.ErrUnusableRoll, word)
+	}
+	return word, nil
+}
+
+// GenerateFromDice builds a passphrase from one roll per word of the plan for
+// opts, applying the usual capitalization and separator
+func (g *Generator) GenerateFromDice(opts Options, rolls []string) (string, error) {
+	slots, err := g.DicePlan(opts)
+	if err != nil {
+		return "", err
+	}
+	if len(rolls) != len(slots) {
+		return "", fmt.Errorf("%w: need %d, got %d", ErrDiceRollCount, len(slots), len(rolls))
+	}
+
+	words := make([]string, len(slots))
+	for i, slot := range slots {
+		if words[i], err = g.DiceWord(slot, rolls[i]); err != nil {
+			return "", fmt.Errorf("word %d: %w", i+1, err)
+		}
+	}
+
+	// Only CapRandom and random substitutions draw randomness, and DicePlan
+	// rejects them
+	words, err = applyCapitalization(nil, words, opts.Capitalization, opts.caseRules())
+	if err != nil {
+		return "", fmt.Errorf("failed to apply capitalization: %w", err)
+	}
+	if words, err = substitute(nil, words, opts); err != nil {
+		return "", fmt.Errorf("failed to apply substitutions: %w", err)
+	}
+	return strings.Join(words, getSeparator(opts.Separator, opts.CustomSep)), nil
+}
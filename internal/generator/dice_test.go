@@ -0,0 +1,126 @@
+package generator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/greysquirr3l/glyphic/internal/wordlist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupDiceGenerator creates a generator over two one-die lists and a plain list
+func setupDiceGenerator(t *testing.T) *Generator {
+	t.Helper()
+	return setupWordsGenerator(t,
+		[]string{"1\tant", "2\tbee", "3\tcat", "4\tdog", "5\teel", "6\tfox"},
+		[]string{"1\toak", "2\telm", "3\tash", "4\tyew", "5\tfir", "6\tbay"},
+		[]string{"apple", "banana", "cherry"},
+	)
+}
+
+func TestDicePlan(t *testing.T) {
+	gen := setupDiceGenerator(t)
+	gen.exclusions.Add("cat")
+
+	opts := Options{WordCount: 4, Capitalization: CapFirst, Separator: SepDash, MinWordlists: 2}
+	slots, err := gen.DicePlan(opts)
+	require.NoError(t, err)
+	require.Len(t, slots, 4)
+
+	for i, slot := range slots {
+		assert.Equal(t, 1, slot.Dice)
+		if i%2 == 0 {
+			assert.Equal(t, "list0", slot.List.Source.ID)
+			assert.InDelta(t, math.Log2(5), slot.Bits, 1e-9, "excluded word drops a roll")
+		} else {
+			assert.Equal(t, "list1", slot.List.Source.ID)
+			assert.InDelta(t, math.Log2(6), slot.Bits, 1e-9)
+		}
+	}
+
+	opts.MinWordlists = 3
+	_, err = gen.DicePlan(opts)
+	assert.ErrorIs(t, err, ErrNotEnoughWordlists)
+}
+
+func TestDicePlanUnsupported(t *testing.T) {
+	gen := setupDiceGenerator(t)
+	base := Options{WordCount: 4, MinWordlists: 2}
+
+	tests := []struct {
+		name   string
+		modify func(*Options)
+	}{
+		{"random capitalization", func(o *Options) { o.Capitalization = CapRandom }},
+		{"numbers", func(o *Options) { o.AddNumbers, o.NumberCount = true, 2 }},
+		{"specials", func(o *Options) { o.AddSpecial, o.SpecialCount = true, 1 }},
+		{"policy", func(o *Options) { o.Policy = &PolicyAD }},
+		{"target entropy", func(o *Options) { o.TargetEntropyBits = 40 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := base
+			tt.modify(&opts)
+			_, err := gen.DicePlan(opts)
+			assert.ErrorIs(t, err, ErrDiceUnsupported)
+		})
+	}
+}
+
+func TestGenerateFromDice(t *testing.T) {
+	gen := setupDiceGenerator(t)
+	gen.exclusions.Add("cat")
+
+	tests := []struct {
+		name    string
+		opts    Options
+		rolls   []string
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "first letter and dash",
+			opts:  Options{WordCount: 3, Capitalization: CapFirst, Separator: SepDash, MinWordlists: 2},
+			rolls: []string{"1", "6", "4"},
+			want:  "Ant-Bay-Dog",
+		},
+		{
+			name:  "alternating and custom separator",
+			opts:  Options{WordCount: 4, Capitalization: CapAlternating, Separator: SepCustom, CustomSep: ".", MinWordlists: 2},
+			rolls: []string{"2", "2", "5", " 3 "},
+			want:  "BEE.elm.EEL.ash",
+		},
+		{
+			name:    "excluded word",
+			opts:    Options{WordCount: 3, Separator: SepDash, MinWordlists: 2},
+			rolls:   []string{"3", "1", "1"},
+			wantErr: wordlist.ErrUnusableRoll,
+		},
+		{
+			name:    "invalid roll",
+			opts:    Options{WordCount: 3, Separator: SepDash, MinWordlists: 2},
+			rolls:   []string{"1", "7", "1"},
+			wantErr: wordlist.ErrInvalidRoll,
+		},
+		{
+			name:    "too few rolls",
+			opts:    Options{WordCount: 3, Separator: SepDash, MinWordlists: 2},
+			rolls:   []string{"1", "1"},
+			wantErr: ErrDiceRollCount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := gen.GenerateFromDice(tt.opts, tt.rolls)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,362 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/greysquirr3l/glyphic/internal/wordlist"
+)
+
+// PoolShare is one wordlist that can fill a word slot
+type PoolShare struct {
+	ListID      string
+	Words       int     // pool size after exclusion filtering
+	Probability float64 // chance the list fills the slot, 0 when not computed exactly
+}
+
+// SlotEntropy is the entropy contributed by one word of the password
+type SlotEntropy struct {
+	Position       int // selection position the slot draws its list from
+	Pools          []PoolShare
+	Bits           float64 // expected log2 of the pool size
+	Capitalization float64 // expected cased letters with CapRandom, otherwise 0
+	Substitution   float64 // expected bits of random letter substitutions
+}
+
+// EntropyBreakdown itemises the entropy of passwords generated with given options.
+// Separators are fixed strings and contribute no bits. Digits and special
+// characters form a fixed-length suffix after the last word, so they can always
+// be told apart from the words and are credited in full, even when a separator
+// uses the same characters. Only random transforms are credited: substitutions
+// drawn per letter and the positions of inserted digits and special characters.
+type EntropyBreakdown struct {
+	Slots          []SlotEntropy
+	Words          float64
+	Capitalization float64
+	Digits         float64
+	Specials       float64
+	Substitutions  float64 // random letter substitutions
+	Placement      float64 // positions of digits and special characters placed inside the passphrase
+	Characters     float64 // whole password of a charset, PIN or token strategy
+	SyllableBits   float64 // bits per syllable of a pronounceable password, not added to Total
+	Total          float64
+	Exact          bool     // false when word bits fall back to the smallest pool
+	Warnings       []string // conditions under which Total overstates the entropy
+}
+
+// MinPassphraseBits is the entropy below which a passphrase of words, random
+// or from a template, is weak: an offline attacker making 10^10 guesses a
+// second searches all 2^50 candidates in about a day and a half.
+const MinPassphraseBits = 50
+
+// Weak reports whether b describes a word passphrase below MinPassphraseBits
+func (b *EntropyBreakdown) Weak() bool {
+	return len(b.Slots) > 0 && b.Total < MinPassphraseBits
+}
+
+// slotPool is a candidate list for a selection position with its filtered words
+type slotPool struct {
+	list  *wordlist.Wordlist
+	words []string
+	p     float64
+}
+
+// EstimateEntropy calculates the entropy bits for given options
+func (g *Generator) EstimateEntropy(opts Options) (float64, error) {
+	breakdown, err := g.EntropyBreakdown(opts)
+	if err != nil {
+		return 0, err
+	}
+	return breakdown.Total, nil
+}
+
+// EntropyBreakdown models the generation process for opts. Word slot i is filled
+// from selection position i mod listCount, so each slot is credited log2 of its
+// filtered pool size averaged over the exact probability of every list landing
+// in that position. The choice of lists itself is not credited, which keeps the
+// figure a lower bound when the lists share words.
+func (g *Generator) EntropyBreakdown(opts Options) (*EntropyBreakdown, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	if opts.TargetEntropyBits > 0 {
+		resolved, err := g.ResolveTarget(opts)
+		if err != nil {
+			return nil, err
+		}
+		opts = resolved
+	}
+
+	if !opts.UsesWordlists() {
+		return g.strategyBreakdown(opts)
+	}
+	if opts.Template != "" {
+		return g.templateBreakdown(opts)
+	}
+
+	if opts.Policy != nil {
+		if err := opts.Policy.compatible(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	positions, exact, err := g.positionPools(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &EntropyBreakdown{Exact: exact}
+	if !exact {
+		b.Warnings = append(b.Warnings, "too many wordlists to compute selection odds exactly; every word is credited with the smallest pool")
+	}
+
+	for i := range opts.WordCount {
+		pos := i % len(positions)
+		slot := slotEntropy(positions[pos], opts, exact)
+		slot.Position = pos
+		b.Slots = append(b.Slots, slot)
+		b.Words += slot.Bits
+		b.Capitalization += slot.Capitalization
+		b.Substitutions += slot.Substitution
+	}
+
+	b.Digits, b.Specials = suffixBits(opts)
+	sep := getSeparator(opts.Separator, opts.CustomSep)
+	placement, warnings := placementBits(opts, wordUnits(positions, opts), sep+opts.substitutes())
+	b.Placement = placement
+	b.Total = b.Words + b.Capitalization + b.Substitutions + b.Digits + b.Specials + b.Placement
+
+	if opts.Policy != nil && lengthBinds(positions, opts) {
+		b.Warnings = append(b.Warnings, fmt.Sprintf("%s length bounds rule out some word combinations; word bits are an upper bound", opts.Policy.name()))
+	}
+	b.Warnings = append(b.Warnings, joinWarnings(positions, opts)...)
+	b.Warnings = append(b.Warnings, substitutionWarnings(opts, sep)...)
+	b.Warnings = append(b.Warnings, warnings...)
+	return b, nil
+}
+
+// suffixBits returns the bits of the appended digits and special characters
+func suffixBits(opts Options) (digits, specials float64) {
+	if opts.AddNumbers {
+		digits = float64(opts.NumberCount) * math.Log2(10)
+	}
+	if opts.AddSpecial {
+		specials = float64(opts.SpecialCount) * math.Log2(float64(len(opts.specialChars())))
+	}
+	return digits, specials
+}
+
+// lengthBinds reports whether the policy length bounds exclude any combination
+// of words the pools could produce
+func lengthBinds(positions [][]slotPool, opts Options) bool {
+	lo, hi := opts.Policy.lengthBounds(opts)
+
+	shortest, longest := 0, 0
+	for i := range opts.WordCount {
+		minLen, maxLen := math.MaxInt, 0
+		for _, pool := range positions[i%len(positions)] {
+			minLen = min(minLen, utf8.RuneCountInString(pool.words[0]))
+			maxLen = max(maxLen, utf8.RuneCountInString(pool.words[len(pool.words)-1]))
+		}
+		shortest += minLen
+		longest += maxLen
+	}
+	return shortest < lo || longest > hi
+}
+
+// positionPools returns the candidate pools for every selection position. When
+// the weighted selection distribution is too expensive to compute, every loaded
+// list is returned for every position and exact is false.
+func (g *Generator) positionPools(opts Options) (positions [][]slotPool, exact bool, err error) {
+	numLists := opts.listCount()
+
+	odds, err := g.manager.SelectionDistribution(numLists, opts.selectOptions())
+	exact = true
+	if errors.Is(err, wordlist.ErrSelectionTooLarge) {
+		// The uniform distribution covers every loaded list
+		odds, err = g.manager.SelectionDistribution(numLists, wordlist.SelectOptions{})
+		exact = false
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	positions = make([][]slotPool, numLists)
+	for _, o := range odds {
+		if !exact && len(o.List.Words) == 0 {
+			continue // never drawn by a weighted selection
+		}
+		words := g.pool(o.List)
+		if opts.Policy != nil {
+			words = g.policyPool(o.List, opts.Policy)
+		}
+		for pos, p := range o.Position {
+			if p == 0 {
+				continue
+			}
+			if len(words) == 0 {
+				return nil, false, fmt.Errorf("%w: wordlist %s has no available words after filtering", ErrNoWordsAvailable, o.List.Source.ID)
+			}
+			if !exact {
+				p = 0
+			}
+			positions[pos] = append(positions[pos], slotPool{list: o.List, words: words, p: p})
+		}
+	}
+
+	for _, pools := range positions {
+		if len(pools) == 0 {
+			return nil, false, ErrNoWordsAvailable
+		}
+	}
+	return positions, exact, nil
+}
+
+// slotEntropy averages pool bits over the list probabilities, or takes the
+// smallest pool when the probabilities are unknown
+func slotEntropy(pools []slotPool, opts Options, exact bool) SlotEntropy {
+	var slot SlotEntropy
+	for i, pool := range pools {
+		slot.Pools = append(slot.Pools, PoolShare{ListID: pool.list.Source.ID, Words: len(pool.words), Probability: pool.p})
+
+		bits := math.Log2(float64(len(pool.words)))
+		capBits, subBits := transformBits(pool.words, opts)
+
+		switch {
+		case exact:
+			slot.Bits += pool.p * bits
+			slot.Capitalization += pool.p * capBits
+			slot.Substitution += pool.p * subBits
+		case i == 0:
+			slot.Bits, slot.Capitalization, slot.Substitution = bits, capBits, subBits
+		default:
+			slot.Bits = min(slot.Bits, bits)
+			slot.Capitalization = min(slot.Capitalization, capBits)
+			slot.Substitution = min(slot.Substitution, subBits)
+		}
+	}
+	return slot
+}
+
+// joinWarnings reports adjacent word slots whose lists can render two different
+// word pairs as the same string, such as "tab"+"lemon" and "table"+"mon" without
+// a separator. Such collisions make the word bits an overestimate.
+func joinWarnings(positions [][]slotPool, opts Options) []string {
+	mode, rules := opts.compareMode(), opts.caseRules()
+	sep := getSeparator(opts.Separator, opts.CustomSep)
+	if mode == CapRandom {
+		sep = strings.ToLower(sep)
+	}
+
+	type pairKey struct {
+		left, right *wordlist.Wordlist
+		parity      int
+	}
+	checked := make(map[pairKey]bool)
+
+	var warnings []string
+	for i := range opts.WordCount - 1 {
+		left, right := positions[i%len(positions)], positions[(i+1)%len(positions)]
+		for _, l := range left {
+			for _, r := range right {
+				if len(positions) > 1 && l.list == r.list {
+					continue // a list never fills two positions
+				}
+
+				key := pairKey{left: l.list, right: r.list}
+				if mode == CapAlternating {
+					key.parity = i % 2
+				}
+				if checked[key] {
+					continue
+				}
+				checked[key] = true
+
+				c, ok := joinCollision(caseForms(l.words, mode, i, rules), caseForms(r.words, mode, i+1, rules), sep)
+				if !ok {
+					continue
+				}
+				warnings = append(warnings, fmt.Sprintf("words from %s and %s can join ambiguously: %q+%q and %q+%q both give %q; use a separator or first-letter capitalization",
+					l.list.Source.ID, r.list.Source.ID, c.left1, c.right1, c.left2, c.right2, c.left1+sep+c.right1))
+			}
+		}
+	}
+	return warnings
+}
+
+// compareMode returns the capitalization words are compared in for join
+// warnings. Substituted letters read back without their case, so words are
+// then compared in lower case like CapRandom.
+func (o *Options) compareMode() CapitalizationMode {
+	if o.Substitutions != nil {
+		return CapRandom
+	}
+	return o.Capitalization
+}
+
+// caseForms renders words as they appear in word slot i. Random case is
+// compared in lower case, since any case pattern can be drawn.
+func caseForms(words []string, mode CapitalizationMode, slot int, rules unicode.SpecialCase) []string {
+	forms := make([]string, len(words))
+	for j, word := range words {
+		switch {
+		case mode == CapRandom, mode == CapAlternating && slot%2 == 1:
+			forms[j] = strings.ToLowerSpecial(rules, word)
+		default:
+			// applyCapitalization only draws randomness for CapRandom
+			form, _ := applyCapitalization(nil, []string{word}, mode, rules)
+			forms[j] = form[0]
+		}
+	}
+	return forms
+}
+
+// collision is two different word pairs that join to the same string
+type collision struct {
+	left1, right1 string
+	left2, right2 string
+}
+
+// joinCollision searches for left1+sep+right1 == left2+sep+right2 with
+// left1 != left2. Then left1+sep is a proper prefix of left2+sep and right1
+// starts with the remainder, which is what the search looks for.
+func joinCollision(left, right []string, sep string) (collision, bool) {
+	heads := make(map[string]string, len(left))
+	for _, word := range left {
+		heads[word+sep] = word
+	}
+
+	tails := slices.Clone(right)
+	slices.Sort(tails)
+	tails = slices.Compact(tails)
+
+	for _, left2 := range slices.Sorted(maps.Values(heads)) {
+		head2 := left2 + sep
+		for k := 1; k < len(head2); k++ {
+			left1, ok := heads[head2[:k]]
+			if !ok {
+				continue
+			}
+
+			rest := head2[k:]
+			start, _ := slices.BinarySearch(tails, rest)
+			for _, right1 := range tails[start:] {
+				if !strings.HasPrefix(right1, rest) {
+					break
+				}
+				right2 := right1[len(rest):]
+				if _, found := slices.BinarySearch(tails, right2); found && right2 != "" {
+					return collision{left1: left1, right1: right1, left2: left2, right2: right2}, true
+				}
+			}
+		}
+	}
+	return collision{}, false
+}
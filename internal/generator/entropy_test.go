@@ -0,0 +1,198 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/greysquirr3l/glyphic/internal/wordlist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupWordsGenerator creates a generator over user wordlists with the given words
+func setupWordsGenerator(t *testing.T, lists ...[]string) *Generator {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	manager, err := wordlist.NewManager(filepath.Join(tmpDir, "cache"))
+	require.NoError(t, err)
+
+	for i, words := range lists {
+		path := filepath.Join(tmpDir, fmt.Sprintf("list%d.txt", i))
+		require.NoError(t, os.WriteFile(path, []byte(strings.Join(words, "\n")), 0600))
+		require.NoError(t, manager.AddUserWordlist(path, fmt.Sprintf("list%d", i)))
+	}
+
+	return New(manager, wordlist.NewExclusionList(false))
+}
+
+func TestEntropyBreakdown(t *testing.T) {
+	// Lists of 2, 4 and 8 three-letter words carry 1, 2 and 3 bits per word
+	gen := setupSizedGenerator(t, 2, 4, 8)
+
+	tests := []struct {
+		name           string
+		opts           Options
+		words          float64
+		capitalization float64
+		digits         float64
+		specials       float64
+	}{
+		{
+			name:  "fixed capitalization adds nothing",
+			opts:  Options{WordCount: 3, MinWordlists: 3, Capitalization: CapFirst},
+			words: 6,
+		},
+		{
+			name:           "random capitalization adds a bit per letter",
+			opts:           Options{WordCount: 3, MinWordlists: 3, Capitalization: CapRandom},
+			words:          6,
+			capitalization: 9,
+		},
+		{
+			name:   "suffix",
+			opts:   Options{WordCount: 3, MinWordlists: 3, AddNumbers: true, NumberCount: 2, AddSpecial: true, SpecialCount: 3},
+			words:  6,
+			digits: 2 * math.Log2(10),
+			// Specials overlapping the separator are still a fixed-length suffix
+			specials: 3 * math.Log2(24),
+		},
+		{
+			name:  "custom separator adds nothing",
+			opts:  Options{WordCount: 4, MinWordlists: 2, Separator: SepCustom, CustomSep: "+"},
+			words: 4 * (1.0 + 2 + 3) / 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := gen.EntropyBreakdown(tt.opts)
+			require.NoError(t, err)
+
+			assert.True(t, b.Exact)
+			assert.InDelta(t, tt.words, b.Words, 1e-9)
+			assert.InDelta(t, tt.capitalization, b.Capitalization, 1e-9)
+			assert.InDelta(t, tt.digits, b.Digits, 1e-9)
+			assert.InDelta(t, tt.specials, b.Specials, 1e-9)
+			assert.InDelta(t, tt.words+tt.capitalization+tt.digits+tt.specials, b.Total, 1e-9)
+			assert.Empty(t, b.Warnings)
+
+			total, err := gen.EstimateEntropy(tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, b.Total, total)
+		})
+	}
+}
+
+func TestEntropyBreakdownSlots(t *testing.T) {
+	gen := setupSizedGenerator(t, 2, 4, 8)
+
+	b, err := gen.EntropyBreakdown(Options{WordCount: 5, MinWordlists: 2, WeightBySize: true})
+	require.NoError(t, err)
+	require.Len(t, b.Slots, 5)
+
+	for i, slot := range b.Slots {
+		assert.Equal(t, i%2, slot.Position)
+		require.Len(t, slot.Pools, 3)
+
+		var p float64
+		for _, pool := range slot.Pools {
+			p += pool.Probability
+		}
+		assert.InDelta(t, 1, p, 1e-9)
+	}
+	assert.Equal(t, b.Slots[0], b.Slots[2])
+	assert.Equal(t, b.Slots[1], b.Slots[3])
+}
+
+func TestEntropyBreakdownWeak(t *testing.T) {
+	// 512 words carry 9 bits each
+	gen := setupSizedGenerator(t, 512)
+
+	tests := []struct {
+		name string
+		opts Options
+		want bool
+	}{
+		{"five words", Options{WordCount: 5, MinWordlists: 1}, true},
+		{"six words", Options{WordCount: 6, MinWordlists: 1}, false},
+		{"five words and digits", Options{WordCount: 5, MinWordlists: 1, AddNumbers: true, NumberCount: 2}, false},
+		{"charset", Options{Strategy: StrategyCharset, Length: 8, Classes: ClassLower}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := gen.EntropyBreakdown(tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, b.Weak(), "%.1f bits", b.Total)
+		})
+	}
+}
+
+func TestEntropyBreakdownJoinWarnings(t *testing.T) {
+	// "tab"+"lemon" and "table"+"mon" both give "tablemon"
+	gen := setupWordsGenerator(t, []string{"tab", "table"}, []string{"lemon", "mon"})
+
+	tests := []struct {
+		name string
+		opts Options
+		warn bool
+	}{
+		{"no separator", Options{Separator: SepNone}, true},
+		{"no separator all caps", Options{Separator: SepNone, Capitalization: CapAll}, true},
+		{"no separator random caps", Options{Separator: SepNone, Capitalization: CapRandom}, true},
+		{"first letter marks boundaries", Options{Separator: SepNone, Capitalization: CapFirst}, false},
+		{"alternating case marks boundaries", Options{Separator: SepNone, Capitalization: CapAlternating}, false},
+		{"dash", Options{Separator: SepDash}, false},
+		{"custom letter separator", Options{Separator: SepCustom, CustomSep: "x"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.WordCount = 3
+			tt.opts.MinWordlists = 2
+
+			b, err := gen.EntropyBreakdown(tt.opts)
+			require.NoError(t, err)
+			if !tt.warn {
+				assert.Empty(t, b.Warnings)
+				return
+			}
+			require.NotEmpty(t, b.Warnings)
+			assert.Contains(t, strings.ToLower(b.Warnings[0]), `"tablemon"`)
+		})
+	}
+}
+
+func TestJoinCollision(t *testing.T) {
+	tests := []struct {
+		name        string
+		left, right []string
+		sep         string
+		want        string
+	}{
+		{"prefix pair", []string{"tab", "table"}, []string{"lemon", "mon"}, "", "tablemon"},
+		{"no prefix", []string{"tab", "cat"}, []string{"lemon", "mon"}, "", ""},
+		{"dash", []string{"tab", "table"}, []string{"lemon", "mon"}, "-", ""},
+		{"letter separator", []string{"ab", "abxcd"}, []string{"cdxef", "ef"}, "x", "abxcdxef"},
+		{"no remainder", []string{"tab", "table"}, []string{"le"}, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, ok := joinCollision(tt.left, tt.right, tt.sep)
+			if tt.want == "" {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.NotEqual(t, c.left1, c.left2)
+			assert.Equal(t, tt.want, c.left1+tt.sep+c.right1)
+			assert.Equal(t, tt.want, c.left2+tt.sep+c.right2)
+		})
+	}
+}
@@ -3,9 +3,13 @@ package generator
 import (
 	"errors"
 	"fmt"
-	"math"
+	"maps"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/greysquirr3l/glyphic/internal/security"
 	"github.com/greysquirr3l/glyphic/internal/wordlist"
@@ -44,6 +48,42 @@ type Options struct {
 	Separator      SeparatorMode      // Word separator style
 	CustomSep      string             // Custom separator if SepCustom
 	MinWordlists   int                // Minimum different wordlists to use (default 3)
+	WeightBySize   bool               // Pick larger wordlists proportionally more often
+	Policy         *Policy            // Site password rules to satisfy, nil for none
+
+	// TargetEntropyBits, when positive, lets the generator choose the word count
+	// and, if enabled, the digit and special character counts to reach it. Other
+	// strategies choose their length or token bits instead.
+	TargetEntropyBits float64
+
+	Strategy      Strategy      // How passwords are built (default diceware)
+	Length        int           // Characters of a charset password (8-128)
+	Classes       CharClass     // Character classes of a charset password, each used at least once
+	NoAmbiguous   bool          // Leave AmbiguousChars out of charset passwords
+	PINLength     int           // Digits of a PIN (4-12)
+	NoRepeat      bool          // Forbid a PIN digit directly followed by itself
+	NoSequence    bool          // Forbid three ascending or descending PIN digits in a row
+	TokenBits     int           // Random bits of a token (64-1024, multiple of 8)
+	TokenEncoding TokenEncoding // Text encoding of a token
+	Syllables     int           // Syllables per pronounceable word (1-6)
+
+	// Template, when set, shapes a diceware passphrase like a sentence, such as
+	// "{adj} {noun} {verb} {adv}": each {tag} slot is drawn from the words with
+	// that part-of-speech tag and the rest is copied. WordCount, MinWordlists,
+	// WeightBySize and the separator are ignored.
+	Template string
+
+	// Substitutions, when set, replaces letters of the words after
+	// capitalization, such as "e" with "3". Each letter in the table is
+	// substituted at random unless SubstituteAll is set. Placement moves the
+	// digits and special characters into the passphrase.
+	Substitutions *Substitutions
+	SubstituteAll bool      // Replace every letter in the table with its first substitute
+	Placement     Placement // Where digits and special characters go (default PlaceEnd)
+
+	// Language selects the case rules of the words, such as "tr" for the
+	// Turkish dotted and dotless i. Empty uses the default Unicode mapping.
+	Language string
 }
 
 // DefaultOptions provides secure default settings
@@ -56,24 +96,111 @@ var DefaultOptions = Options{
 	SpecialCount:   1,
 	Separator:      SepDash,
 	MinWordlists:   3,
+	Length:         20,
+	Classes:        ClassAll,
+	PINLength:      6,
+	TokenBits:      128,
+	TokenEncoding:  EncodingHex,
+	Syllables:      2,
 }
 
 // SpecialChars is the set of allowed special characters
 var SpecialChars = []rune{'!', '@', '#', '$', '%', '^', '&', '*', '(', ')', '-', '_', '=', '+', '[', ']', '{', '}', '|', ';', ':', ',', '.', '?'}
 
 var (
-	ErrInvalidWordCount    = errors.New("word count must be between 3 and 12")
-	ErrInvalidNumberCount  = errors.New("number count must be between 1 and 4")
-	ErrInvalidSpecialCount = errors.New("special character count must be between 1 and 4")
-	ErrInvalidMinWordlists = errors.New("minimum wordlists must be at least 1")
-	ErrNotEnoughWordlists  = errors.New("not enough different wordlists available")
-	ErrNoWordsAvailable    = errors.New("no words available after exclusion filtering")
+	ErrInvalidWordCount      = errors.New("word count must be between 3 and 12")
+	ErrInvalidNumberCount    = errors.New("number count must be between 1 and 4")
+	ErrInvalidSpecialCount   = errors.New("special character count must be between 1 and 4")
+	ErrInvalidMinWordlists   = errors.New("minimum wordlists must be at least 1")
+	ErrNotEnoughWordlists    = errors.New("not enough different wordlists available")
+	ErrNoWordsAvailable      = errors.New("no words available after exclusion filtering")
+	ErrUnknownCapitalization = errors.New("unknown capitalization mode")
+	ErrUnknownSeparator      = errors.New("unknown separator mode")
+	ErrInvalidTargetEntropy  = errors.New("target entropy must be between 0 and 512 bits")
+	ErrTargetUnreachable     = errors.New("target entropy cannot be reached")
 )
 
+// capitalizationNames maps capitalization modes to their CLI names
+var capitalizationNames = map[CapitalizationMode]string{
+	CapNone:        "none",
+	CapFirst:       "first",
+	CapRandom:      "random",
+	CapAll:         "all",
+	CapAlternating: "alternating",
+}
+
+// separatorNames maps separator modes to their CLI names
+var separatorNames = map[SeparatorMode]string{
+	SepNone:       "none",
+	SepSpace:      "space",
+	SepDash:       "dash",
+	SepUnderscore: "underscore",
+	SepCustom:     "custom",
+}
+
+// String returns the CLI name of the capitalization mode
+func (m CapitalizationMode) String() string {
+	if name, ok := capitalizationNames[m]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// String returns the CLI name of the separator mode
+func (m SeparatorMode) String() string {
+	if name, ok := separatorNames[m]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ParseCapitalization returns the capitalization mode with the given name
+func ParseCapitalization(name string) (CapitalizationMode, error) {
+	for mode, n := range capitalizationNames {
+		if strings.EqualFold(n, name) {
+			return mode, nil
+		}
+	}
+	return CapNone, fmt.Errorf("%w: %q", ErrUnknownCapitalization, name)
+}
+
+// ParseSeparator returns the separator mode with the given name
+func ParseSeparator(name string) (SeparatorMode, error) {
+	for mode, n := range separatorNames {
+		if strings.EqualFold(n, name) {
+			return mode, nil
+		}
+	}
+	return SepNone, fmt.Errorf("%w: %q", ErrUnknownSeparator, name)
+}
+
 // Generator generates passwords using wordlists
 type Generator struct {
 	manager    *wordlist.Manager
 	exclusions *wordlist.ExclusionList
+
+	poolMu sync.Mutex                    // serialises pool rebuilds
+	pools  atomic.Pointer[filteredPools] // read without locking on the hot path
+
+	target atomic.Pointer[resolvedTarget] // last target entropy resolution
+	rand   security.RandomSource          // source of every random choice
+}
+
+// filteredPools holds exclusion-filtered words per wordlist for one version of
+// the loaded lists and exclusions. It is never modified once published.
+type filteredPools struct {
+	listsVersion      uint64
+	exclusionsVersion uint64
+	words             map[poolKey][]string
+}
+
+// poolKey identifies a pool by its wordlist, or by a part-of-speech tag
+// spanning every list, and any policy word filter applied on top of the
+// exclusions
+type poolKey struct {
+	list   *wordlist.Wordlist
+	tag    string
+	filter string
 }
 
 // New creates a new password generator
@@ -81,12 +208,99 @@ func New(manager *wordlist.Manager, exclusions *wordlist.ExclusionList) *Generat
 	return &Generator{
 		manager:    manager,
 		exclusions: exclusions,
+		rand:       security.Crypto,
+	}
+}
+
+// SetRandomSource replaces the crypto/rand source, for example with a seeded
+// source in golden tests. A nil source restores crypto/rand. It must be called
+// before generating.
+func (g *Generator) SetRandomSource(src security.RandomSource) {
+	if src == nil {
+		src = security.Crypto
 	}
+	g.rand = src
+}
+
+// matches reports whether the pools were built for the given versions
+func (p *filteredPools) matches(listsVersion, exclusionsVersion uint64) bool {
+	return p != nil && p.listsVersion == listsVersion && p.exclusionsVersion == exclusionsVersion
+}
+
+// lookup returns the pool for key if it is current
+func (p *filteredPools) lookup(key poolKey, listsVersion, exclusionsVersion uint64) ([]string, bool) {
+	if !p.matches(listsVersion, exclusionsVersion) {
+		return nil, false
+	}
+	words, ok := p.words[key]
+	return words, ok
+}
+
+// pool returns the exclusion-filtered words of list, filtering it only the first
+// time it is used after the loaded lists or exclusions change. The returned
+// slice is shared and must not be modified.
+func (g *Generator) pool(list *wordlist.Wordlist) []string {
+	return g.filteredPool(poolKey{list: list}, nil)
+}
+
+// filteredPool returns the pool for key, building it from the exclusion-filtered
+// words with refine, if given, when it is missing or stale
+func (g *Generator) filteredPool(key poolKey, refine func([]string) []string) []string {
+	listsVersion, exclusionsVersion := g.manager.Version(), g.exclusions.Version()
+	if words, ok := g.pools.Load().lookup(key, listsVersion, exclusionsVersion); ok {
+		return words
+	}
+
+	g.poolMu.Lock()
+	defer g.poolMu.Unlock()
+
+	// Another goroutine may have published the pool while we waited
+	current := g.pools.Load()
+	if words, ok := current.lookup(key, listsVersion, exclusionsVersion); ok {
+		return words
+	}
+
+	next := &filteredPools{
+		listsVersion:      listsVersion,
+		exclusionsVersion: exclusionsVersion,
+		words:             make(map[poolKey][]string),
+	}
+	if current.matches(listsVersion, exclusionsVersion) {
+		maps.Copy(next.words, current.words)
+	}
+
+	var words []string
+	if key.list != nil {
+		words = g.exclusions.Filter(key.list.Words)
+	} else {
+		words = g.exclusions.Filter(g.manager.TaggedWords(key.tag))
+	}
+	if refine != nil {
+		words = refine(words)
+	}
+	next.words[key] = words
+	g.pools.Store(next)
+
+	return words
 }
 
 // Validate checks if options are valid
 func (o *Options) Validate() error {
-	if o.WordCount < 3 || o.WordCount > 12 {
+	if o.TargetEntropyBits < 0 || o.TargetEntropyBits > MaxTargetEntropyBits {
+		return ErrInvalidTargetEntropy
+	}
+	if o.Strategy != StrategyDiceware {
+		return o.validateStrategy()
+	}
+	if o.Template != "" {
+		return o.validateTemplate()
+	}
+	if o.TargetEntropyBits > 0 {
+		// The word count is chosen by ResolveTarget and may exceed 12
+		if o.WordCount < 3 || o.WordCount > MaxTargetWordCount {
+			return fmt.Errorf("%w (up to %d with a target entropy)", ErrInvalidWordCount, MaxTargetWordCount)
+		}
+	} else if o.WordCount < 3 || o.WordCount > 12 {
 		return ErrInvalidWordCount
 	}
 	if o.AddNumbers && (o.NumberCount < 1 || o.NumberCount > 4) {
@@ -98,28 +312,67 @@ func (o *Options) Validate() error {
 	if o.MinWordlists < 1 {
 		return ErrInvalidMinWordlists
 	}
+	if err := o.validateTransforms(); err != nil {
+		return err
+	}
+	if o.Policy != nil {
+		return o.Policy.Validate()
+	}
 	return nil
 }
 
+// listCount returns how many distinct wordlists a password draws from
+func (o *Options) listCount() int {
+	return min(o.MinWordlists, o.WordCount)
+}
+
+// specialChars returns the special characters a password may end with
+func (o *Options) specialChars() []rune {
+	if o.Policy != nil {
+		return o.Policy.specials()
+	}
+	return SpecialChars
+}
+
+// selectOptions maps the options onto wordlist selection options
+func (o *Options) selectOptions() wordlist.SelectOptions {
+	return wordlist.SelectOptions{WeightBySize: o.WeightBySize}
+}
+
 // Generate creates a single password with the given options
 func (g *Generator) Generate(opts Options) (string, error) {
+	return g.generate(g.rand, opts)
+}
+
+// generate creates a password drawing every random choice from src
+func (g *Generator) generate(src security.RandomSource, opts Options) (string, error) {
 	if err := opts.Validate(); err != nil {
 		return "", fmt.Errorf("invalid options: %w", err)
 	}
 
-	// Select random wordlists (at least MinWordlists different ones)
-	numLists := opts.MinWordlists
-	if opts.WordCount < numLists {
-		numLists = opts.WordCount
+	if opts.TargetEntropyBits > 0 {
+		resolved, err := g.ResolveTarget(opts)
+		if err != nil {
+			return "", err
+		}
+		opts = resolved
 	}
 
-	lists, err := g.manager.SelectRandomLists(numLists)
-	if err != nil {
-		return "", fmt.Errorf("failed to select wordlists: %w", err)
+	if !opts.UsesWordlists() {
+		return g.generateStrategy(src, opts)
 	}
 
-	if len(lists) < numLists {
-		return "", fmt.Errorf("%w: need %d, got %d", ErrNotEnoughWordlists, numLists, len(lists))
+	if opts.Template != "" {
+		return g.generateTemplate(src, opts)
+	}
+
+	if opts.Policy != nil {
+		return g.generateWithPolicy(src, opts)
+	}
+
+	lists, err := g.selectLists(src, opts)
+	if err != nil {
+		return "", err
 	}
 
 	// Select words from different wordlists
@@ -130,13 +383,13 @@ func (g *Generator) Generate(opts Options) (string, error) {
 		list := lists[listIdx]
 
 		// Filter words by exclusion list
-		availableWords := g.exclusions.Filter(list.Words)
+		availableWords := g.pool(list)
 		if len(availableWords) == 0 {
 			return "", fmt.Errorf("%w: wordlist %s has no available words after filtering", ErrNoWordsAvailable, list.Source.ID)
 		}
 
 		// Select random word
-		wordIdx, err := security.SecureRandomIndex(len(availableWords))
+		wordIdx, err := security.RandomIndex(src, len(availableWords))
 		if err != nil {
 			return "", fmt.Errorf("failed to select random word: %w", err)
 		}
@@ -145,37 +398,55 @@ func (g *Generator) Generate(opts Options) (string, error) {
 	}
 
 	// Apply capitalization
-	words, err = applyCapitalization(words, opts.Capitalization)
+	words, err = applyCapitalization(src, words, opts.Capitalization, opts.caseRules())
 	if err != nil {
 		return "", fmt.Errorf("failed to apply capitalization: %w", err)
 	}
 
-	// Join words with separator
+	// Apply letter substitutions
+	words, err = substitute(src, words, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to apply substitutions: %w", err)
+	}
+
+	// Join words with separator, keeping each separator with the word after it
 	separator := getSeparator(opts.Separator, opts.CustomSep)
-	password := strings.Join(words, separator)
+	for i := 1; i < len(words); i++ {
+		words[i] = separator + words[i]
+	}
 
-	// Add numbers if requested
-	if opts.AddNumbers {
-		numbers, err := generateRandomNumbers(opts.NumberCount)
-		if err != nil {
-			return "", fmt.Errorf("failed to generate numbers: %w", err)
-		}
-		password += numbers
+	// Add numbers and special characters if requested
+	return placeExtras(src, words, opts)
+}
+
+// selectLists selects the random wordlists (at least MinWordlists different ones)
+// that a password draws its words from, using src
+func (g *Generator) selectLists(src security.RandomSource, opts Options) ([]*wordlist.Wordlist, error) {
+	numLists := opts.listCount()
+	selectOpts := opts.selectOptions()
+	selectOpts.Rand = src
+	lists, err := g.manager.SelectLists(numLists, selectOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select wordlists: %w", err)
 	}
 
-	// Add special characters if requested
-	if opts.AddSpecial {
-		specials, err := generateRandomSpecialChars(opts.SpecialCount)
-		if err != nil {
-			return "", fmt.Errorf("failed to generate special characters: %w", err)
-		}
-		password += specials
+	if len(lists) < numLists {
+		return nil, fmt.Errorf("%w: need %d, got %d", ErrNotEnoughWordlists, numLists, len(lists))
 	}
+	return lists, nil
+}
 
-	return password, nil
+// AvailableLists returns how many wordlists passwords with opts can draw
+// from, counting only lists with a dice index for physical dice
+func (g *Generator) AvailableLists(opts Options, dice bool) int {
+	if dice {
+		return len(g.manager.DiceLists())
+	}
+	return g.manager.SelectableCount(opts.selectOptions())
 }
 
-// GenerateMultiple creates multiple passwords
+// GenerateMultiple creates multiple passwords in memory.
+// Use Stream or WriteBatch for large batches.
 func (g *Generator) GenerateMultiple(count int, opts Options) ([]string, error) {
 	if count < 1 {
 		return nil, errors.New("count must be at least 1")
@@ -194,43 +465,45 @@ func (g *Generator) GenerateMultiple(count int, opts Options) ([]string, error)
 }
 
 // applyCapitalization applies the specified capitalization mode to words
-func applyCapitalization(words []string, mode CapitalizationMode) ([]string, error) {
+// under the case rules of their language, drawing CapRandom coin flips from src
+func applyCapitalization(src security.RandomSource, words []string, mode CapitalizationMode, rules unicode.SpecialCase) ([]string, error) {
 	result := make([]string, len(words))
 
 	for i, word := range words {
 		switch mode {
 		case CapNone:
-			result[i] = strings.ToLower(word)
+			result[i] = strings.ToLowerSpecial(rules, word)
 
 		case CapFirst:
 			if len(word) == 0 {
 				result[i] = word
 			} else {
-				result[i] = strings.ToUpper(word[:1]) + strings.ToLower(word[1:])
+				r, size := utf8.DecodeRuneInString(word)
+				result[i] = string(rules.ToTitle(r)) + strings.ToLowerSpecial(rules, word[size:])
 			}
 
 		case CapRandom:
-			runes := []rune(strings.ToLower(word))
+			runes := []rune(strings.ToLowerSpecial(rules, word))
 			for j := range runes {
 				// 50% chance to capitalize each character
-				shouldCap, err := security.SecureRandomIndex(2)
+				shouldCap, err := security.RandomIndex(src, 2)
 				if err != nil {
 					return nil, err
 				}
 				if shouldCap == 1 {
-					runes[j] = []rune(strings.ToUpper(string(runes[j])))[0]
+					runes[j] = rules.ToUpper(runes[j])
 				}
 			}
 			result[i] = string(runes)
 
 		case CapAll:
-			result[i] = strings.ToUpper(word)
+			result[i] = strings.ToUpperSpecial(rules, word)
 
 		case CapAlternating:
 			if i%2 == 0 {
-				result[i] = strings.ToUpper(word)
+				result[i] = strings.ToUpperSpecial(rules, word)
 			} else {
-				result[i] = strings.ToLower(word)
+				result[i] = strings.ToLowerSpecial(rules, word)
 			}
 
 		default:
@@ -241,6 +514,11 @@ func applyCapitalization(words []string, mode CapitalizationMode) ([]string, err
 	return result, nil
 }
 
+// caseRules returns the case mapping of the words' language
+func (o *Options) caseRules() unicode.SpecialCase {
+	return wordlist.CaseRules(o.Language)
+}
+
 // getSeparator returns the appropriate separator string
 func getSeparator(mode SeparatorMode, custom string) string {
 	switch mode {
@@ -259,11 +537,11 @@ func getSeparator(mode SeparatorMode, custom string) string {
 	}
 }
 
-// generateRandomNumbers generates N random digits
-func generateRandomNumbers(count int) (string, error) {
+// generateRandomNumbers generates N random digits from src
+func generateRandomNumbers(src security.RandomSource, count int) (string, error) {
 	var sb strings.Builder
 	for range count {
-		digit, err := security.SecureRandomIndex(10)
+		digit, err := security.RandomIndex(src, 10)
 		if err != nil {
 			return "", err
 		}
@@ -272,11 +550,11 @@ func generateRandomNumbers(count int) (string, error) {
 	return sb.String(), nil
 }
 
-// generateRandomSpecialChars generates N random special characters
-func generateRandomSpecialChars(count int) (string, error) {
+// generateRandomSpecialChars generates N random special characters from src
+func generateRandomSpecialChars(src security.RandomSource, count int) (string, error) {
 	var sb strings.Builder
 	for range count {
-		idx, err := security.SecureRandomIndex(len(SpecialChars))
+		idx, err := security.RandomIndex(src, len(SpecialChars))
 		if err != nil {
 			return "", err
 		}
@@ -284,44 +562,3 @@ func generateRandomSpecialChars(count int) (string, error) {
 	}
 	return sb.String(), nil
 }
-
-// EstimateEntropy calculates the approximate entropy bits for given options
-func (g *Generator) EstimateEntropy(opts Options) (float64, error) {
-	if err := opts.Validate(); err != nil {
-		return 0, err
-	}
-
-	// Get average wordlist size
-	lists, err := g.manager.SelectRandomLists(opts.MinWordlists)
-	if err != nil {
-		return 0, err
-	}
-
-	var totalWords int
-	for _, list := range lists {
-		availableWords := g.exclusions.Filter(list.Words)
-		totalWords += len(availableWords)
-	}
-
-	if totalWords == 0 {
-		return 0, ErrNoWordsAvailable
-	}
-
-	avgWordlistSize := float64(totalWords) / float64(len(lists))
-
-	// Calculate entropy: log2(possibilities)
-	// For words: word_count * log2(avg_wordlist_size)
-	entropy := float64(opts.WordCount) * math.Log2(avgWordlistSize)
-
-	// Add entropy for numbers
-	if opts.AddNumbers {
-		entropy += float64(opts.NumberCount) * math.Log2(10)
-	}
-
-	// Add entropy for special characters
-	if opts.AddSpecial {
-		entropy += float64(opts.SpecialCount) * math.Log2(float64(len(SpecialChars)))
-	}
-
-	return entropy, nil
-}
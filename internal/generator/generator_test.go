@@ -5,8 +5,11 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"unicode"
 
+	"github.com/greysquirr3l/glyphic/internal/security"
 	"github.com/greysquirr3l/glyphic/internal/wordlist"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -106,6 +109,33 @@ func TestOptionsValidate(t *testing.T) {
 			},
 			wantErr: ErrInvalidMinWordlists,
 		},
+		{
+			name: "target entropy relaxes word count bound",
+			opts: Options{
+				WordCount:         20,
+				MinWordlists:      1,
+				TargetEntropyBits: 200,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "target entropy negative",
+			opts: Options{
+				WordCount:         6,
+				MinWordlists:      1,
+				TargetEntropyBits: -1,
+			},
+			wantErr: ErrInvalidTargetEntropy,
+		},
+		{
+			name: "target entropy too high",
+			opts: Options{
+				WordCount:         6,
+				MinWordlists:      1,
+				TargetEntropyBits: 1024,
+			},
+			wantErr: ErrInvalidTargetEntropy,
+		},
 	}
 
 	for _, tt := range tests {
@@ -403,6 +433,82 @@ func TestEstimateEntropy(t *testing.T) {
 	}
 }
 
+// setupSizedGenerator creates a generator over user wordlists with the given word counts
+func setupSizedGenerator(t *testing.T, sizes ...int) *Generator {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	manager, err := wordlist.NewManager(filepath.Join(tmpDir, "cache"))
+	require.NoError(t, err)
+
+	for i, size := range sizes {
+		words := make([]string, size)
+		for j := range words {
+			words[j] = fmt.Sprintf("%c%c%c", 'a'+i, 'a'+j/26, 'a'+j%26)
+		}
+		path := filepath.Join(tmpDir, fmt.Sprintf("list%d.txt", i))
+		require.NoError(t, os.WriteFile(path, []byte(strings.Join(words, "\n")), 0600))
+		require.NoError(t, manager.AddUserWordlist(path, fmt.Sprintf("list%d", i)))
+	}
+
+	return New(manager, wordlist.NewExclusionList(false))
+}
+
+func TestEstimateEntropySelectionDistribution(t *testing.T) {
+	// Lists of 2, 4 and 8 words carry 1, 2 and 3 bits per word
+	gen := setupSizedGenerator(t, 2, 4, 8)
+
+	tests := []struct {
+		name string
+		opts Options
+		want float64
+	}{
+		{
+			name: "uniform single list averages bits",
+			opts: Options{WordCount: 3, MinWordlists: 1},
+			want: 3 * (1.0 + 2 + 3) / 3,
+		},
+		{
+			name: "weighted single list favours large list",
+			opts: Options{WordCount: 3, MinWordlists: 1, WeightBySize: true},
+			want: 3 * (2*1.0 + 4*2 + 8*3) / 14,
+		},
+		{
+			name: "all lists used",
+			opts: Options{WordCount: 3, MinWordlists: 3},
+			want: 6,
+		},
+		{
+			name: "all lists used weighted",
+			opts: Options{WordCount: 6, MinWordlists: 3, WeightBySize: true},
+			want: 12,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entropy, err := gen.EstimateEntropy(tt.opts)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, entropy, 1e-9)
+		})
+	}
+}
+
+func TestGenerateWeightBySize(t *testing.T) {
+	gen := setupSizedGenerator(t, 2, 4, 8)
+	opts := Options{WordCount: 3, MinWordlists: 3, WeightBySize: true, Separator: SepDash}
+
+	password, err := gen.Generate(opts)
+	require.NoError(t, err)
+
+	// Every list contributes exactly one word, identified by its first letter
+	var firsts []byte
+	for _, word := range strings.Split(password, "-") {
+		firsts = append(firsts, word[0])
+	}
+	assert.ElementsMatch(t, []byte("abc"), firsts)
+}
+
 func TestApplyCapitalization(t *testing.T) {
 	words := []string{"hello", "world", "test"}
 
@@ -451,17 +557,43 @@ func TestApplyCapitalization(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := applyCapitalization(words, tt.mode)
+			result, err := applyCapitalization(nil, words, tt.mode, nil)
 			assert.NoError(t, err)
 			tt.check(t, result)
 		})
 	}
 }
 
+func TestApplyCapitalizationUnicode(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+		mode  CapitalizationMode
+		rules unicode.SpecialCase
+		want  []string
+	}{
+		{name: "first rune", words: []string{"élan", "über", "ǆungla"}, mode: CapFirst, want: []string{"Élan", "Über", "ǅungla"}},
+		{name: "all keeps sharp s", words: []string{"straße", "año"}, mode: CapAll, want: []string{"STRAßE", "AÑO"}},
+		{name: "uncased script", words: []string{"さくら"}, mode: CapFirst, want: []string{"さくら"}},
+		{name: "default i", words: []string{"istanbul", "ılık"}, mode: CapFirst, want: []string{"Istanbul", "Ilık"}},
+		{name: "turkish i", words: []string{"istanbul", "ılık"}, mode: CapFirst, rules: unicode.TurkishCase, want: []string{"İstanbul", "Ilık"}},
+		{name: "turkish all", words: []string{"kişi"}, mode: CapAll, rules: unicode.TurkishCase, want: []string{"KİŞİ"}},
+		{name: "turkish lower", words: []string{"IŞIK"}, mode: CapNone, rules: unicode.TurkishCase, want: []string{"ışık"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := applyCapitalization(nil, tt.words, tt.mode, tt.rules)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result)
+		})
+	}
+}
+
 func TestGenerateRandomNumbers(t *testing.T) {
 	for count := 1; count <= 4; count++ {
 		t.Run(fmt.Sprintf("count=%d", count), func(t *testing.T) {
-			numbers, err := generateRandomNumbers(count)
+			numbers, err := generateRandomNumbers(nil, count)
 			assert.NoError(t, err)
 			assert.Len(t, numbers, count)
 
@@ -476,7 +608,7 @@ func TestGenerateRandomNumbers(t *testing.T) {
 func TestGenerateRandomSpecialChars(t *testing.T) {
 	for count := 1; count <= 4; count++ {
 		t.Run(fmt.Sprintf("count=%d", count), func(t *testing.T) {
-			specials, err := generateRandomSpecialChars(count)
+			specials, err := generateRandomSpecialChars(nil, count)
 			assert.NoError(t, err)
 			assert.Len(t, specials, count)
 
@@ -494,3 +626,180 @@ func TestGenerateRandomSpecialChars(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCapitalization(t *testing.T) {
+	for mode, name := range capitalizationNames {
+		got, err := ParseCapitalization(name)
+		assert.NoError(t, err)
+		assert.Equal(t, mode, got)
+		assert.Equal(t, name, mode.String())
+	}
+
+	_, err := ParseCapitalization("shouting")
+	assert.ErrorIs(t, err, ErrUnknownCapitalization)
+}
+
+func TestParseSeparator(t *testing.T) {
+	for mode, name := range separatorNames {
+		got, err := ParseSeparator(name)
+		assert.NoError(t, err)
+		assert.Equal(t, mode, got)
+		assert.Equal(t, name, mode.String())
+	}
+
+	_, err := ParseSeparator("comma")
+	assert.ErrorIs(t, err, ErrUnknownSeparator)
+}
+
+func TestPoolCaching(t *testing.T) {
+	gen := setupTestGenerator(t)
+	lists, err := gen.manager.SelectRandomLists(3)
+	require.NoError(t, err)
+	list := lists[0]
+
+	first := gen.pool(list)
+	require.Len(t, first, 5)
+	assert.Same(t, &first[0], &gen.pool(list)[0], "pool should be reused")
+
+	// Changing exclusions rebuilds the pool without touching the wordlist
+	gen.exclusions.Add(first[0])
+	second := gen.pool(list)
+	assert.Len(t, second, 4)
+	assert.NotContains(t, second, first[0])
+	assert.Len(t, list.Words, 5)
+	assert.Contains(t, list.Words, first[0])
+
+	// Loading another list invalidates the cache too
+	path := filepath.Join(t.TempDir(), "extra.txt")
+	require.NoError(t, os.WriteFile(path, []byte("pear\nplum\n"), 0600))
+	require.NoError(t, gen.manager.AddUserWordlist(path, "extra"))
+	third := gen.pool(list)
+	assert.Equal(t, second, third)
+	assert.NotSame(t, &second[0], &third[0])
+}
+
+func TestGenerateRepeatedlyKeepsWordlistsIntact(t *testing.T) {
+	gen := setupTestGenerator(t)
+	gen.exclusions.Add("apple", "falcon", "kale")
+	opts := Options{WordCount: 6, MinWordlists: 3, Separator: SepDash}
+
+	for range 200 {
+		password, err := gen.Generate(opts)
+		require.NoError(t, err)
+		for _, word := range strings.Split(password, "-") {
+			assert.NotEmpty(t, word)
+			assert.NotContains(t, []string{"apple", "falcon", "kale"}, word)
+		}
+	}
+
+	lists, err := gen.manager.SelectRandomLists(3)
+	require.NoError(t, err)
+	for _, list := range lists {
+		assert.Len(t, list.Words, 5)
+		assert.NotContains(t, list.Words, "")
+	}
+}
+
+func TestGenerateConcurrent(t *testing.T) {
+	gen := setupTestGenerator(t)
+	opts := Options{WordCount: 4, MinWordlists: 3, Separator: SepDash}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := range 8 {
+		wg.Go(func() {
+			for range 100 {
+				if _, err := gen.Generate(opts); err != nil {
+					errs <- err
+					return
+				}
+			}
+			if i == 0 {
+				gen.exclusions.Add("apple")
+			}
+		})
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+}
+
+// setupBenchmarkGenerator uses the embedded wordlists and default exclusions
+func setupBenchmarkGenerator(b *testing.B) (*Generator, Options) {
+	b.Helper()
+
+	manager, err := wordlist.NewManager(b.TempDir())
+	require.NoError(b, err)
+	require.NoError(b, manager.LoadAll())
+
+	lists := len(wordlist.EmbeddedIDs(wordlist.DefaultLanguage))
+	if lists == 0 {
+		b.Skip("no embedded wordlists in slim build")
+	}
+
+	opts := DefaultOptions
+	opts.MinWordlists = lists
+	opts.AddNumbers = true
+	opts.AddSpecial = true
+	return New(manager, wordlist.NewExclusionList(true)), opts
+}
+
+func BenchmarkGenerate(b *testing.B) {
+	gen, opts := setupBenchmarkGenerator(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for b.Loop() {
+		if _, err := gen.Generate(opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGenerateMultiple(b *testing.B) {
+	gen, opts := setupBenchmarkGenerator(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for b.Loop() {
+		if _, err := gen.GenerateMultiple(1000, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestGenerateGolden(t *testing.T) {
+	gen := setupTestGenerator(t)
+	gen.SetRandomSource(security.NewInsecureSeededSource("golden"))
+
+	opts := Options{
+		WordCount:      4,
+		Capitalization: CapRandom,
+		Separator:      SepDash,
+		MinWordlists:   3,
+		AddNumbers:     true,
+		NumberCount:    2,
+		AddSpecial:     true,
+		SpecialCount:   1,
+	}
+
+	var passwords []string
+	for range 3 {
+		password, err := gen.Generate(opts)
+		require.NoError(t, err)
+		passwords = append(passwords, password)
+	}
+	assert.Equal(t, []string{
+		"GooSe-CHERRy-leTtuCE-faLcOn33^",
+		"letTUce-jay-eLdeRBerRy-napA80!",
+		"banana-lEttuCe-hAwk-elDERBerrY77{",
+	}, passwords)
+
+	opts.Policy = &PolicyAWSIAM
+	password, err := gen.Generate(opts)
+	require.NoError(t, err)
+	assert.Equal(t, "okrA-DATe-JAy-MUSTARD27!", password)
+}
@@ -0,0 +1,583 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"math/big"
+	"math/bits"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/greysquirr3l/glyphic/internal/security"
+	"github.com/greysquirr3l/glyphic/internal/wordlist"
+)
+
+// Policy describes site password rules. Generate satisfies them by construction:
+// words that break the word rules are dropped from the pools, word lengths are
+// drawn so the total lands within the length bounds, and suffix characters come
+// only from the allowed symbols. Rules that span word boundaries are checked last.
+type Policy struct {
+	Name          string
+	MinLength     int      // minimum length in characters, 0 for none
+	MaxLength     int      // maximum length in characters, 0 for none
+	RequireUpper  bool     // at least one uppercase letter
+	RequireLower  bool     // at least one lowercase letter
+	RequireDigit  bool     // at least one digit
+	RequireSymbol bool     // at least one symbol (anything but letters and digits)
+	MinClasses    int      // minimum number of the four classes above that must appear
+	Symbols       string   // allowed symbols including space, empty to allow any
+	Forbidden     []string // substrings that must not appear, compared case-insensitively
+	MaxRepeat     int      // longest run of one repeated character, 0 for no limit
+}
+
+var (
+	ErrInvalidPolicy       = errors.New("invalid policy")
+	ErrUnknownPolicy       = errors.New("unknown policy")
+	ErrPolicyUnsatisfiable = errors.New("policy cannot be satisfied with these options")
+	ErrPolicyViolation     = errors.New("password violates policy")
+)
+
+// Character classes counted by Policy.MinClasses
+const (
+	classUpper = 1 << iota
+	classLower
+	classDigit
+	classSymbol
+)
+
+// classNames lists the character classes in display order
+var classNames = []struct {
+	class int
+	name  string
+}{
+	{classUpper, "uppercase"},
+	{classLower, "lowercase"},
+	{classDigit, "digit"},
+	{classSymbol, "symbol"},
+}
+
+// maxPolicyAttempts bounds the redraws for rules that span word boundaries
+const maxPolicyAttempts = 100
+
+// Built-in policy presets
+var (
+	// PolicyAD matches the Active Directory default complexity requirements:
+	// at least 7 characters from three of the four character classes
+	PolicyAD = Policy{Name: "ad", MinLength: 7, MinClasses: 3}
+
+	// PolicyAWSIAM matches the AWS IAM default password policy: 8 to 128
+	// characters from three classes, with symbols limited to the IAM set
+	PolicyAWSIAM = Policy{Name: "aws-iam", MinLength: 8, MaxLength: 128, MinClasses: 3, Symbols: "!@#$%^&*()_+-=[]{}|'"}
+
+	// PolicyPCIDSS matches PCI-DSS v4.0 requirement 8.3.6: at least 12 characters
+	// with both letters and digits. The words always supply the letters.
+	PolicyPCIDSS = Policy{Name: "pci-dss", MinLength: 12, RequireDigit: true}
+)
+
+// policyPresets maps preset names to the built-in policies
+var policyPresets = map[string]*Policy{
+	PolicyAD.Name:     &PolicyAD,
+	PolicyAWSIAM.Name: &PolicyAWSIAM,
+	PolicyPCIDSS.Name: &PolicyPCIDSS,
+}
+
+// PolicyNames returns the sorted names of the built-in policies
+func PolicyNames() []string {
+	return slices.Sorted(maps.Keys(policyPresets))
+}
+
+// LookupPolicy returns a copy of the built-in policy with the given name
+func LookupPolicy(name string) (*Policy, error) {
+	preset, ok := policyPresets[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownPolicy, name)
+	}
+	p := *preset
+	p.Forbidden = slices.Clone(preset.Forbidden)
+	return &p, nil
+}
+
+// Validate checks that the policy rules are consistent
+func (p *Policy) Validate() error {
+	switch {
+	case p.MinLength < 0 || p.MaxLength < 0:
+		return fmt.Errorf("%w: lengths must not be negative", ErrInvalidPolicy)
+	case p.MaxLength > 0 && p.MinLength > p.MaxLength:
+		return fmt.Errorf("%w: minimum length %d exceeds maximum %d", ErrInvalidPolicy, p.MinLength, p.MaxLength)
+	case p.MinClasses < 0 || p.MinClasses > len(classNames):
+		return fmt.Errorf("%w: minimum classes must be between 0 and %d", ErrInvalidPolicy, len(classNames))
+	case p.MaxRepeat < 0:
+		return fmt.Errorf("%w: maximum repeat must not be negative", ErrInvalidPolicy)
+	}
+
+	for _, r := range p.Symbols {
+		if !isSymbol(r) {
+			return fmt.Errorf("%w: allowed symbols contain %q, which is not a symbol", ErrInvalidPolicy, r)
+		}
+	}
+	if slices.Contains(p.Forbidden, "") {
+		return fmt.Errorf("%w: forbidden substrings must not be empty", ErrInvalidPolicy)
+	}
+	return nil
+}
+
+// Check reports whether password satisfies the policy. Errors describe the
+// rule that failed without quoting the password.
+func (p *Policy) Check(password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		return fmt.Errorf("%w: %s needs at least %d characters, got %d", ErrPolicyViolation, p.name(), p.MinLength, length)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		return fmt.Errorf("%w: %s allows at most %d characters, got %d", ErrPolicyViolation, p.name(), p.MaxLength, length)
+	}
+
+	classes := classesOf(password)
+	if missing := p.requiredClasses() &^ classes; missing != 0 {
+		return fmt.Errorf("%w: %s requires %s characters", ErrPolicyViolation, p.name(), describeClasses(missing))
+	}
+	if n := bits.OnesCount(uint(classes)); n < p.MinClasses {
+		return fmt.Errorf("%w: %s requires %d character classes, got %d", ErrPolicyViolation, p.name(), p.MinClasses, n)
+	}
+
+	for _, r := range password {
+		if isSymbol(r) && !p.allowsSymbol(r) {
+			return fmt.Errorf("%w: %s does not allow one of the symbols used", ErrPolicyViolation, p.name())
+		}
+	}
+	if p.containsForbidden(password) {
+		return fmt.Errorf("%w: %s forbids a substring of the password", ErrPolicyViolation, p.name())
+	}
+	if p.MaxRepeat > 0 && longestRun(password) > p.MaxRepeat {
+		return fmt.Errorf("%w: %s allows at most %d repeated characters in a row", ErrPolicyViolation, p.name(), p.MaxRepeat)
+	}
+	return nil
+}
+
+// name returns the policy name for messages
+func (p *Policy) name() string {
+	if p.Name == "" {
+		return "policy"
+	}
+	return "policy " + p.Name
+}
+
+// requiredClasses returns the classes the policy names explicitly
+func (p *Policy) requiredClasses() int {
+	var classes int
+	if p.RequireUpper {
+		classes |= classUpper
+	}
+	if p.RequireLower {
+		classes |= classLower
+	}
+	if p.RequireDigit {
+		classes |= classDigit
+	}
+	if p.RequireSymbol {
+		classes |= classSymbol
+	}
+	return classes
+}
+
+// allowsSymbol reports whether the symbol r may appear
+func (p *Policy) allowsSymbol(r rune) bool {
+	return p.Symbols == "" || strings.ContainsRune(p.Symbols, r)
+}
+
+// specials returns the special characters the policy allows
+func (p *Policy) specials() []rune {
+	var allowed []rune
+	for _, r := range SpecialChars {
+		if p.allowsSymbol(r) {
+			allowed = append(allowed, r)
+		}
+	}
+	return allowed
+}
+
+// containsForbidden reports whether s contains a forbidden substring, ignoring case
+func (p *Policy) containsForbidden(s string) bool {
+	lower := strings.ToLower(s)
+	for _, f := range p.Forbidden {
+		if strings.Contains(lower, strings.ToLower(f)) {
+			return true
+		}
+	}
+	return false
+}
+
+// wordFilter identifies the word rules, so pools can be cached per policy
+func (p *Policy) wordFilter() string {
+	forbidden := make([]string, len(p.Forbidden))
+	for i, f := range p.Forbidden {
+		forbidden[i] = strings.ToLower(f)
+	}
+	slices.Sort(forbidden)
+	return fmt.Sprintf("policy:%d:%q:%q", p.MaxRepeat, p.Symbols, forbidden)
+}
+
+// filterWords returns the words that cannot break the policy on their own,
+// sorted by length
+func (p *Policy) filterWords(words []string) []string {
+	kept := make([]string, 0, len(words))
+	for _, word := range words {
+		if p.containsForbidden(word) || (p.MaxRepeat > 0 && longestRun(word) > p.MaxRepeat) {
+			continue
+		}
+		if strings.ContainsFunc(word, func(r rune) bool { return isSymbol(r) && !p.allowsSymbol(r) }) {
+			continue
+		}
+		kept = append(kept, word)
+	}
+
+	slices.SortStableFunc(kept, func(a, b string) int {
+		return utf8.RuneCountInString(a) - utf8.RuneCountInString(b)
+	})
+	return kept
+}
+
+// guaranteedClasses returns the classes every password generated with opts contains
+func guaranteedClasses(opts Options) int {
+	var classes int
+	switch opts.Capitalization {
+	case CapNone:
+		classes |= classLower
+	case CapAll:
+		classes |= classUpper
+	case CapFirst, CapAlternating:
+		classes |= classUpper | classLower
+	case CapRandom:
+		// Patterns lacking either case are redrawn when a policy is set
+		classes |= classUpper | classLower
+	}
+
+	if opts.AddNumbers {
+		classes |= classDigit
+	}
+	if opts.AddSpecial {
+		classes |= classSymbol
+	}
+	if opts.WordCount > 1 {
+		classes |= classesOf(getSeparator(opts.Separator, opts.CustomSep))
+	}
+	return classes
+}
+
+// compatible explains why opts can never produce a password satisfying the policy
+func (p *Policy) compatible(opts Options) error {
+	if sep := getSeparator(opts.Separator, opts.CustomSep); opts.WordCount > 1 && sep != "" {
+		if strings.ContainsFunc(sep, func(r rune) bool { return isSymbol(r) && !p.allowsSymbol(r) }) {
+			return fmt.Errorf("%w: %s does not allow the separator %q", ErrPolicyUnsatisfiable, p.name(), sep)
+		}
+		if p.containsForbidden(sep) {
+			return fmt.Errorf("%w: %s forbids the separator %q", ErrPolicyUnsatisfiable, p.name(), sep)
+		}
+	}
+
+	if opts.AddSpecial && len(p.specials()) == 0 {
+		return fmt.Errorf("%w: %s allows none of the special characters", ErrPolicyUnsatisfiable, p.name())
+	}
+
+	guaranteed := guaranteedClasses(opts)
+	if missing := p.requiredClasses() &^ guaranteed; missing != 0 {
+		return fmt.Errorf("%w: %s requires %s characters, which these options never add", ErrPolicyUnsatisfiable, p.name(), describeClasses(missing))
+	}
+	if n := bits.OnesCount(uint(guaranteed)); n < p.MinClasses {
+		return fmt.Errorf("%w: %s requires %d character classes but these options only guarantee %d (%s); add numbers or special characters",
+			ErrPolicyUnsatisfiable, p.name(), p.MinClasses, n, describeClasses(guaranteed))
+	}
+	return nil
+}
+
+// lengthBounds returns the total word length allowed once separators and the
+// suffix are accounted for. hi is math.MaxInt without a maximum length.
+func (p *Policy) lengthBounds(opts Options) (lo, hi int) {
+	fixed := (opts.WordCount - 1) * utf8.RuneCountInString(getSeparator(opts.Separator, opts.CustomSep))
+	if opts.AddNumbers {
+		fixed += opts.NumberCount
+	}
+	if opts.AddSpecial {
+		fixed += opts.SpecialCount
+	}
+
+	lo, hi = max(p.MinLength-fixed, 0), math.MaxInt
+	if p.MaxLength > 0 {
+		hi = p.MaxLength - fixed
+	}
+	return lo, hi
+}
+
+// policyPool returns the words of list that satisfy the policy's word rules,
+// sorted by length. The returned slice is shared and must not be modified.
+func (g *Generator) policyPool(list *wordlist.Wordlist, p *Policy) []string {
+	return g.filteredPool(poolKey{list: list, filter: p.wordFilter()}, p.filterWords)
+}
+
+// generateWithPolicy builds a password that satisfies opts.Policy, drawing
+// from src
+func (g *Generator) generateWithPolicy(src security.RandomSource, opts Options) (string, error) {
+	p := opts.Policy
+	if err := p.compatible(opts); err != nil {
+		return "", err
+	}
+
+	lists, err := g.selectLists(src, opts)
+	if err != nil {
+		return "", err
+	}
+
+	pools := make([][]string, opts.WordCount)
+	minWords, maxWords := 0, 0
+	for i := range pools {
+		list := lists[i%len(lists)]
+		pools[i] = g.policyPool(list, p)
+		if len(pools[i]) == 0 {
+			return "", fmt.Errorf("%w: wordlist %s has no words that satisfy %s", ErrNoWordsAvailable, list.Source.ID, p.name())
+		}
+		minWords += utf8.RuneCountInString(pools[i][0])
+		maxWords += utf8.RuneCountInString(pools[i][len(pools[i])-1])
+	}
+
+	lo, hi := p.lengthBounds(opts)
+	separator := getSeparator(opts.Separator, opts.CustomSep)
+	specials := opts.specialChars()
+
+	var lastErr error
+	for range maxPolicyAttempts {
+		var words []string
+		if lo <= minWords && hi >= maxWords {
+			words, err = drawWords(src, pools)
+		} else {
+			var ok bool
+			words, ok, err = drawWordsWithin(src, pools, lo, hi)
+			if err == nil && !ok {
+				return "", fmt.Errorf("%w: %s allows %d to %d characters for %d words, but these wordlists give %d to %d",
+					ErrPolicyUnsatisfiable, p.name(), lo, hi, opts.WordCount, minWords, maxWords)
+			}
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to select random word: %w", err)
+		}
+
+		words, err = applyCapitalization(src, words, opts.Capitalization, opts.caseRules())
+		if err != nil {
+			return "", fmt.Errorf("failed to apply capitalization: %w", err)
+		}
+
+		password := []rune(strings.Join(words, separator))
+		if opts.AddNumbers {
+			if password, err = appendRandom(src, password, []rune("0123456789"), opts.NumberCount, p.MaxRepeat); err != nil {
+				return "", fmt.Errorf("failed to generate numbers: %w", err)
+			}
+		}
+		if opts.AddSpecial {
+			if password, err = appendRandom(src, password, specials, opts.SpecialCount, p.MaxRepeat); err != nil {
+				return "", fmt.Errorf("failed to generate special characters: %w", err)
+			}
+		}
+
+		// Only rules spanning word boundaries or random case can still fail
+		result := string(password)
+		if lastErr = p.Check(result); lastErr == nil {
+			return result, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: no password satisfied %s after %d attempts: %w", ErrPolicyUnsatisfiable, p.name(), maxPolicyAttempts, lastErr)
+}
+
+// drawWords picks one word uniformly from each pool
+func drawWords(src security.RandomSource, pools [][]string) ([]string, error) {
+	words := make([]string, len(pools))
+	for i, pool := range pools {
+		idx, err := security.RandomIndex(src, len(pool))
+		if err != nil {
+			return nil, err
+		}
+		words[i] = pool[idx]
+	}
+	return words, nil
+}
+
+// lengthBucket is the run of words with one length in a length-sorted pool
+type lengthBucket struct {
+	length     int
+	start, end int
+}
+
+// bucketByLength splits a length-sorted pool into runs of equal length
+func bucketByLength(pool []string) []lengthBucket {
+	var buckets []lengthBucket
+	for i, word := range pool {
+		n := utf8.RuneCountInString(word)
+		if len(buckets) > 0 && buckets[len(buckets)-1].length == n {
+			buckets[len(buckets)-1].end = i + 1
+			continue
+		}
+		buckets = append(buckets, lengthBucket{length: n, start: i, end: i + 1})
+	}
+	return buckets
+}
+
+// drawWordsWithin picks one word per length-sorted pool, uniformly among all
+// combinations whose total length lies in [lo, hi]. Each word is drawn with
+// probability proportional to the number of ways the remaining pools can
+// complete it. It reports false when no combination fits.
+func drawWordsWithin(src security.RandomSource, pools [][]string, lo, hi int) ([]string, bool, error) {
+	n := len(pools)
+	buckets := make([][]lengthBucket, n)
+
+	// ways[j][t] counts the combinations of pools j onwards with total length t
+	ways := make([][]*big.Int, n+1)
+	ways[n] = []*big.Int{big.NewInt(1)}
+	for j := n - 1; j >= 0; j-- {
+		buckets[j] = bucketByLength(pools[j])
+		longest := buckets[j][len(buckets[j])-1].length
+
+		ways[j] = make([]*big.Int, len(ways[j+1])+longest)
+		for t := range ways[j] {
+			ways[j][t] = new(big.Int)
+		}
+		for _, b := range buckets[j] {
+			count := big.NewInt(int64(b.end - b.start))
+			for t, w := range ways[j+1] {
+				if w.Sign() != 0 {
+					ways[j][t+b.length].Add(ways[j][t+b.length], new(big.Int).Mul(w, count))
+				}
+			}
+		}
+	}
+
+	words := make([]string, n)
+	used := 0
+	for j := range n {
+		weights := make([]*big.Int, len(buckets[j]))
+		total := new(big.Int)
+		for k, b := range buckets[j] {
+			weights[k] = countWithin(ways[j+1], lo-used-b.length, hi-used-b.length)
+			weights[k].Mul(weights[k], big.NewInt(int64(b.end-b.start)))
+			total.Add(total, weights[k])
+		}
+		if total.Sign() == 0 {
+			return nil, false, nil
+		}
+
+		r, err := security.RandomBigInt(src, total)
+		if err != nil {
+			return nil, false, err
+		}
+		k := 0
+		for ; r.Cmp(weights[k]) >= 0; k++ {
+			r.Sub(r, weights[k])
+		}
+
+		b := buckets[j][k]
+		idx, err := security.RandomIndex(src, b.end-b.start)
+		if err != nil {
+			return nil, false, err
+		}
+		words[j] = pools[j][b.start+idx]
+		used += b.length
+	}
+	return words, true, nil
+}
+
+// countWithin sums ways[t] for t in [lo, hi]
+func countWithin(ways []*big.Int, lo, hi int) *big.Int {
+	sum := new(big.Int)
+	for t := max(lo, 0); t <= hi && t < len(ways); t++ {
+		sum.Add(sum, ways[t])
+	}
+	return sum
+}
+
+// appendRandom appends count runes drawn uniformly from set, leaving out the
+// rune that would extend a run beyond maxRepeat
+func appendRandom(src security.RandomSource, password, set []rune, count, maxRepeat int) ([]rune, error) {
+	for range count {
+		candidates := set
+		if maxRepeat > 0 && len(password) >= maxRepeat {
+			last := password[len(password)-1]
+			if trailingRun(password) >= maxRepeat && slices.Contains(set, last) {
+				candidates = slices.DeleteFunc(slices.Clone(set), func(r rune) bool { return r == last })
+			}
+		}
+		if len(candidates) == 0 {
+			return nil, fmt.Errorf("%w: no character can follow without exceeding the repeat limit", ErrPolicyUnsatisfiable)
+		}
+
+		idx, err := security.RandomIndex(src, len(candidates))
+		if err != nil {
+			return nil, err
+		}
+		password = append(password, candidates[idx])
+	}
+	return password, nil
+}
+
+// trailingRun returns the length of the run of equal runes ending s
+func trailingRun(s []rune) int {
+	n := 0
+	for i := len(s) - 1; i >= 0 && s[i] == s[len(s)-1]; i-- {
+		n++
+	}
+	return n
+}
+
+// longestRun returns the length of the longest run of one repeated rune in s
+func longestRun(s string) int {
+	longest, run := 0, 0
+	var prev rune
+	for i, r := range []rune(s) {
+		if i > 0 && r == prev {
+			run++
+		} else {
+			run = 1
+		}
+		prev = r
+		longest = max(longest, run)
+	}
+	return longest
+}
+
+// isSymbol reports whether r counts as a symbol: anything but letters and digits
+func isSymbol(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// classesOf returns the character classes present in s
+func classesOf(s string) int {
+	var classes int
+	for _, r := range s {
+		switch {
+		case unicode.IsUpper(r):
+			classes |= classUpper
+		case unicode.IsLower(r):
+			classes |= classLower
+		case unicode.IsDigit(r):
+			classes |= classDigit
+		case isSymbol(r):
+			classes |= classSymbol
+		}
+	}
+	return classes
+}
+
+// describeClasses lists the names of the given classes
+func describeClasses(classes int) string {
+	var names []string
+	for _, c := range classNames {
+		if classes&c.class != 0 {
+			names = append(names, c.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,251 @@
+package generator
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupPolicy(t *testing.T) {
+	assert.Equal(t, []string{"ad", "aws-iam", "pci-dss"}, PolicyNames())
+
+	p, err := LookupPolicy("AWS-IAM")
+	require.NoError(t, err)
+	assert.Equal(t, PolicyAWSIAM, *p)
+	assert.NotSame(t, &PolicyAWSIAM, p, "presets must be copied")
+
+	_, err = LookupPolicy("nist")
+	assert.ErrorIs(t, err, ErrUnknownPolicy)
+}
+
+func TestPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr bool
+	}{
+		{"presets", PolicyAWSIAM, false},
+		{"negative length", Policy{MinLength: -1}, true},
+		{"min above max", Policy{MinLength: 20, MaxLength: 10}, true},
+		{"too many classes", Policy{MinClasses: 5}, true},
+		{"negative repeat", Policy{MaxRepeat: -1}, true},
+		{"letter in symbols", Policy{Symbols: "!a"}, true},
+		{"empty forbidden", Policy{Forbidden: []string{""}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidPolicy)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPolicyCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   Policy
+		password string
+		wantErr  bool
+	}{
+		{"ok", PolicyAWSIAM, "Apple-Banana-42", false},
+		{"too short", Policy{MinLength: 12}, "Apple-42", true},
+		{"too long", Policy{MaxLength: 5}, "Apple-42", true},
+		{"missing digit", Policy{RequireDigit: true}, "Apple-Banana", true},
+		{"too few classes", Policy{MinClasses: 3}, "Apple-Banana", false},
+		{"two classes", Policy{MinClasses: 3}, "AppleBanana", true},
+		{"disallowed symbol", Policy{Symbols: "!"}, "apple banana", true},
+		{"forbidden substring", Policy{Forbidden: []string{"BAN"}}, "apple-banana", true},
+		{"repeated characters", Policy{MaxRepeat: 2}, "apple-baaanana", true},
+		{"repeats within limit", Policy{MaxRepeat: 2}, "apple-banana", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(tt.password)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrPolicyViolation)
+				assert.NotContains(t, err.Error(), tt.password)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPolicyCompatible(t *testing.T) {
+	base := Options{WordCount: 3, MinWordlists: 1, Capitalization: CapFirst, Separator: SepDash}
+
+	tests := []struct {
+		name   string
+		policy Policy
+		modify func(*Options)
+	}{
+		{"separator not allowed", Policy{Symbols: "!"}, func(*Options) {}},
+		{"separator forbidden", Policy{Forbidden: []string{"-"}}, func(*Options) {}},
+		{"no allowed specials", Policy{Symbols: " ~"}, func(o *Options) { o.Separator, o.AddSpecial, o.SpecialCount = SepSpace, true, 1 }},
+		{"upper never added", Policy{RequireUpper: true}, func(o *Options) { o.Capitalization = CapNone }},
+		{"digit never added", PolicyPCIDSS, func(*Options) {}},
+		{"too few classes", PolicyAWSIAM, func(o *Options) { o.Separator = SepNone }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := base
+			tt.modify(&opts)
+			assert.ErrorIs(t, tt.policy.compatible(opts), ErrPolicyUnsatisfiable)
+		})
+	}
+
+	assert.NoError(t, PolicyAWSIAM.compatible(base))
+	assert.NoError(t, PolicyAD.compatible(base))
+}
+
+func TestGenerateWithPolicy(t *testing.T) {
+	gen := setupWordsGenerator(t,
+		[]string{"ab", "abcd", "abcdefgh", "moon"},
+		[]string{"cd", "cdef", "cdefghij", "zoom"},
+	)
+	base := Options{WordCount: 3, MinWordlists: 1, Capitalization: CapFirst, Separator: SepDash}
+
+	tests := []struct {
+		name   string
+		policy Policy
+		modify func(*Options)
+		check  func(t *testing.T, password string)
+	}{
+		{
+			name:   "exact length",
+			policy: Policy{MinLength: 14, MaxLength: 14},
+			check: func(t *testing.T, password string) {
+				assert.Equal(t, 14, utf8.RuneCountInString(password))
+			},
+		},
+		{
+			name:   "forbidden substrings never appear",
+			policy: Policy{Forbidden: []string{"CDE"}},
+			check: func(t *testing.T, password string) {
+				assert.NotContains(t, strings.ToLower(password), "cde")
+			},
+		},
+		{
+			name:   "no repeats",
+			policy: Policy{MaxRepeat: 1},
+			modify: func(o *Options) { o.AddNumbers, o.NumberCount = true, 4 },
+			check: func(t *testing.T, password string) {
+				assert.Equal(t, 1, longestRun(password))
+			},
+		},
+		{
+			name:   "restricted symbols",
+			policy: Policy{Symbols: "-!"},
+			modify: func(o *Options) { o.AddSpecial, o.SpecialCount = true, 2 },
+			check: func(t *testing.T, password string) {
+				suffix := password[len(password)-2:]
+				assert.Empty(t, strings.Trim(suffix, "-!"), suffix)
+			},
+		},
+		{
+			name:   "random case has both cases",
+			policy: Policy{RequireUpper: true, RequireLower: true},
+			modify: func(o *Options) { o.Capitalization = CapRandom },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := base
+			if tt.modify != nil {
+				tt.modify(&opts)
+			}
+			opts.Policy = &tt.policy
+
+			for range 200 {
+				password, err := gen.Generate(opts)
+				require.NoError(t, err)
+				require.NoError(t, tt.policy.Check(password))
+				if tt.check != nil {
+					tt.check(t, password)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateWithPolicyUnsatisfiable(t *testing.T) {
+	gen := setupSizedGenerator(t, 8, 8)
+	opts := Options{WordCount: 3, MinWordlists: 2, Separator: SepDash}
+
+	t.Run("too long", func(t *testing.T) {
+		opts.Policy = &Policy{MaxLength: 8}
+		_, err := gen.Generate(opts)
+		assert.ErrorIs(t, err, ErrPolicyUnsatisfiable)
+	})
+
+	t.Run("length between word sizes", func(t *testing.T) {
+		// Three-letter words give exactly 11 characters
+		opts.Policy = &Policy{MinLength: 12}
+		_, err := gen.Generate(opts)
+		assert.ErrorIs(t, err, ErrPolicyUnsatisfiable)
+	})
+
+	t.Run("incompatible options", func(t *testing.T) {
+		opts.Policy = &PolicyPCIDSS
+		_, err := gen.Generate(opts)
+		assert.ErrorIs(t, err, ErrPolicyUnsatisfiable)
+		_, err = gen.EstimateEntropy(opts)
+		assert.ErrorIs(t, err, ErrPolicyUnsatisfiable)
+	})
+}
+
+func TestDrawWordsWithinUniform(t *testing.T) {
+	pools := [][]string{
+		{"a", "b", "cc", "dddd"},
+		{"e", "ff", "gg", "hhh"},
+	}
+
+	// Combinations with total length 4: a+hhh, b+hhh, cc+ff, cc+gg
+	want := map[string]bool{"a+hhh": true, "b+hhh": true, "cc+ff": true, "cc+gg": true}
+	counts := make(map[string]int)
+	const draws = 4000
+	for range draws {
+		words, ok, err := drawWordsWithin(nil, pools, 4, 4)
+		require.NoError(t, err)
+		require.True(t, ok)
+		counts[strings.Join(words, "+")]++
+	}
+
+	require.Len(t, counts, len(want))
+	for combo, n := range counts {
+		assert.True(t, want[combo], combo)
+		assert.InDelta(t, draws/len(want), n, 150, combo)
+	}
+
+	_, ok, err := drawWordsWithin(nil, pools, 9, 20)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestEntropyBreakdownPolicy(t *testing.T) {
+	gen := setupSizedGenerator(t, 8, 8)
+	opts := Options{WordCount: 3, MinWordlists: 2, Separator: SepDash, Capitalization: CapFirst, AddSpecial: true, SpecialCount: 1, Policy: &PolicyAWSIAM}
+
+	b, err := gen.EntropyBreakdown(opts)
+	require.NoError(t, err)
+	assert.InDelta(t, math.Log2(19), b.Specials, 1e-9)
+	assert.Empty(t, b.Warnings)
+
+	opts.Policy = &Policy{MaxLength: 11}
+	b, err = gen.EntropyBreakdown(opts)
+	require.NoError(t, err)
+	require.Len(t, b.Warnings, 1)
+	assert.Contains(t, b.Warnings[0], "length bounds")
+}
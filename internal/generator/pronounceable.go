@@ -0,0 +1,194 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/greysquirr3l/glyphic/internal/security"
+)
+
+// Letters of the syllable template used by StrategyPronounceable. A syllable is
+// an onset consonant, a vowel and an optional coda consonant. Every onset and
+// coda is a single letter, so a consonant directly before a vowel always opens
+// a syllable and any other consonant closes one: each pseudo-word splits into
+// syllables in exactly one way, and distinct draws give distinct words.
+const (
+	syllableOnsets = "bdfghjklmnprstvz"
+	syllableVowels = "aeiou"
+	syllableCodas  = "klmnrst"
+)
+
+// Bounds for pronounceable pseudo-words
+const (
+	MaxPronounceableWords = 12
+	MinSyllables          = 1
+	MaxSyllables          = 6
+)
+
+var (
+	ErrInvalidPseudoWords = errors.New("pronounceable word count must be between 1 and 12")
+	ErrInvalidSyllables   = errors.New("syllables per word must be between 1 and 6")
+)
+
+// syllableCount is the number of distinct syllables
+const syllableCount = len(syllableOnsets) * len(syllableVowels) * (len(syllableCodas) + 1)
+
+// SyllableBits is the entropy of one syllable drawn uniformly from the template
+var SyllableBits = math.Log2(float64(syllableCount))
+
+// syllable returns the syllable with index idx in [0, syllableCount)
+func syllable(idx int) string {
+	codas := len(syllableCodas) + 1
+	s := []byte{syllableOnsets[idx/(len(syllableVowels)*codas)], syllableVowels[idx/codas%len(syllableVowels)]}
+	if coda := idx % codas; coda > 0 {
+		s = append(s, syllableCodas[coda-1])
+	}
+	return string(s)
+}
+
+// pseudoWord draws a word of the given number of syllables, redrawing it while
+// it is excluded so the result is uniform over the allowed words
+func (g *Generator) pseudoWord(src security.RandomSource, syllables int) (string, error) {
+	var sb strings.Builder
+	for {
+		sb.Reset()
+		for range syllables {
+			idx, err := security.RandomIndex(src, syllableCount)
+			if err != nil {
+				return "", fmt.Errorf("failed to select syllable: %w", err)
+			}
+			sb.WriteString(syllable(idx))
+		}
+		if !g.exclusions.Contains(sb.String()) {
+			return sb.String(), nil
+		}
+	}
+}
+
+// countSyllables returns the number of syllables word splits into under the
+// template, or -1 when it cannot be produced by it
+func countSyllables(word string) int {
+	isVowel := func(i int) bool { return i < len(word) && strings.IndexByte(syllableVowels, word[i]) >= 0 }
+
+	count := 0
+	for i := 0; i < len(word); {
+		if strings.IndexByte(syllableOnsets, word[i]) < 0 || !isVowel(i+1) {
+			return -1
+		}
+		i += 2
+		count++
+
+		// A consonant not followed by a vowel closes the syllable
+		if i < len(word) && !isVowel(i+1) {
+			if strings.IndexByte(syllableCodas, word[i]) < 0 {
+				return -1
+			}
+			i++
+		}
+	}
+	return count
+}
+
+// pronounceableWords returns the number of allowed words of the given number of
+// syllables: every template word minus the excluded words it can produce
+func (g *Generator) pronounceableWords(syllables int) *big.Int {
+	excluded := 0
+	for _, word := range g.exclusions.Words() {
+		if countSyllables(word) == syllables {
+			excluded++
+		}
+	}
+
+	total := new(big.Int).Exp(big.NewInt(int64(syllableCount)), big.NewInt(int64(syllables)), nil)
+	return total.Sub(total, big.NewInt(int64(excluded)))
+}
+
+// generatePronounceable builds WordCount pseudo-words of Syllables syllables,
+// then applies the capitalization, separator and suffix options like a
+// diceware passphrase
+func (g *Generator) generatePronounceable(src security.RandomSource, opts Options) (string, error) {
+	words := make([]string, opts.WordCount)
+	for i := range words {
+		word, err := g.pseudoWord(src, opts.Syllables)
+		if err != nil {
+			return "", err
+		}
+		words[i] = word
+	}
+
+	words, err := applyCapitalization(src, words, opts.Capitalization, opts.caseRules())
+	if err != nil {
+		return "", fmt.Errorf("failed to apply capitalization: %w", err)
+	}
+	password := strings.Join(words, getSeparator(opts.Separator, opts.CustomSep))
+
+	if opts.AddNumbers {
+		numbers, err := generateRandomNumbers(src, opts.NumberCount)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate numbers: %w", err)
+		}
+		password += numbers
+	}
+	if opts.AddSpecial {
+		specials, err := generateRandomSpecialChars(src, opts.SpecialCount)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate special characters: %w", err)
+		}
+		password += specials
+	}
+	return password, nil
+}
+
+// pronounceableBreakdown credits every pseudo-word with log2 of the allowed
+// words, which is exact because the template is uniquely decodable. Random
+// capitalization is credited with the expected letters per word.
+func (g *Generator) pronounceableBreakdown(opts Options) (*EntropyBreakdown, error) {
+	allowed := g.pronounceableWords(opts.Syllables)
+	if allowed.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: every %d-syllable word is excluded", ErrNoWordsAvailable, opts.Syllables)
+	}
+
+	slot := SlotEntropy{
+		Pools: []PoolShare{{ListID: StrategyPronounceable.String(), Words: int(allowed.Int64()), Probability: 1}},
+		Bits:  log2Big(allowed),
+	}
+	if opts.Capitalization == CapRandom {
+		letters := 2 + float64(len(syllableCodas))/float64(len(syllableCodas)+1)
+		slot.Capitalization = float64(opts.Syllables) * letters
+	}
+
+	b := &EntropyBreakdown{Exact: true, SyllableBits: SyllableBits}
+	for range opts.WordCount {
+		b.Slots = append(b.Slots, slot)
+		b.Words += slot.Bits
+		b.Capitalization += slot.Capitalization
+	}
+	b.Digits, b.Specials = suffixBits(opts)
+	b.Total = b.Words + b.Capitalization + b.Digits + b.Specials
+	return b, nil
+}
+
+// resolvePronounceableTarget returns opts with the fewest pseudo-words that
+// reach the target entropy, keeping the syllable count and suffix
+func (g *Generator) resolvePronounceableTarget(opts Options) (Options, error) {
+	b, err := g.pronounceableBreakdown(Options{WordCount: 1, Syllables: opts.Syllables, Capitalization: opts.Capitalization})
+	if err != nil {
+		return Options{}, err
+	}
+	digits, specials := suffixBits(opts)
+
+	perWord := b.Total
+	for words := 1; words <= MaxTargetWordCount; words++ {
+		if float64(words)*perWord+digits+specials >= opts.TargetEntropyBits {
+			candidate := opts
+			candidate.WordCount = words
+			return candidate, nil
+		}
+	}
+
+	return Options{}, fmt.Errorf("%w: %.1f bits needs more than %d pronounceable words (at most %.1f bits)",
+		ErrTargetUnreachable, opts.TargetEntropyBits, MaxTargetWordCount, float64(MaxTargetWordCount)*perWord+digits+specials)
+}
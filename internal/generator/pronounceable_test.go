@@ -0,0 +1,181 @@
+package generator
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyllableTemplateUniquelyDecodable(t *testing.T) {
+	syllables := make([]string, syllableCount)
+	seen := make(map[string]bool, syllableCount)
+	for i := range syllables {
+		syllables[i] = syllable(i)
+		assert.False(t, seen[syllables[i]], "duplicate syllable %q", syllables[i])
+		seen[syllables[i]] = true
+		assert.Equal(t, 1, countSyllables(syllables[i]), syllables[i])
+	}
+
+	// Every pair of syllables gives a distinct two-syllable word
+	words := make(map[string]bool, syllableCount*syllableCount)
+	for _, a := range syllables {
+		for _, b := range syllables {
+			word := a + b
+			require.False(t, words[word], "%q splits two ways", word)
+			words[word] = true
+			require.Equal(t, 2, countSyllables(word), word)
+		}
+	}
+}
+
+func TestCountSyllables(t *testing.T) {
+	tests := []struct {
+		word  string
+		count int
+	}{
+		{"ta", 1},
+		{"tar", 1},
+		{"tarvik", 2},
+		{"tatra", 2},  // tat-ra
+		{"tapra", -1}, // p cannot close a syllable
+		{"bomlu", 2},
+		{"omblu", -1}, // no onset
+		{"taa", -1},
+		{"tarx", -1},
+		{"taxi", -1},
+		{"banana", 3},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			assert.Equal(t, tt.count, countSyllables(tt.word))
+		})
+	}
+}
+
+func TestGeneratePronounceable(t *testing.T) {
+	gen := setupTestGenerator(t)
+	opts := Options{
+		Strategy:       StrategyPronounceable,
+		WordCount:      2,
+		Syllables:      2,
+		Capitalization: CapNone,
+		Separator:      SepDash,
+		AddNumbers:     true,
+		NumberCount:    2,
+	}
+
+	for range 100 {
+		password, err := gen.Generate(opts)
+		require.NoError(t, err)
+
+		words := strings.Split(password[:len(password)-2], "-")
+		require.Len(t, words, 2, password)
+		for _, word := range words {
+			assert.Equal(t, 2, countSyllables(word), password)
+		}
+		assert.Regexp(t, `[0-9]{2}$`, password)
+	}
+}
+
+func TestGeneratePronounceableNeverExcluded(t *testing.T) {
+	gen := setupTestGenerator(t)
+
+	// Exclude every one-syllable word but four
+	allowed := map[string]bool{"ba": true, "ko": true, "mus": true, "zet": true}
+	for i := range syllableCount {
+		if s := syllable(i); !allowed[s] {
+			gen.exclusions.Add(s)
+		}
+	}
+
+	opts := Options{Strategy: StrategyPronounceable, WordCount: 3, Syllables: 1, Separator: SepSpace}
+	for range 200 {
+		password, err := gen.Generate(opts)
+		require.NoError(t, err)
+		for _, word := range strings.Fields(password) {
+			assert.True(t, allowed[strings.ToLower(word)], password)
+		}
+	}
+
+	b, err := gen.EntropyBreakdown(opts)
+	require.NoError(t, err)
+	assert.InDelta(t, 3*2.0, b.Words, 1e-9)
+	require.Len(t, b.Slots, 3)
+	assert.Equal(t, 4, b.Slots[0].Pools[0].Words)
+
+	// Excluding the rest leaves nothing to draw
+	gen.exclusions.Add("ba", "ko", "mus", "zet")
+	_, err = gen.EntropyBreakdown(opts)
+	require.ErrorIs(t, err, ErrNoWordsAvailable)
+}
+
+func TestPronounceableEntropy(t *testing.T) {
+	gen := setupTestGenerator(t)
+	gen.exclusions.Add("tarvik", "banana", "apple") // only tarvik is a two-syllable template word
+
+	b, err := gen.EntropyBreakdown(Options{
+		Strategy:       StrategyPronounceable,
+		WordCount:      3,
+		Syllables:      2,
+		Capitalization: CapRandom,
+		AddSpecial:     true,
+		SpecialCount:   1,
+	})
+	require.NoError(t, err)
+
+	perWord := math.Log2(640*640 - 1)
+	assert.InDelta(t, 3*perWord, b.Words, 1e-9)
+	assert.InDelta(t, math.Log2(640), b.SyllableBits, 1e-12)
+	assert.InDelta(t, 3*2*(2+7.0/8), b.Capitalization, 1e-9)
+	assert.InDelta(t, math.Log2(float64(len(SpecialChars))), b.Specials, 1e-9)
+	assert.InDelta(t, b.Words+b.Capitalization+b.Specials, b.Total, 1e-9)
+	assert.True(t, b.Exact)
+}
+
+func TestResolveTargetPronounceable(t *testing.T) {
+	gen := setupTestGenerator(t)
+
+	// Two syllables carry 18.6 bits per word
+	resolved, err := gen.ResolveTarget(Options{Strategy: StrategyPronounceable, WordCount: 2, Syllables: 2, TargetEntropyBits: 60})
+	require.NoError(t, err)
+	assert.Equal(t, 4, resolved.WordCount)
+
+	// The largest target may take more words than MaxPronounceableWords
+	resolved, err = gen.ResolveTarget(Options{Strategy: StrategyPronounceable, WordCount: 2, Syllables: 1, TargetEntropyBits: MaxTargetEntropyBits})
+	require.NoError(t, err)
+	assert.Equal(t, 55, resolved.WordCount)
+	_, err = gen.Generate(resolved)
+	require.NoError(t, err)
+}
+
+func TestValidatePronounceable(t *testing.T) {
+	valid := Options{Strategy: StrategyPronounceable, WordCount: 2, Syllables: 2}
+	require.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		change func(*Options)
+		err    error
+	}{
+		{"no words", func(o *Options) { o.WordCount = 0 }, ErrInvalidPseudoWords},
+		{"too many words", func(o *Options) { o.WordCount = 13 }, ErrInvalidPseudoWords},
+		{"no syllables", func(o *Options) { o.Syllables = 0 }, ErrInvalidSyllables},
+		{"too many syllables", func(o *Options) { o.Syllables = 7 }, ErrInvalidSyllables},
+		{"number count", func(o *Options) { o.AddNumbers = true }, ErrInvalidNumberCount},
+		{"special count", func(o *Options) { o.AddSpecial = true; o.SpecialCount = 5 }, ErrInvalidSpecialCount},
+		{"policy", func(o *Options) { o.Policy = &PolicyAD }, ErrStrategyOption},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := valid
+			tt.change(&opts)
+			require.ErrorIs(t, opts.Validate(), tt.err)
+		})
+	}
+}
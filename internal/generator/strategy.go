@@ -0,0 +1,514 @@
+package generator
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"slices"
+	"strings"
+
+	"github.com/greysquirr3l/glyphic/internal/security"
+)
+
+// Strategy selects how a password is built
+type Strategy int
+
+const (
+	StrategyDiceware      Strategy = iota // words drawn from the wordlists
+	StrategyCharset                       // random characters from selected classes
+	StrategyPIN                           // random decimal digits
+	StrategyToken                         // random bytes in a text encoding
+	StrategyPronounceable                 // pseudo-words built from syllables
+)
+
+// CharClass is a set of character classes for StrategyCharset
+type CharClass int
+
+const (
+	ClassLower   CharClass = 1 << iota // a-z
+	ClassUpper                         // A-Z
+	ClassDigits                        // 0-9
+	ClassSymbols                       // SpecialChars
+
+	ClassAll = ClassLower | ClassUpper | ClassDigits | ClassSymbols
+)
+
+// TokenEncoding is the text encoding of a StrategyToken password
+type TokenEncoding int
+
+const (
+	EncodingHex       TokenEncoding = iota // lowercase hex
+	EncodingBase32                         // RFC 4648 base32 without padding
+	EncodingBase64URL                      // RFC 4648 URL-safe base64 without padding
+)
+
+// Bounds for the non-diceware strategies
+const (
+	MinCharsetLength = 8
+	MaxCharsetLength = 128
+	MinPINLength     = 4
+	MaxPINLength     = 12
+	MinTokenBits     = 64
+	MaxTokenBits     = 1024
+)
+
+// AmbiguousChars are left out of charset passwords with NoAmbiguous
+const AmbiguousChars = "0O1lI|"
+
+var (
+	ErrUnknownStrategy  = errors.New("unknown password strategy")
+	ErrUnknownCharClass = errors.New("unknown character class")
+	ErrUnknownEncoding  = errors.New("unknown token encoding")
+	ErrNoCharClasses    = errors.New("at least one character class is required")
+	ErrInvalidLength    = errors.New("charset length must be between 8 and 128")
+	ErrInvalidPINLength = errors.New("PIN length must be between 4 and 12")
+	ErrInvalidTokenBits = errors.New("token bits must be a multiple of 8 between 64 and 1024")
+	ErrStrategyOption   = errors.New("option only applies to diceware passphrases")
+)
+
+// strategyNames maps strategies to their CLI names
+var strategyNames = map[Strategy]string{
+	StrategyDiceware:      "diceware",
+	StrategyCharset:       "charset",
+	StrategyPIN:           "pin",
+	StrategyToken:         "token",
+	StrategyPronounceable: "pronounceable",
+}
+
+// charClassName is the CLI name of a single charset class
+type charClassName struct {
+	class CharClass
+	name  string
+}
+
+// charClassNames lists the charset classes in output order
+var charClassNames = []charClassName{
+	{ClassLower, "lower"},
+	{ClassUpper, "upper"},
+	{ClassDigits, "digits"},
+	{ClassSymbols, "symbols"},
+}
+
+// encodingNames maps token encodings to their CLI names
+var encodingNames = map[TokenEncoding]string{
+	EncodingHex:       "hex",
+	EncodingBase32:    "base32",
+	EncodingBase64URL: "base64url",
+}
+
+// String returns the CLI name of the strategy
+func (s Strategy) String() string {
+	if name, ok := strategyNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ParseStrategy returns the strategy with the given name
+func ParseStrategy(name string) (Strategy, error) {
+	for s, n := range strategyNames {
+		if strings.EqualFold(n, name) {
+			return s, nil
+		}
+	}
+	return StrategyDiceware, fmt.Errorf("%w: %q", ErrUnknownStrategy, name)
+}
+
+// String returns the comma-separated CLI names of the classes
+func (c CharClass) String() string {
+	var names []string
+	for _, cn := range charClassNames {
+		if c&cn.class != 0 {
+			names = append(names, cn.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// ParseCharClasses returns the classes named in a comma-separated list
+func ParseCharClasses(list string) (CharClass, error) {
+	var classes CharClass
+	for name := range strings.SplitSeq(list, ",") {
+		name = strings.TrimSpace(name)
+		i := slices.IndexFunc(charClassNames, func(cn charClassName) bool { return strings.EqualFold(cn.name, name) })
+		if i < 0 {
+			return 0, fmt.Errorf("%w: %q", ErrUnknownCharClass, name)
+		}
+		classes |= charClassNames[i].class
+	}
+	return classes, nil
+}
+
+// String returns the CLI name of the encoding
+func (e TokenEncoding) String() string {
+	if name, ok := encodingNames[e]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ParseTokenEncoding returns the token encoding with the given name
+func ParseTokenEncoding(name string) (TokenEncoding, error) {
+	for e, n := range encodingNames {
+		if strings.EqualFold(n, name) {
+			return e, nil
+		}
+	}
+	return EncodingHex, fmt.Errorf("%w: %q", ErrUnknownEncoding, name)
+}
+
+// UsesWordlists reports whether passwords are built from wordlists
+func (o *Options) UsesWordlists() bool {
+	return o.Strategy == StrategyDiceware
+}
+
+// validateStrategy checks the options of a non-diceware strategy. The
+// pronounceable strategy keeps the word, capitalization, separator and suffix
+// options; the others ignore the word settings and reject options that would
+// add characters the user expects to see.
+func (o *Options) validateStrategy() error {
+	if o.Policy != nil {
+		return fmt.Errorf("%w: password policies", ErrStrategyOption)
+	}
+	if o.Template != "" {
+		return fmt.Errorf("%w: passphrase templates", ErrStrategyOption)
+	}
+	if o.transforms() {
+		return fmt.Errorf("%w: substitutions and placement", ErrStrategyOption)
+	}
+	if o.Strategy != StrategyPronounceable {
+		switch {
+		case o.AddNumbers:
+			return fmt.Errorf("%w: appended digits", ErrStrategyOption)
+		case o.AddSpecial:
+			return fmt.Errorf("%w: appended special characters", ErrStrategyOption)
+		}
+	}
+
+	switch o.Strategy {
+	case StrategyPronounceable:
+		maxWords := MaxPronounceableWords
+		if o.TargetEntropyBits > 0 {
+			maxWords = MaxTargetWordCount // chosen by ResolveTarget
+		}
+		if o.WordCount < 1 || o.WordCount > maxWords {
+			return ErrInvalidPseudoWords
+		}
+		if o.Syllables < MinSyllables || o.Syllables > MaxSyllables {
+			return ErrInvalidSyllables
+		}
+		if o.AddNumbers && (o.NumberCount < 1 || o.NumberCount > 4) {
+			return ErrInvalidNumberCount
+		}
+		if o.AddSpecial && (o.SpecialCount < 1 || o.SpecialCount > 4) {
+			return ErrInvalidSpecialCount
+		}
+	case StrategyCharset:
+		if o.Classes&ClassAll == 0 || o.Classes&^ClassAll != 0 {
+			return ErrNoCharClasses
+		}
+		if o.Length < MinCharsetLength || o.Length > MaxCharsetLength {
+			return ErrInvalidLength
+		}
+	case StrategyPIN:
+		if o.PINLength < MinPINLength || o.PINLength > MaxPINLength {
+			return ErrInvalidPINLength
+		}
+	case StrategyToken:
+		if o.TokenBits < MinTokenBits || o.TokenBits > MaxTokenBits || o.TokenBits%8 != 0 {
+			return ErrInvalidTokenBits
+		}
+		if _, ok := encodingNames[o.TokenEncoding]; !ok {
+			return fmt.Errorf("%w: %d", ErrUnknownEncoding, o.TokenEncoding)
+		}
+	default:
+		return fmt.Errorf("%w: %d", ErrUnknownStrategy, o.Strategy)
+	}
+	return nil
+}
+
+// charsetClasses returns the alphabet of every selected class, without the
+// ambiguous characters when NoAmbiguous is set
+func (o *Options) charsetClasses() [][]rune {
+	alphabets := map[CharClass][]rune{
+		ClassLower:   []rune("abcdefghijklmnopqrstuvwxyz"),
+		ClassUpper:   []rune("ABCDEFGHIJKLMNOPQRSTUVWXYZ"),
+		ClassDigits:  []rune("0123456789"),
+		ClassSymbols: SpecialChars,
+	}
+
+	var classes [][]rune
+	for _, cn := range charClassNames {
+		if o.Classes&cn.class == 0 {
+			continue
+		}
+		chars := alphabets[cn.class]
+		if o.NoAmbiguous {
+			chars = slices.DeleteFunc(slices.Clone(chars), func(r rune) bool {
+				return strings.ContainsRune(AmbiguousChars, r)
+			})
+		}
+		classes = append(classes, chars)
+	}
+	return classes
+}
+
+// generateStrategy builds a non-diceware password drawing from src
+func (g *Generator) generateStrategy(src security.RandomSource, opts Options) (string, error) {
+	switch opts.Strategy {
+	case StrategyPronounceable:
+		return g.generatePronounceable(src, opts)
+	case StrategyCharset:
+		return generateCharset(src, opts)
+	case StrategyPIN:
+		return generatePIN(src, opts)
+	case StrategyToken:
+		return generateToken(src, opts)
+	default:
+		return "", fmt.Errorf("%w: %d", ErrUnknownStrategy, opts.Strategy)
+	}
+}
+
+// generateCharset draws Length characters from the union of the classes and
+// redraws the whole password until every class appears, which keeps the
+// result uniform over the passwords that satisfy the rule
+func generateCharset(src security.RandomSource, opts Options) (string, error) {
+	classes := opts.charsetClasses()
+	alphabet := slices.Concat(classes...)
+
+	password := make([]rune, opts.Length)
+	for {
+		for i := range password {
+			idx, err := security.RandomIndex(src, len(alphabet))
+			if err != nil {
+				return "", fmt.Errorf("failed to select character: %w", err)
+			}
+			password[i] = alphabet[idx]
+		}
+
+		if coversClasses(password, classes) {
+			return string(password), nil
+		}
+	}
+}
+
+// coversClasses reports whether password has a character from every class
+func coversClasses(password []rune, classes [][]rune) bool {
+	for _, class := range classes {
+		if !slices.ContainsFunc(password, func(r rune) bool { return slices.Contains(class, r) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// generatePIN draws PINLength digits and redraws the whole PIN until it meets
+// the repeat and sequence rules, keeping it uniform over the allowed PINs
+func generatePIN(src security.RandomSource, opts Options) (string, error) {
+	digits := make([]int, opts.PINLength)
+	for {
+		for i := range digits {
+			d, err := security.RandomIndex(src, 10)
+			if err != nil {
+				return "", fmt.Errorf("failed to select digit: %w", err)
+			}
+			digits[i] = d
+		}
+
+		if pinAllowed(digits, opts) {
+			var sb strings.Builder
+			for _, d := range digits {
+				sb.WriteByte(byte('0' + d))
+			}
+			return sb.String(), nil
+		}
+	}
+}
+
+// pinAllowed reports whether digits meet the NoRepeat and NoSequence rules.
+// NoRepeat forbids a digit directly followed by itself; NoSequence forbids
+// three consecutive digits that count up or down by one, such as 123 or 987.
+func pinAllowed(digits []int, opts Options) bool {
+	for i := 1; i < len(digits); i++ {
+		if !pinStepAllowed(digits, i, opts) {
+			return false
+		}
+	}
+	return true
+}
+
+// pinStepAllowed checks the rules for the digit at position i given the
+// digits before it
+func pinStepAllowed(digits []int, i int, opts Options) bool {
+	if opts.NoRepeat && digits[i] == digits[i-1] {
+		return false
+	}
+	if opts.NoSequence && i >= 2 {
+		step := digits[i] - digits[i-1]
+		if (step == 1 || step == -1) && digits[i-1]-digits[i-2] == step {
+			return false
+		}
+	}
+	return true
+}
+
+// generateToken draws TokenBits of random bytes and encodes them
+func generateToken(src security.RandomSource, opts Options) (string, error) {
+	data := make([]byte, opts.TokenBits/8)
+	defer security.SecureZero(data)
+
+	if err := security.RandomBytes(src, data); err != nil {
+		return "", fmt.Errorf("failed to draw token: %w", err)
+	}
+
+	switch opts.TokenEncoding {
+	case EncodingBase32:
+		return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(data), nil
+	case EncodingBase64URL:
+		return base64.RawURLEncoding.EncodeToString(data), nil
+	default:
+		return hex.EncodeToString(data), nil
+	}
+}
+
+// strategyBreakdown returns the entropy of a non-diceware password
+func (g *Generator) strategyBreakdown(opts Options) (*EntropyBreakdown, error) {
+	if opts.Strategy == StrategyPronounceable {
+		return g.pronounceableBreakdown(opts)
+	}
+	bits := strategyBits(opts)
+	return &EntropyBreakdown{Characters: bits, Total: bits, Exact: true}, nil
+}
+
+// strategyBits returns the exact entropy of a charset, PIN or token password:
+// log2 of the number of passwords the strategy can produce, all equally likely
+func strategyBits(opts Options) float64 {
+	switch opts.Strategy {
+	case StrategyCharset:
+		var sizes []int
+		for _, class := range opts.charsetClasses() {
+			sizes = append(sizes, len(class))
+		}
+		return log2Big(charsetCount(sizes, opts.Length))
+	case StrategyPIN:
+		return math.Log2(float64(pinCount(opts)))
+	case StrategyToken:
+		return float64(opts.TokenBits)
+	default:
+		return 0
+	}
+}
+
+// charsetCount counts the strings of length n over disjoint classes of the
+// given sizes that use every class, by inclusion-exclusion over the classes
+// left out
+func charsetCount(sizes []int, n int) *big.Int {
+	total := new(big.Int)
+	for mask := range 1 << len(sizes) {
+		size := 0
+		for i, s := range sizes {
+			if mask&(1<<i) == 0 {
+				size += s
+			}
+		}
+
+		term := new(big.Int).Exp(big.NewInt(int64(size)), big.NewInt(int64(n)), nil)
+		if bits.OnesCount(uint(mask))%2 == 1 {
+			total.Sub(total, term)
+		} else {
+			total.Add(total, term)
+		}
+	}
+	return total
+}
+
+// pinCount counts the PINs that meet the repeat and sequence rules, tracking
+// the last two digits of every prefix
+func pinCount(opts Options) uint64 {
+	// counts[a][b] is the number of allowed prefixes ending in digits a, b
+	var counts [10][10]uint64
+	for a := range 10 {
+		for b := range 10 {
+			if pinAllowed([]int{a, b}, opts) {
+				counts[a][b] = 1
+			}
+		}
+	}
+
+	for range opts.PINLength - 2 {
+		var next [10][10]uint64
+		for a := range 10 {
+			for b := range 10 {
+				for c := range 10 {
+					if counts[a][b] > 0 && pinStepAllowed([]int{a, b, c}, 2, opts) {
+						next[b][c] += counts[a][b]
+					}
+				}
+			}
+		}
+		counts = next
+	}
+
+	var total uint64
+	for a := range 10 {
+		for b := range 10 {
+			total += counts[a][b]
+		}
+	}
+	return total
+}
+
+// log2Big returns log2 of a positive integer, accurate to float64 precision
+func log2Big(x *big.Int) float64 {
+	shift := max(x.BitLen()-53, 0)
+	top := new(big.Int).Rsh(x, uint(shift))
+	return float64(shift) + math.Log2(float64(top.Uint64()))
+}
+
+// resolveStrategyTarget returns opts with the shortest length that reaches
+// the target entropy
+func (g *Generator) resolveStrategyTarget(opts Options) (Options, error) {
+	if opts.Strategy == StrategyPronounceable {
+		return g.resolvePronounceableTarget(opts)
+	}
+
+	var best float64
+	try := func(candidate Options) bool {
+		bits := strategyBits(candidate)
+		best = max(best, bits)
+		return bits >= opts.TargetEntropyBits
+	}
+
+	switch opts.Strategy {
+	case StrategyCharset:
+		for length := MinCharsetLength; length <= MaxCharsetLength; length++ {
+			candidate := opts
+			candidate.Length = length
+			if try(candidate) {
+				return candidate, nil
+			}
+		}
+	case StrategyPIN:
+		for length := MinPINLength; length <= MaxPINLength; length++ {
+			candidate := opts
+			candidate.PINLength = length
+			if try(candidate) {
+				return candidate, nil
+			}
+		}
+	case StrategyToken:
+		candidate := opts
+		candidate.TokenBits = max(MinTokenBits, int(math.Ceil(opts.TargetEntropyBits/8))*8)
+		if try(candidate) {
+			return candidate, nil
+		}
+	}
+
+	return Options{}, fmt.Errorf("%w: %.1f bits with the %s strategy (at most %.1f bits)",
+		ErrTargetUnreachable, opts.TargetEntropyBits, opts.Strategy, best)
+}
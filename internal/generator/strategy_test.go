@@ -0,0 +1,331 @@
+package generator
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"math"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/greysquirr3l/glyphic/internal/security"
+)
+
+func TestParseStrategy(t *testing.T) {
+	for s, name := range strategyNames {
+		parsed, err := ParseStrategy(strings.ToUpper(name))
+		require.NoError(t, err)
+		assert.Equal(t, s, parsed)
+		assert.Equal(t, name, s.String())
+	}
+
+	_, err := ParseStrategy("words")
+	require.ErrorIs(t, err, ErrUnknownStrategy)
+}
+
+func TestParseCharClasses(t *testing.T) {
+	classes, err := ParseCharClasses("lower, Upper,digits")
+	require.NoError(t, err)
+	assert.Equal(t, ClassLower|ClassUpper|ClassDigits, classes)
+	assert.Equal(t, "lower,upper,digits", classes.String())
+	assert.Equal(t, "lower,upper,digits,symbols", ClassAll.String())
+
+	_, err = ParseCharClasses("lower,emoji")
+	require.ErrorIs(t, err, ErrUnknownCharClass)
+	_, err = ParseCharClasses("")
+	require.ErrorIs(t, err, ErrUnknownCharClass)
+}
+
+func TestParseTokenEncoding(t *testing.T) {
+	for e, name := range encodingNames {
+		parsed, err := ParseTokenEncoding(name)
+		require.NoError(t, err)
+		assert.Equal(t, e, parsed)
+	}
+
+	_, err := ParseTokenEncoding("base58")
+	require.ErrorIs(t, err, ErrUnknownEncoding)
+}
+
+func TestValidateStrategy(t *testing.T) {
+	charset := Options{Strategy: StrategyCharset, Length: 16, Classes: ClassAll}
+	pin := Options{Strategy: StrategyPIN, PINLength: 6}
+	token := Options{Strategy: StrategyToken, TokenBits: 128}
+
+	with := func(opts Options, change func(*Options)) Options {
+		change(&opts)
+		return opts
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		err  error
+	}{
+		{name: "charset", opts: charset},
+		{name: "pin", opts: pin},
+		{name: "token", opts: token},
+		{name: "word options ignored", opts: with(pin, func(o *Options) { o.WordCount = 99; o.MinWordlists = 0 })},
+		{name: "no classes", opts: with(charset, func(o *Options) { o.Classes = 0 }), err: ErrNoCharClasses},
+		{name: "unknown class bit", opts: with(charset, func(o *Options) { o.Classes = 1 << 7 }), err: ErrNoCharClasses},
+		{name: "charset too short", opts: with(charset, func(o *Options) { o.Length = 7 }), err: ErrInvalidLength},
+		{name: "charset too long", opts: with(charset, func(o *Options) { o.Length = 129 }), err: ErrInvalidLength},
+		{name: "pin too short", opts: with(pin, func(o *Options) { o.PINLength = 3 }), err: ErrInvalidPINLength},
+		{name: "pin too long", opts: with(pin, func(o *Options) { o.PINLength = 13 }), err: ErrInvalidPINLength},
+		{name: "token bits not bytes", opts: with(token, func(o *Options) { o.TokenBits = 100 }), err: ErrInvalidTokenBits},
+		{name: "token too small", opts: with(token, func(o *Options) { o.TokenBits = 56 }), err: ErrInvalidTokenBits},
+		{name: "unknown encoding", opts: with(token, func(o *Options) { o.TokenEncoding = 9 }), err: ErrUnknownEncoding},
+		{name: "unknown strategy", opts: Options{Strategy: 9}, err: ErrUnknownStrategy},
+		{name: "numbers", opts: with(pin, func(o *Options) { o.AddNumbers = true; o.NumberCount = 2 }), err: ErrStrategyOption},
+		{name: "specials", opts: with(charset, func(o *Options) { o.AddSpecial = true; o.SpecialCount = 1 }), err: ErrStrategyOption},
+		{name: "policy", opts: with(token, func(o *Options) { o.Policy = &PolicyAD }), err: ErrStrategyOption},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if tt.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestGenerateCharset(t *testing.T) {
+	gen := setupTestGenerator(t)
+
+	tests := []struct {
+		name    string
+		classes CharClass
+		allowed func(rune) bool
+	}{
+		{name: "alphanumeric", classes: ClassLower | ClassUpper | ClassDigits, allowed: func(r rune) bool {
+			return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+		}},
+		{name: "digits", classes: ClassDigits, allowed: unicode.IsDigit},
+		{name: "all", classes: ClassAll, allowed: func(r rune) bool {
+			return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(string(SpecialChars), r))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{Strategy: StrategyCharset, Length: 8, Classes: tt.classes, NoAmbiguous: true}
+			for range 200 {
+				password, err := gen.Generate(opts)
+				require.NoError(t, err)
+				assert.Len(t, password, 8)
+				assert.NotContains(t, password, "0")
+				assert.True(t, coversClasses([]rune(password), opts.charsetClasses()), password)
+				for _, r := range password {
+					assert.True(t, tt.allowed(r), "unexpected %q in %q", r, password)
+					assert.NotContains(t, AmbiguousChars, string(r))
+				}
+			}
+		})
+	}
+}
+
+func TestGeneratePIN(t *testing.T) {
+	gen := setupTestGenerator(t)
+	opts := Options{Strategy: StrategyPIN, PINLength: 12, NoRepeat: true, NoSequence: true}
+
+	for range 500 {
+		pin, err := gen.Generate(opts)
+		require.NoError(t, err)
+		require.Len(t, pin, 12)
+
+		digits := make([]int, len(pin))
+		for i, r := range pin {
+			require.True(t, unicode.IsDigit(r))
+			digits[i] = int(r - '0')
+		}
+		assert.True(t, pinAllowed(digits, opts), pin)
+	}
+}
+
+func TestPINAllowed(t *testing.T) {
+	tests := []struct {
+		pin        string
+		noRepeat   bool
+		noSequence bool
+	}{
+		{pin: "1357", noRepeat: true, noSequence: true},
+		{pin: "1123", noRepeat: false, noSequence: false},
+		{pin: "1213", noRepeat: true, noSequence: true},
+		{pin: "9870", noRepeat: true, noSequence: false},
+		{pin: "0121", noRepeat: true, noSequence: false},
+		{pin: "8912", noRepeat: true, noSequence: true},  // two-digit runs are allowed
+		{pin: "9012", noRepeat: true, noSequence: false}, // no wraparound, but 012 counts up
+		{pin: "2468", noRepeat: true, noSequence: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pin, func(t *testing.T) {
+			digits := make([]int, len(tt.pin))
+			for i, r := range tt.pin {
+				digits[i] = int(r - '0')
+			}
+			assert.Equal(t, tt.noRepeat, pinAllowed(digits, Options{NoRepeat: true}))
+			assert.Equal(t, tt.noSequence, pinAllowed(digits, Options{NoSequence: true}))
+		})
+	}
+}
+
+func TestPINCount(t *testing.T) {
+	// Brute force every 4- and 5-digit PIN against the counting DP
+	for _, length := range []int{4, 5} {
+		for _, rules := range []Options{{}, {NoRepeat: true}, {NoSequence: true}, {NoRepeat: true, NoSequence: true}} {
+			rules.PINLength = length
+
+			var want uint64
+			digits := make([]int, length)
+			for n := range int(math.Pow10(length)) {
+				for i, v := length-1, n; i >= 0; i, v = i-1, v/10 {
+					digits[i] = v % 10
+				}
+				if pinAllowed(digits, rules) {
+					want++
+				}
+			}
+			assert.Equal(t, want, pinCount(rules), "length %d, %+v", length, rules)
+		}
+	}
+
+	assert.Equal(t, uint64(1_000_000), pinCount(Options{PINLength: 6}))
+	assert.Equal(t, uint64(10*9*9*9), pinCount(Options{PINLength: 4, NoRepeat: true}))
+}
+
+func TestCharsetCount(t *testing.T) {
+	// Brute force strings of length 4 over classes of 2, 3 and 1 characters
+	sizes := []int{2, 3, 1}
+	class := func(c int) int {
+		switch {
+		case c < 2:
+			return 0
+		case c < 5:
+			return 1
+		default:
+			return 2
+		}
+	}
+
+	var want int64
+	for n := range 6 * 6 * 6 * 6 {
+		seen := 0
+		for v, i := n, 0; i < 4; v, i = v/6, i+1 {
+			seen |= 1 << class(v%6)
+		}
+		if seen == 0b111 {
+			want++
+		}
+	}
+	assert.Equal(t, want, charsetCount(sizes, 4).Int64())
+
+	// A single class has no constraint
+	assert.Equal(t, int64(1e8), charsetCount([]int{10}, 8).Int64())
+}
+
+func TestGenerateToken(t *testing.T) {
+	gen := setupTestGenerator(t)
+
+	tests := []struct {
+		encoding TokenEncoding
+		length   int
+		decode   func(string) ([]byte, error)
+	}{
+		{EncodingHex, 64, hex.DecodeString},
+		{EncodingBase32, 52, base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString},
+		{EncodingBase64URL, 43, base64.RawURLEncoding.DecodeString},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.encoding.String(), func(t *testing.T) {
+			token, err := gen.Generate(Options{Strategy: StrategyToken, TokenBits: 256, TokenEncoding: tt.encoding})
+			require.NoError(t, err)
+			assert.Len(t, token, tt.length)
+
+			data, err := tt.decode(token)
+			require.NoError(t, err)
+			assert.Len(t, data, 32)
+		})
+	}
+}
+
+func TestGenerateStrategySeeded(t *testing.T) {
+	gen := setupTestGenerator(t)
+	gen.SetRandomSource(security.NewInsecureSeededSource("golden"))
+
+	first, err := gen.Generate(Options{Strategy: StrategyCharset, Length: 16, Classes: ClassAll})
+	require.NoError(t, err)
+
+	gen.SetRandomSource(security.NewInsecureSeededSource("golden"))
+	second, err := gen.Generate(Options{Strategy: StrategyCharset, Length: 16, Classes: ClassAll})
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+}
+
+func TestStrategyEntropy(t *testing.T) {
+	gen := setupTestGenerator(t)
+
+	tests := []struct {
+		name string
+		opts Options
+		bits float64
+	}{
+		{name: "digits only", opts: Options{Strategy: StrategyCharset, Length: 10, Classes: ClassDigits}, bits: 10 * math.Log2(10)},
+		{name: "no ambiguous digits", opts: Options{Strategy: StrategyCharset, Length: 10, Classes: ClassDigits, NoAmbiguous: true}, bits: 10 * math.Log2(8)},
+		{name: "pin", opts: Options{Strategy: StrategyPIN, PINLength: 6}, bits: 6 * math.Log2(10)},
+		{name: "pin without repeats", opts: Options{Strategy: StrategyPIN, PINLength: 4, NoRepeat: true}, bits: math.Log2(10 * 9 * 9 * 9)},
+		{name: "token", opts: Options{Strategy: StrategyToken, TokenBits: 192}, bits: 192},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := gen.EntropyBreakdown(tt.opts)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.bits, b.Total, 1e-9)
+			assert.InDelta(t, tt.bits, b.Characters, 1e-9)
+			assert.Zero(t, b.Words)
+			assert.True(t, b.Exact)
+		})
+	}
+
+	// Requiring every class costs a little entropy against the plain alphabet
+	b, err := gen.EntropyBreakdown(Options{Strategy: StrategyCharset, Length: 16, Classes: ClassAll})
+	require.NoError(t, err)
+	assert.Less(t, b.Total, 16*math.Log2(86))
+	assert.Greater(t, b.Total, 16*math.Log2(86)-1)
+}
+
+func TestResolveTargetStrategy(t *testing.T) {
+	gen := setupTestGenerator(t)
+
+	resolved, err := gen.ResolveTarget(Options{Strategy: StrategyCharset, Length: 8, Classes: ClassDigits, TargetEntropyBits: 40})
+	require.NoError(t, err)
+	assert.Equal(t, 13, resolved.Length) // 12 digits give 39.9 bits
+
+	resolved, err = gen.ResolveTarget(Options{Strategy: StrategyPIN, PINLength: 4, TargetEntropyBits: 20})
+	require.NoError(t, err)
+	assert.Equal(t, 7, resolved.PINLength)
+
+	resolved, err = gen.ResolveTarget(Options{Strategy: StrategyToken, TokenBits: 64, TargetEntropyBits: 100})
+	require.NoError(t, err)
+	assert.Equal(t, 104, resolved.TokenBits)
+
+	_, err = gen.ResolveTarget(Options{Strategy: StrategyPIN, PINLength: 4, TargetEntropyBits: 60})
+	require.ErrorIs(t, err, ErrTargetUnreachable)
+}
+
+func TestDicePlanStrategy(t *testing.T) {
+	gen := setupTestGenerator(t)
+	_, err := gen.DicePlan(Options{Strategy: StrategyPIN, PINLength: 6})
+	require.ErrorIs(t, err, ErrDiceUnsupported)
+}
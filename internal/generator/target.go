@@ -0,0 +1,135 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Bounds for target entropy mode
+const (
+	MaxTargetEntropyBits = 512 // largest accepted TargetEntropyBits
+	MaxTargetWordCount   = 64  // most words ResolveTarget will choose
+)
+
+// resolvedTarget caches the options chosen for one target entropy request
+type resolvedTarget struct {
+	listsVersion      uint64
+	exclusionsVersion uint64
+	request           Options
+	resolved          Options
+}
+
+// suffixChoice is a candidate number of digits and special characters
+type suffixChoice struct {
+	digits, specials int
+}
+
+// ResolveTarget returns opts with the word count chosen to reach
+// opts.TargetEntropyBits with the actual filtered pools, along with the digit
+// and special character counts when AddNumbers or AddSpecial is set. It prefers
+// the fewest words, then the shortest suffix. Options without a target are
+// returned unchanged. The last resolution is cached until the loaded lists or
+// exclusions change. Other strategies get the shortest length, the fewest
+// token bits or the fewest pronounceable words that reaches the target.
+func (g *Generator) ResolveTarget(opts Options) (Options, error) {
+	if opts.TargetEntropyBits <= 0 {
+		return opts, nil
+	}
+	if err := opts.Validate(); err != nil {
+		return Options{}, err
+	}
+
+	listsVersion, exclusionsVersion := g.manager.Version(), g.exclusions.Version()
+	if t := g.target.Load(); t != nil && t.request == opts && t.listsVersion == listsVersion && t.exclusionsVersion == exclusionsVersion {
+		return t.resolved, nil
+	}
+
+	resolved, err := g.resolveTarget(opts)
+	if err != nil {
+		return Options{}, err
+	}
+
+	g.target.Store(&resolvedTarget{
+		listsVersion:      listsVersion,
+		exclusionsVersion: exclusionsVersion,
+		request:           opts,
+		resolved:          resolved,
+	})
+	return resolved, nil
+}
+
+// resolveTarget searches word counts from 3 upwards, trying every allowed suffix
+// for each, and returns the first combination that reaches the target
+func (g *Generator) resolveTarget(opts Options) (Options, error) {
+	if !opts.UsesWordlists() {
+		return g.resolveStrategyTarget(opts)
+	}
+
+	suffixes := suffixChoices(opts)
+
+	var positionBits []float64
+	var best float64
+	for words := 3; words <= MaxTargetWordCount; words++ {
+		candidate := opts
+		candidate.WordCount = words
+
+		// Positions only change while the word count limits the list count
+		if len(positionBits) != candidate.listCount() {
+			positions, exact, err := g.positionPools(candidate)
+			if err != nil {
+				return Options{}, err
+			}
+			positionBits = make([]float64, len(positions))
+			for pos, pools := range positions {
+				slot := slotEntropy(pools, opts, exact)
+				positionBits[pos] = slot.Bits + slot.Capitalization + slot.Substitution
+			}
+		}
+
+		var wordBits float64
+		for i := range words {
+			wordBits += positionBits[i%len(positionBits)]
+		}
+
+		for _, suffix := range suffixes {
+			if opts.AddNumbers {
+				candidate.NumberCount = suffix.digits
+			}
+			if opts.AddSpecial {
+				candidate.SpecialCount = suffix.specials
+			}
+
+			digits, specials := suffixBits(candidate)
+			total := wordBits + digits + specials
+			if total >= opts.TargetEntropyBits {
+				return candidate, nil
+			}
+			best = max(best, total)
+		}
+	}
+
+	return Options{}, fmt.Errorf("%w: %.1f bits needs more than %d words (at most %.1f bits with these wordlists)",
+		ErrTargetUnreachable, opts.TargetEntropyBits, MaxTargetWordCount, best)
+}
+
+// suffixChoices returns the allowed digit and special character counts, shortest first
+func suffixChoices(opts Options) []suffixChoice {
+	digits, specials := []int{0}, []int{0}
+	if opts.AddNumbers {
+		digits = []int{1, 2, 3, 4}
+	}
+	if opts.AddSpecial {
+		specials = []int{1, 2, 3, 4}
+	}
+
+	var choices []suffixChoice
+	for _, d := range digits {
+		for _, s := range specials {
+			choices = append(choices, suffixChoice{digits: d, specials: s})
+		}
+	}
+	slices.SortStableFunc(choices, func(a, b suffixChoice) int {
+		return (a.digits + a.specials) - (b.digits + b.specials)
+	})
+	return choices
+}
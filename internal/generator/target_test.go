@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveTarget(t *testing.T) {
+	// Two lists of 8 words carry 3 bits per word
+	gen := setupSizedGenerator(t, 8, 8)
+
+	tests := []struct {
+		name     string
+		opts     Options
+		words    int
+		digits   int
+		specials int
+	}{
+		{
+			name:  "words only",
+			opts:  Options{MinWordlists: 2, TargetEntropyBits: 20},
+			words: 7,
+		},
+		{
+			name:  "beyond twelve words",
+			opts:  Options{MinWordlists: 2, TargetEntropyBits: 40},
+			words: 14,
+		},
+		{
+			name:   "digits before more words",
+			opts:   Options{MinWordlists: 2, TargetEntropyBits: 20, AddNumbers: true, NumberCount: 1},
+			words:  3,
+			digits: 4,
+		},
+		{
+			name:     "shortest suffix",
+			opts:     Options{MinWordlists: 2, TargetEntropyBits: 16, AddNumbers: true, NumberCount: 4, AddSpecial: true, SpecialCount: 4},
+			words:    3,
+			digits:   1,
+			specials: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.WordCount = 6
+			tt.opts.Separator = SepSpace
+			resolved, err := gen.ResolveTarget(tt.opts)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.words, resolved.WordCount)
+			if tt.opts.AddNumbers {
+				assert.Equal(t, tt.digits, resolved.NumberCount)
+			}
+			if tt.opts.AddSpecial {
+				assert.Equal(t, tt.specials, resolved.SpecialCount)
+			}
+
+			bits, err := gen.EstimateEntropy(resolved)
+			require.NoError(t, err)
+			assert.GreaterOrEqual(t, bits, tt.opts.TargetEntropyBits)
+
+			password, err := gen.Generate(tt.opts)
+			require.NoError(t, err)
+			assert.Len(t, strings.Fields(password), tt.words)
+		})
+	}
+}
+
+func TestResolveTargetUnreachable(t *testing.T) {
+	gen := setupSizedGenerator(t, 8, 8)
+
+	_, err := gen.ResolveTarget(Options{WordCount: 6, MinWordlists: 2, TargetEntropyBits: 500})
+	assert.ErrorIs(t, err, ErrTargetUnreachable)
+}
+
+func TestResolveTargetTracksPools(t *testing.T) {
+	gen := setupSizedGenerator(t, 8, 8)
+	opts := Options{WordCount: 6, MinWordlists: 1, TargetEntropyBits: 18}
+
+	resolved, err := gen.ResolveTarget(opts)
+	require.NoError(t, err)
+	assert.Equal(t, 6, resolved.WordCount)
+
+	// Halving both pools leaves 2 bits per word
+	gen.exclusions.Add("aaa", "aab", "aac", "aad", "baa", "bab", "bac", "bad")
+	resolved, err = gen.ResolveTarget(opts)
+	require.NoError(t, err)
+	assert.Equal(t, 9, resolved.WordCount)
+}
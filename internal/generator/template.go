@@ -0,0 +1,286 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/greysquirr3l/glyphic/internal/security"
+)
+
+// NumberTag is the template slot filled with a number from MinTemplateNumber
+// to MaxTemplateNumber rather than a tagged word, as in "{num} {adj} {noun}s"
+const NumberTag = "num"
+
+// Range of the numbers drawn for {num} slots
+const (
+	MinTemplateNumber = 2
+	MaxTemplateNumber = 99
+)
+
+var (
+	ErrInvalidTemplate     = errors.New("invalid passphrase template")
+	ErrUnknownTag          = errors.New("no loaded wordlist has words with this tag")
+	ErrTemplateUnsupported = errors.New("option cannot be used with a passphrase template")
+)
+
+// templatePart is literal text or, when tag is set, a slot drawn from the
+// words with that part-of-speech tag
+type templatePart struct {
+	text string
+	tag  string
+}
+
+// parseTemplate splits a pattern such as "{adj} {noun} {verb} {adv}" into
+// literal text and tagged slots. Tags are lowercase ASCII letters; "{{" and
+// "}}" stand for literal braces.
+func parseTemplate(pattern string) ([]templatePart, error) {
+	var parts []templatePart
+	var literal strings.Builder
+	slots := 0
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '{' && strings.HasPrefix(pattern[i:], "{{"), c == '}' && strings.HasPrefix(pattern[i:], "}}"):
+			literal.WriteByte(c)
+			i++
+		case c == '{':
+			end := strings.IndexByte(pattern[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unclosed { at offset %d", ErrInvalidTemplate, i)
+			}
+			tag := strings.ToLower(pattern[i+1 : i+end])
+			if tag == "" || strings.Trim(tag, "abcdefghijklmnopqrstuvwxyz") != "" {
+				return nil, fmt.Errorf("%w: bad tag {%s}", ErrInvalidTemplate, pattern[i+1:i+end])
+			}
+			if literal.Len() > 0 {
+				parts = append(parts, templatePart{text: literal.String()})
+				literal.Reset()
+			}
+			parts = append(parts, templatePart{tag: tag})
+			slots++
+			i += end
+		case c == '}':
+			return nil, fmt.Errorf("%w: unmatched } at offset %d", ErrInvalidTemplate, i)
+		default:
+			literal.WriteByte(c)
+		}
+	}
+
+	if literal.Len() > 0 {
+		parts = append(parts, templatePart{text: literal.String()})
+	}
+	if slots == 0 {
+		return nil, fmt.Errorf("%w: no {tag} slots", ErrInvalidTemplate)
+	}
+	return parts, nil
+}
+
+// validateTemplate checks the options of a template passphrase. The word count
+// and wordlist selection settings are ignored.
+func (o *Options) validateTemplate() error {
+	if _, err := parseTemplate(o.Template); err != nil {
+		return err
+	}
+
+	switch {
+	case o.TargetEntropyBits > 0:
+		return fmt.Errorf("%w: target entropy", ErrTemplateUnsupported)
+	case o.Policy != nil:
+		return fmt.Errorf("%w: password policies", ErrTemplateUnsupported)
+	case o.AddNumbers && (o.NumberCount < 1 || o.NumberCount > 4):
+		return ErrInvalidNumberCount
+	case o.AddSpecial && (o.SpecialCount < 1 || o.SpecialCount > 4):
+		return ErrInvalidSpecialCount
+	}
+	return o.validateTransforms()
+}
+
+// numberPool holds the strings a {num} slot can produce
+var numberPool = func() []string {
+	pool := make([]string, 0, MaxTemplateNumber-MinTemplateNumber+1)
+	for n := MinTemplateNumber; n <= MaxTemplateNumber; n++ {
+		pool = append(pool, strconv.Itoa(n))
+	}
+	return pool
+}()
+
+// tagPool returns the exclusion-filtered words tagged with tag across every
+// loaded list, or the numbers for NumberTag. The returned slice is shared and
+// must not be modified.
+func (g *Generator) tagPool(tag string) ([]string, error) {
+	if tag == NumberTag {
+		return numberPool, nil
+	}
+
+	words := g.filteredPool(poolKey{tag: tag}, nil)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("%w: {%s}", ErrUnknownTag, tag)
+	}
+	return words, nil
+}
+
+// generateTemplate fills every slot of opts.Template from its pool, applies the
+// capitalization and substitutions to the word slots and places the digits and
+// special characters, treating every slot and literal as a unit
+func (g *Generator) generateTemplate(src security.RandomSource, opts Options) (string, error) {
+	parts, err := parseTemplate(opts.Template)
+	if err != nil {
+		return "", err
+	}
+
+	var words []string
+	for _, part := range parts {
+		if part.tag == "" || part.tag == NumberTag {
+			continue
+		}
+		pool, err := g.tagPool(part.tag)
+		if err != nil {
+			return "", err
+		}
+		idx, err := security.RandomIndex(src, len(pool))
+		if err != nil {
+			return "", fmt.Errorf("failed to select random word: %w", err)
+		}
+		words = append(words, pool[idx])
+	}
+
+	words, err = applyCapitalization(src, words, opts.Capitalization, opts.caseRules())
+	if err != nil {
+		return "", fmt.Errorf("failed to apply capitalization: %w", err)
+	}
+	words, err = substitute(src, words, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to apply substitutions: %w", err)
+	}
+
+	units := make([]string, len(parts))
+	for i, part := range parts {
+		switch part.tag {
+		case "":
+			units[i] = part.text
+		case NumberTag:
+			idx, err := security.RandomIndex(src, len(numberPool))
+			if err != nil {
+				return "", fmt.Errorf("failed to select number: %w", err)
+			}
+			units[i] = numberPool[idx]
+		default:
+			units[i] = words[0]
+			words = words[1:]
+		}
+	}
+
+	return placeExtras(src, units, opts)
+}
+
+// templateBreakdown credits every slot with log2 of its pool. Word slots count
+// towards Words and {num} slots towards Digits. Neighbouring slots whose text
+// can run together are reported as warnings, like joined words.
+func (g *Generator) templateBreakdown(opts Options) (*EntropyBreakdown, error) {
+	parts, err := parseTemplate(opts.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &EntropyBreakdown{Exact: true}
+	wordSlot := 0
+	units := len(parts)
+	if opts.Placement == PlaceAnywhere {
+		units = 0
+	}
+	var literals strings.Builder
+	fixed := opts.substitutes()
+	for i, part := range parts {
+		if part.tag == "" {
+			literals.WriteString(part.text)
+			if opts.Placement == PlaceAnywhere {
+				units += utf8.RuneCountInString(part.text)
+			}
+			continue
+		}
+		pool, err := g.tagPool(part.tag)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Placement == PlaceAnywhere {
+			units += shortestWord(pool)
+		}
+
+		slot := SlotEntropy{
+			Position: len(b.Slots),
+			Pools:    []PoolShare{{ListID: "{" + part.tag + "}", Words: len(pool), Probability: 1}},
+			Bits:     math.Log2(float64(len(pool))),
+		}
+		if part.tag == NumberTag {
+			b.Digits += slot.Bits
+			fixed += "0123456789"
+		} else {
+			slot.Capitalization, slot.Substitution = transformBits(pool, opts)
+			b.Words += slot.Bits
+			b.Capitalization += slot.Capitalization
+			b.Substitutions += slot.Substitution
+		}
+		b.Slots = append(b.Slots, slot)
+
+		if warning, ok := g.templateJoin(parts, i, wordSlot, opts.compareMode(), opts.caseRules()); ok {
+			b.Warnings = append(b.Warnings, warning)
+		}
+		if part.tag != NumberTag {
+			wordSlot++
+		}
+	}
+
+	digits, specials := suffixBits(opts)
+	b.Digits += digits
+	b.Specials = specials
+	placement, warnings := placementBits(opts, units, literals.String()+fixed)
+	b.Placement = placement
+	b.Total = b.Words + b.Capitalization + b.Substitutions + b.Digits + b.Specials + b.Placement
+
+	b.Warnings = append(b.Warnings, substitutionWarnings(opts, literals.String())...)
+	b.Warnings = append(b.Warnings, warnings...)
+	return b, nil
+}
+
+// templateJoin checks whether the slot at parts[i] can run into the next slot
+// across the literal text between them. wordSlot is the capitalization index
+// of parts[i] when it is a word slot.
+func (g *Generator) templateJoin(parts []templatePart, i, wordSlot int, mode CapitalizationMode, rules unicode.SpecialCase) (string, bool) {
+	sep := ""
+	next := i + 1
+	if next < len(parts) && parts[next].tag == "" {
+		sep = parts[next].text
+		next++
+	}
+	if next >= len(parts) {
+		return "", false
+	}
+
+	forms := func(part templatePart, slot int) []string {
+		pool, _ := g.tagPool(part.tag)
+		if part.tag == NumberTag {
+			return pool
+		}
+		return caseForms(pool, mode, slot, rules)
+	}
+
+	rightSlot := wordSlot
+	if parts[i].tag != NumberTag {
+		rightSlot++
+	}
+	if mode == CapRandom {
+		sep = strings.ToLower(sep)
+	}
+
+	c, ok := joinCollision(forms(parts[i], wordSlot), forms(parts[next], rightSlot), sep)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("slots {%s} and {%s} can join ambiguously: %q+%q and %q+%q both give %q; put a separator between them",
+		parts[i].tag, parts[next].tag, c.left1, c.right1, c.left2, c.right2, c.left1+sep+c.right1), true
+}
@@ -0,0 +1,163 @@
+package generator
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/greysquirr3l/glyphic/internal/wordlist"
+)
+
+// setupTaggedGenerator returns a generator over one tagged list with 4
+// adjectives, 8 nouns, 2 verbs and 4 adverbs
+func setupTaggedGenerator(t *testing.T) *Generator {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "tagged.txt")
+	data := "brave adj\ncalm adj\neager adj\nfancy adj\n" +
+		"otter noun\nbadger noun\nheron noun\nmoth noun\nwren noun\nnewt noun\ntoad noun\nlynx noun\n" +
+		"swims verb\nsings verb\n" +
+		"slowly adv\nloudly adv\ngently adv\nbadly adv\n"
+	require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+
+	manager, err := wordlist.NewManager(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, manager.AddUserWordlist(path, "tagged"))
+
+	return New(manager, wordlist.NewExclusionList(false))
+}
+
+func TestParseTemplate(t *testing.T) {
+	tests := []struct {
+		pattern string
+		parts   []templatePart
+		err     bool
+	}{
+		{pattern: "{adj} {noun}", parts: []templatePart{{tag: "adj"}, {text: " "}, {tag: "noun"}}},
+		{pattern: "{num} {ADJ} {noun}s", parts: []templatePart{{tag: "num"}, {text: " "}, {tag: "adj"}, {text: " "}, {tag: "noun"}, {text: "s"}}},
+		{pattern: "{{{noun}}}", parts: []templatePart{{text: "{"}, {tag: "noun"}, {text: "}"}}},
+		{pattern: "no slots", err: true},
+		{pattern: "{adj", err: true},
+		{pattern: "adj}", err: true},
+		{pattern: "{}", err: true},
+		{pattern: "{ad j}", err: true},
+		{pattern: "{noun2}", err: true},
+		{pattern: "", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			parts, err := parseTemplate(tt.pattern)
+			if tt.err {
+				require.ErrorIs(t, err, ErrInvalidTemplate)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.parts, parts)
+		})
+	}
+}
+
+func TestGenerateTemplate(t *testing.T) {
+	gen := setupTaggedGenerator(t)
+	pattern := regexp.MustCompile(`^([2-9]|[1-9][0-9]) (Brave|Calm|Eager|Fancy) (Otter|Badger|Heron|Moth|Wren|Newt|Toad|Lynx)s (Swims|Sings) (Slowly|Loudly|Gently|Badly)!$`)
+
+	opts := Options{Template: "{num} {adj} {noun}s {verb} {adv}!", Capitalization: CapFirst}
+	for range 100 {
+		password, err := gen.Generate(opts)
+		require.NoError(t, err)
+		assert.Regexp(t, pattern, password)
+	}
+}
+
+func TestGenerateTemplateAlternatingSkipsLiterals(t *testing.T) {
+	gen := setupTaggedGenerator(t)
+
+	password, err := gen.Generate(Options{Template: "the {adj} {noun}", Capitalization: CapAlternating, AddNumbers: true, NumberCount: 2})
+	require.NoError(t, err)
+	assert.Regexp(t, `^the [A-Z]+ [a-z]+[0-9]{2}$`, password)
+}
+
+func TestTemplateEntropy(t *testing.T) {
+	gen := setupTaggedGenerator(t)
+
+	b, err := gen.EntropyBreakdown(Options{Template: "{num} {adj} {noun}s {verb} {adv}", AddSpecial: true, SpecialCount: 1})
+	require.NoError(t, err)
+
+	require.Len(t, b.Slots, 5)
+	assert.Equal(t, "{num}", b.Slots[0].Pools[0].ListID)
+	assert.Equal(t, 98, b.Slots[0].Pools[0].Words)
+	assert.Equal(t, 8, b.Slots[2].Pools[0].Words)
+
+	assert.InDelta(t, 2+3+1+2, b.Words, 1e-9)
+	assert.InDelta(t, math.Log2(98), b.Digits, 1e-9)
+	assert.InDelta(t, math.Log2(float64(len(SpecialChars))), b.Specials, 1e-9)
+	assert.InDelta(t, b.Words+b.Digits+b.Specials, b.Total, 1e-9)
+	assert.Empty(t, b.Warnings)
+
+	// Exclusions shrink the pools
+	gen.exclusions.Add("otter", "badger", "heron", "moth")
+	b, err = gen.EntropyBreakdown(Options{Template: "{noun}"})
+	require.NoError(t, err)
+	assert.InDelta(t, 2, b.Total, 1e-9)
+}
+
+func TestTemplateJoinWarning(t *testing.T) {
+	gen := setupTaggedGenerator(t)
+
+	// No adjective and noun pair splits two ways, but "2"+"34" and "23"+"4" do
+	b, err := gen.EntropyBreakdown(Options{Template: "{num}{num} {adj}{noun}"})
+	require.NoError(t, err)
+	require.Len(t, b.Warnings, 1)
+	assert.Contains(t, b.Warnings[0], "{num} and {num}")
+}
+
+func TestTemplateErrors(t *testing.T) {
+	gen := setupTaggedGenerator(t)
+
+	tests := []struct {
+		name string
+		opts Options
+		err  error
+	}{
+		{name: "unknown tag", opts: Options{Template: "{adj} {pronoun}"}, err: ErrUnknownTag},
+		{name: "syntax", opts: Options{Template: "{adj"}, err: ErrInvalidTemplate},
+		{name: "target", opts: Options{Template: "{adj}", TargetEntropyBits: 40}, err: ErrTemplateUnsupported},
+		{name: "policy", opts: Options{Template: "{adj}", Policy: &PolicyAD}, err: ErrTemplateUnsupported},
+		{name: "number count", opts: Options{Template: "{adj}", AddNumbers: true}, err: ErrInvalidNumberCount},
+		{name: "strategy", opts: Options{Template: "{adj}", Strategy: StrategyPIN, PINLength: 6}, err: ErrStrategyOption},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := gen.Generate(tt.opts)
+			require.ErrorIs(t, err, tt.err)
+			_, err = gen.EntropyBreakdown(tt.opts)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	_, err := gen.DicePlan(Options{Template: "{adj}"})
+	require.ErrorIs(t, err, ErrDiceUnsupported)
+}
+
+func TestTemplatePoolTracksWordlists(t *testing.T) {
+	gen := setupTaggedGenerator(t)
+
+	b, err := gen.EntropyBreakdown(Options{Template: "{adj}"})
+	require.NoError(t, err)
+	assert.InDelta(t, 2, b.Total, 1e-9)
+
+	path := filepath.Join(t.TempDir(), "more.txt")
+	require.NoError(t, os.WriteFile(path, []byte("bold adj\ndeft adj\nglad adj\nkeen adj\n"), 0600))
+	require.NoError(t, gen.manager.AddUserWordlist(path, "more"))
+
+	b, err = gen.EntropyBreakdown(Options{Template: "{adj}"})
+	require.NoError(t, err)
+	assert.InDelta(t, 3, b.Total, 1e-9)
+}
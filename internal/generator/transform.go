@@ -0,0 +1,415 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/greysquirr3l/glyphic/internal/security"
+)
+
+// Placement says where the digits and special characters of a passphrase go
+type Placement int
+
+const (
+	PlaceEnd      Placement = iota // appended after the last word
+	PlaceBetween                   // before, between or after whole words
+	PlaceAnywhere                  // at any character position, inside words too
+)
+
+var (
+	ErrUnknownSubstitutions = errors.New("unknown substitution table")
+	ErrInvalidSubstitutions = errors.New("invalid substitution table")
+	ErrUnknownPlacement     = errors.New("unknown placement")
+	ErrTransformUnsupported = errors.New("option cannot be used with substitutions or placement")
+)
+
+// placementNames maps placements to their CLI names
+var placementNames = map[Placement]string{
+	PlaceEnd:      "end",
+	PlaceBetween:  "between",
+	PlaceAnywhere: "anywhere",
+}
+
+// String returns the CLI name of the placement
+func (p Placement) String() string {
+	if name, ok := placementNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("Placement(%d)", int(p))
+}
+
+// ParsePlacement parses a CLI placement name
+func ParsePlacement(name string) (Placement, error) {
+	for p, n := range placementNames {
+		if strings.EqualFold(n, name) {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnknownPlacement, name)
+}
+
+// Substitutions maps lowercase letters to the characters that may replace them
+// after capitalization, such as 'e' to "3". Substitutes must not be letters or
+// spaces and no character may substitute two letters, so every transformed
+// word can be read back.
+type Substitutions struct {
+	Name  string
+	Table map[rune]string
+}
+
+// Built-in substitution tables
+var (
+	// SubstitutionsLeet is the common leetspeak table
+	SubstitutionsLeet = Substitutions{Name: "leet", Table: map[rune]string{
+		'a': "4@", 'b': "8", 'e': "3", 'g': "9", 'i': "1!", 'o': "0", 's': "5$", 't': "7", 'z': "2",
+	}}
+
+	// SubstitutionsSafe leaves out substitutes that look like letters or like
+	// each other, such as 0 and O or 1, l and I
+	SubstitutionsSafe = Substitutions{Name: "safe", Table: map[rune]string{
+		'a': "4@", 'e': "3", 'h': "#", 'i': "!", 's': "$", 't': "7",
+	}}
+)
+
+// substitutionPresets maps built-in table names to their tables
+var substitutionPresets = map[string]*Substitutions{
+	SubstitutionsLeet.Name: &SubstitutionsLeet,
+	SubstitutionsSafe.Name: &SubstitutionsSafe,
+}
+
+// SubstitutionNames returns the sorted names of the built-in tables
+func SubstitutionNames() []string {
+	return slices.Sorted(maps.Keys(substitutionPresets))
+}
+
+// ParseSubstitutions returns a copy of the built-in table with the given name,
+// or parses a custom table such as "a=4@,e=3,s=$"
+func ParseSubstitutions(spec string) (*Substitutions, error) {
+	if preset, ok := substitutionPresets[strings.ToLower(spec)]; ok {
+		s := *preset
+		s.Table = maps.Clone(preset.Table)
+		return &s, nil
+	}
+	if !strings.Contains(spec, "=") {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownSubstitutions, spec)
+	}
+
+	s := &Substitutions{Table: make(map[rune]string)}
+	for entry := range strings.SplitSeq(spec, ",") {
+		letter, subs, ok := strings.Cut(entry, "=")
+		if !ok || utf8.RuneCountInString(letter) != 1 || subs == "" {
+			return nil, fmt.Errorf("%w: entry %q is not letter=substitutes", ErrInvalidSubstitutions, entry)
+		}
+		r, _ := utf8.DecodeRuneInString(letter)
+		r = unicode.ToLower(r)
+		if _, dup := s.Table[r]; dup {
+			return nil, fmt.Errorf("%w: %q is listed twice", ErrInvalidSubstitutions, letter)
+		}
+		s.Table[r] = subs
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	s.Name = s.String()
+	return s, nil
+}
+
+// String returns the name of a built-in table, or the canonical spec of a
+// custom one
+func (s *Substitutions) String() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	entries := make([]string, 0, len(s.Table))
+	for _, letter := range slices.Sorted(maps.Keys(s.Table)) {
+		entries = append(entries, string(letter)+"="+s.Table[letter])
+	}
+	return strings.Join(entries, ",")
+}
+
+// Validate checks that every transformed word can be read back: keys are
+// lowercase letters, substitutes are neither letters nor spaces, and no
+// substitute is shared between letters
+func (s *Substitutions) Validate() error {
+	if len(s.Table) == 0 {
+		return fmt.Errorf("%w: no substitutions", ErrInvalidSubstitutions)
+	}
+
+	owner := make(map[rune]rune)
+	for letter, subs := range s.Table {
+		if !unicode.IsLetter(letter) || unicode.ToLower(letter) != letter {
+			return fmt.Errorf("%w: %q is not a lowercase letter", ErrInvalidSubstitutions, letter)
+		}
+		if subs == "" {
+			return fmt.Errorf("%w: %q has no substitutes", ErrInvalidSubstitutions, letter)
+		}
+		for _, r := range subs {
+			switch prev, dup := owner[r]; {
+			case unicode.IsLetter(r), unicode.IsSpace(r), !unicode.IsPrint(r):
+				return fmt.Errorf("%w: %q cannot substitute a letter", ErrInvalidSubstitutions, r)
+			case dup:
+				return fmt.Errorf("%w: %q substitutes both %q and %q", ErrInvalidSubstitutions, r, prev, letter)
+			}
+			owner[r] = letter
+		}
+	}
+	return nil
+}
+
+// substitutes returns every character the table of opts can produce
+func (o *Options) substitutes() string {
+	if o.Substitutions == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, letter := range slices.Sorted(maps.Keys(o.Substitutions.Table)) {
+		sb.WriteString(o.Substitutions.Table[letter])
+	}
+	return sb.String()
+}
+
+// transforms reports whether any substitution or placement is configured
+func (o *Options) transforms() bool {
+	return o.Substitutions != nil || o.Placement != PlaceEnd
+}
+
+// validateTransforms checks the substitution table and placement. Policies
+// build their passwords by construction and cannot be combined with either.
+func (o *Options) validateTransforms() error {
+	if !o.transforms() {
+		return nil
+	}
+	if o.Policy != nil {
+		return fmt.Errorf("%w: password policies", ErrTransformUnsupported)
+	}
+	if _, ok := placementNames[o.Placement]; !ok {
+		return fmt.Errorf("%w: %d", ErrUnknownPlacement, o.Placement)
+	}
+	if o.Substitutions != nil {
+		return o.Substitutions.Validate()
+	}
+	return nil
+}
+
+// substitute applies the substitution table to words. Each letter in the table
+// becomes one of itself and its substitutes, drawn uniformly from src, or its
+// first substitute with SubstituteAll.
+func substitute(src security.RandomSource, words []string, opts Options) ([]string, error) {
+	if opts.Substitutions == nil {
+		return words, nil
+	}
+
+	result := make([]string, len(words))
+	for i, word := range words {
+		runes := []rune(word)
+		for j, r := range runes {
+			subs := []rune(opts.Substitutions.Table[unicode.ToLower(r)])
+			switch {
+			case len(subs) == 0:
+				continue
+			case opts.SubstituteAll:
+				runes[j] = subs[0]
+			default:
+				idx, err := security.RandomIndex(src, len(subs)+1)
+				if err != nil {
+					return nil, err
+				}
+				if idx > 0 {
+					runes[j] = subs[idx-1]
+				}
+			}
+		}
+		result[i] = string(runes)
+	}
+	return result, nil
+}
+
+// placeExtras draws the digits and special characters of opts and places them.
+// units are the pieces of the passphrase that stay whole with PlaceBetween,
+// such as the words with their leading separators; PlaceAnywhere splits them
+// into characters. Every arrangement of units, digits and specials that keeps
+// each group in order is equally likely.
+func placeExtras(src security.RandomSource, units []string, opts Options) (string, error) {
+	var numbers, specials string
+	var err error
+	if opts.AddNumbers {
+		if numbers, err = generateRandomNumbers(src, opts.NumberCount); err != nil {
+			return "", fmt.Errorf("failed to generate numbers: %w", err)
+		}
+	}
+	if opts.AddSpecial {
+		if specials, err = generateRandomSpecialChars(src, opts.SpecialCount); err != nil {
+			return "", fmt.Errorf("failed to generate special characters: %w", err)
+		}
+	}
+
+	base := strings.Join(units, "")
+	if opts.Placement == PlaceEnd || numbers+specials == "" {
+		return base + numbers + specials, nil
+	}
+
+	if opts.Placement == PlaceAnywhere {
+		units = strings.Split(base, "")
+	}
+	extras := strings.Split(numbers+specials, "")
+
+	// Shuffling the slot kinds gives a uniform arrangement of the three groups
+	kinds := make([]int, len(units)+len(extras))
+	for i := range extras {
+		kinds[len(units)+i] = 1
+		if i >= len(numbers) {
+			kinds[len(units)+i] = 2
+		}
+	}
+	if err := security.Shuffle(src, kinds); err != nil {
+		return "", fmt.Errorf("failed to place digits and special characters: %w", err)
+	}
+
+	var sb strings.Builder
+	next := [3][]string{units, extras[:len(numbers)], extras[len(numbers):]}
+	for _, kind := range kinds {
+		sb.WriteString(next[kind][0])
+		next[kind] = next[kind][1:]
+	}
+	return sb.String(), nil
+}
+
+// transformBits returns the mean capitalization and substitution bits per word
+// of pool. CapRandom flips a fair coin for every letter with distinct upper and
+// lower case forms, adding one bit. A letter with k substitutes drawn at random
+// adds log2(k+1) bits and only keeps its coin flip when it stays a letter, so
+// it contributes 1/(k+1) capitalization bits. SubstituteAll is fixed and adds
+// nothing.
+func transformBits(pool []string, opts Options) (capBits, subBits float64) {
+	var table map[rune]string
+	if opts.Substitutions != nil {
+		table = opts.Substitutions.Table
+	}
+
+	for _, word := range pool {
+		for _, r := range word {
+			lower := unicode.ToLower(r)
+			cased := opts.Capitalization == CapRandom && unicode.ToUpper(lower) != lower
+			k := utf8.RuneCountInString(table[lower])
+			switch {
+			case k == 0:
+				if cased {
+					capBits++
+				}
+			case opts.SubstituteAll:
+			default:
+				subBits += math.Log2(float64(k + 1))
+				if cased {
+					capBits += 1 / float64(k+1)
+				}
+			}
+		}
+	}
+	return capBits / float64(len(pool)), subBits / float64(len(pool))
+}
+
+// shortestWord returns the length in characters of the shortest word of pool
+func shortestWord(pool []string) int {
+	shortest := math.MaxInt
+	for _, word := range pool {
+		shortest = min(shortest, utf8.RuneCountInString(word))
+	}
+	return shortest
+}
+
+// wordUnits returns what placementBits arranges the extras of a diceware
+// passphrase among: its words, or its shortest possible length in characters
+// with PlaceAnywhere
+func wordUnits(positions [][]slotPool, opts Options) int {
+	if opts.Placement != PlaceAnywhere {
+		return opts.WordCount
+	}
+
+	length := (opts.WordCount - 1) * utf8.RuneCountInString(getSeparator(opts.Separator, opts.CustomSep))
+	for i := range opts.WordCount {
+		shortest := math.MaxInt
+		for _, pool := range positions[i%len(positions)] {
+			shortest = min(shortest, shortestWord(pool.words))
+		}
+		length += shortest
+	}
+	return length
+}
+
+// placementBits credits the positions of the inserted digits and special
+// characters. units is the number of whole units with PlaceBetween and the
+// shortest possible passphrase length with PlaceAnywhere, so the figure is a
+// lower bound. fixed holds the non-letter characters the rest of the passphrase
+// can contain: a group whose characters also occur there cannot be located
+// reliably, so its positions are not credited and a warning is returned.
+func placementBits(opts Options, units int, fixed string) (float64, []string) {
+	if opts.Placement == PlaceEnd {
+		return 0, nil
+	}
+
+	var digits, specials int
+	if opts.AddNumbers {
+		digits = opts.NumberCount
+	}
+	if opts.AddSpecial {
+		specials = opts.SpecialCount
+	}
+
+	var warnings []string
+	digitsClear := true
+	if i := strings.IndexFunc(fixed, unicode.IsDigit); digits > 0 && i >= 0 {
+		digitsClear = false
+		r, _ := utf8.DecodeRuneInString(fixed[i:])
+		warnings = append(warnings, fmt.Sprintf("inserted digits can be mistaken for %q elsewhere in the passphrase; their positions are not credited and the total may be overstated", r))
+	}
+	specialsClear := true
+	if i := strings.IndexFunc(fixed, func(r rune) bool { return slices.Contains(SpecialChars, r) }); specials > 0 && i >= 0 {
+		specialsClear = false
+		r, _ := utf8.DecodeRuneInString(fixed[i:])
+		warnings = append(warnings, fmt.Sprintf("inserted special characters can be mistaken for %q elsewhere in the passphrase; their positions are not credited and the total may be overstated", r))
+	}
+
+	total := units + digits + specials
+	var bits float64
+	switch {
+	case digitsClear && specialsClear:
+		bits = log2Binomial(total, digits) + log2Binomial(units+specials, specials)
+	case digitsClear:
+		bits = log2Binomial(total, digits)
+	case specialsClear:
+		bits = log2Binomial(total, specials)
+	}
+	return bits, warnings
+}
+
+// substitutionWarnings reports substitutes that also occur in the fixed text
+// between words, which blurs where one word ends and the next begins
+func substitutionWarnings(opts Options, fixed string) []string {
+	if opts.Substitutions == nil {
+		return nil
+	}
+	i := strings.IndexAny(fixed, opts.substitutes())
+	if i < 0 {
+		return nil
+	}
+	r, _ := utf8.DecodeRuneInString(fixed[i:])
+	return []string{fmt.Sprintf("substitute %q also appears between words, so word boundaries can be ambiguous; use another separator", r)}
+}
+
+// log2Binomial returns log2 of n choose k
+func log2Binomial(n, k int) float64 {
+	if k <= 0 || k >= n {
+		return 0
+	}
+	ln := func(x int) float64 {
+		v, _ := math.Lgamma(float64(x + 1))
+		return v
+	}
+	return (ln(n) - ln(k) - ln(n-k)) / math.Ln2
+}
@@ -0,0 +1,319 @@
+package generator
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSubstitutions(t *testing.T) {
+	leet, err := ParseSubstitutions("LEET")
+	require.NoError(t, err)
+	assert.Equal(t, "leet", leet.String())
+	leet.Table['a'] = "x"
+	assert.Equal(t, "4@", SubstitutionsLeet.Table['a'], "presets are copied")
+
+	custom, err := ParseSubstitutions("s=$5,E=3")
+	require.NoError(t, err)
+	assert.Equal(t, map[rune]string{'s': "$5", 'e': "3"}, custom.Table)
+	assert.Equal(t, "e=3,s=$5", custom.String())
+
+	for _, name := range SubstitutionNames() {
+		s, err := ParseSubstitutions(name)
+		require.NoError(t, err)
+		require.NoError(t, s.Validate(), name)
+	}
+
+	tests := []struct {
+		spec string
+		err  error
+	}{
+		{"klingon", ErrUnknownSubstitutions},
+		{"a=4,e", ErrInvalidSubstitutions},
+		{"ab=4", ErrInvalidSubstitutions},
+		{"a=", ErrInvalidSubstitutions},
+		{"a=4,A=@", ErrInvalidSubstitutions},
+		{"a=4,e=4", ErrInvalidSubstitutions},
+		{"a=b", ErrInvalidSubstitutions},
+		{"a= ", ErrInvalidSubstitutions},
+		{"4=a", ErrInvalidSubstitutions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			_, err := ParseSubstitutions(tt.spec)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestParsePlacement(t *testing.T) {
+	for _, p := range []Placement{PlaceEnd, PlaceBetween, PlaceAnywhere} {
+		parsed, err := ParsePlacement(strings.ToUpper(p.String()))
+		require.NoError(t, err)
+		assert.Equal(t, p, parsed)
+	}
+	_, err := ParsePlacement("middle")
+	require.ErrorIs(t, err, ErrUnknownPlacement)
+}
+
+func TestSubstitute(t *testing.T) {
+	words := []string{"Password", "SESAME"}
+
+	all, err := substitute(nil, words, Options{Substitutions: &SubstitutionsLeet, SubstituteAll: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"P455w0rd", "5354M3"}, all)
+
+	none, err := substitute(nil, words, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, words, none)
+
+	// Every choice of a letter and its substitutes turns up
+	seen := make(map[string]bool)
+	opts := Options{Substitutions: &SubstitutionsLeet}
+	for range 300 {
+		out, err := substitute(nil, []string{"sat"}, opts)
+		require.NoError(t, err)
+		assert.Regexp(t, `^[s5$][a4@][t7]$`, out[0])
+		seen[out[0]] = true
+	}
+	assert.Len(t, seen, 3*3*2)
+}
+
+func TestPlaceExtras(t *testing.T) {
+	units := []string{"oak", " elm", " ash"}
+	opts := Options{AddNumbers: true, NumberCount: 1, Placement: PlaceBetween}
+
+	// A single digit lands in each of the four gaps equally often
+	counts := make(map[int]int)
+	for range 4000 {
+		out, err := placeExtras(nil, units, opts)
+		require.NoError(t, err)
+		i := strings.IndexFunc(out, unicode.IsDigit)
+		require.GreaterOrEqual(t, i, 0, out)
+		assert.Equal(t, "oak elm ash", out[:i]+out[i+1:])
+		counts[i]++
+	}
+	assert.Equal(t, []int{0, 3, 7, 11}, slices.Sorted(func(yield func(int) bool) {
+		for i := range counts {
+			if !yield(i) {
+				return
+			}
+		}
+	}))
+	for i, n := range counts {
+		assert.InDelta(t, 1000, n, 200, "gap at %d", i)
+	}
+
+	// PlaceAnywhere reaches inside words
+	opts = Options{AddNumbers: true, NumberCount: 2, AddSpecial: true, SpecialCount: 2, Placement: PlaceAnywhere}
+	inside := false
+	for range 200 {
+		out, err := placeExtras(nil, units, opts)
+		require.NoError(t, err)
+		require.Len(t, out, 15)
+
+		var base strings.Builder
+		digits, specials := 0, 0
+		for _, r := range out {
+			switch {
+			case unicode.IsLetter(r), r == ' ':
+				base.WriteRune(r)
+			case unicode.IsDigit(r):
+				digits++
+			default:
+				specials++
+			}
+		}
+		assert.Equal(t, "oak elm ash", base.String())
+		assert.Equal(t, 2, digits, out)
+		assert.Equal(t, 2, specials, out)
+		for _, word := range strings.Fields(out) {
+			if strings.IndexFunc(word, unicode.IsLetter) >= 0 && !slices.Contains([]string{"oak", "elm", "ash"}, strings.Trim(word, "0123456789"+string(SpecialChars))) {
+				inside = true
+			}
+		}
+	}
+	assert.True(t, inside)
+
+	// PlaceEnd appends digits then specials as before
+	out, err := placeExtras(nil, units, Options{AddNumbers: true, NumberCount: 2, AddSpecial: true, SpecialCount: 1})
+	require.NoError(t, err)
+	assert.Regexp(t, `^oak elm ash[0-9]{2}[^0-9a-z ]$`, out)
+}
+
+func TestTransformEntropy(t *testing.T) {
+	gen := setupWordsGenerator(t, []string{"bat", "cat"}, []string{"dog", "hog"}, []string{"eel", "elk"})
+	base := Options{WordCount: 3, MinWordlists: 3, Separator: SepSpace}
+
+	tests := []struct {
+		name          string
+		change        func(*Options)
+		caps          float64
+		substitutions float64
+		placement     float64
+		warnings      int
+	}{
+		{
+			// bat, cat: 1.5+log2(3) bits; dog, hog: 2; eel, elk: 1.5
+			name:          "random leet",
+			change:        func(o *Options) { o.Substitutions = &SubstitutionsLeet },
+			substitutions: 5 + math.Log2(3),
+		},
+		{
+			// A substituted letter keeps its coin flip 1/(k+1) of the time
+			name:          "random leet with random case",
+			change:        func(o *Options) { o.Substitutions = &SubstitutionsLeet; o.Capitalization = CapRandom },
+			caps:          35.0 / 6,
+			substitutions: 5 + math.Log2(3),
+		},
+		{
+			name: "fixed leet with random case",
+			change: func(o *Options) {
+				o.Substitutions = &SubstitutionsLeet
+				o.SubstituteAll = true
+				o.Capitalization = CapRandom
+			},
+			caps: 3,
+		},
+		{
+			// Two digits and a special among three words
+			name: "between",
+			change: func(o *Options) {
+				o.Placement = PlaceBetween
+				o.AddNumbers, o.NumberCount, o.AddSpecial, o.SpecialCount = true, 2, true, 1
+			},
+			placement: math.Log2(15) + 2,
+		},
+		{
+			name: "between with a dash",
+			change: func(o *Options) {
+				o.Placement, o.Separator = PlaceBetween, SepDash
+				o.AddNumbers, o.NumberCount, o.AddSpecial, o.SpecialCount = true, 2, true, 1
+			},
+			placement: math.Log2(15),
+			warnings:  1,
+		},
+		{
+			// The shortest passphrase has 11 characters
+			name:      "anywhere",
+			change:    func(o *Options) { o.Placement = PlaceAnywhere; o.AddNumbers, o.NumberCount = true, 1 },
+			placement: math.Log2(12),
+		},
+		{
+			name: "anywhere with leet",
+			change: func(o *Options) {
+				o.Placement, o.Substitutions = PlaceAnywhere, &SubstitutionsLeet
+				o.AddNumbers, o.NumberCount, o.AddSpecial, o.SpecialCount = true, 1, true, 1
+			},
+			substitutions: 5 + math.Log2(3),
+			warnings:      2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := base
+			tt.change(&opts)
+
+			b, err := gen.EntropyBreakdown(opts)
+			require.NoError(t, err)
+			assert.InDelta(t, 3, b.Words, 1e-9)
+			assert.InDelta(t, tt.caps, b.Capitalization, 1e-9)
+			assert.InDelta(t, tt.substitutions, b.Substitutions, 1e-9)
+			assert.InDelta(t, tt.placement, b.Placement, 1e-9)
+			assert.InDelta(t, b.Words+b.Capitalization+b.Substitutions+b.Digits+b.Specials+b.Placement, b.Total, 1e-9)
+			assert.Len(t, b.Warnings, tt.warnings, b.Warnings)
+
+			for range 20 {
+				_, err := gen.Generate(opts)
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGenerateTransforms(t *testing.T) {
+	gen := setupWordsGenerator(t, []string{"bat", "cat"}, []string{"dog", "hog"}, []string{"eel", "elk"})
+
+	opts := Options{WordCount: 3, MinWordlists: 3, Separator: SepSpace, Substitutions: &SubstitutionsSafe, SubstituteAll: true}
+	for range 50 {
+		password, err := gen.Generate(opts)
+		require.NoError(t, err)
+		assert.Regexp(t, `^(b47|c47|dog|#og|33l|3lk)( (b47|c47|dog|#og|33l|3lk)){2}$`, password)
+	}
+
+	opts = Options{WordCount: 3, MinWordlists: 3, Separator: SepSpace, Placement: PlaceBetween, AddSpecial: true, SpecialCount: 2}
+	for range 50 {
+		password, err := gen.Generate(opts)
+		require.NoError(t, err)
+		for _, word := range strings.FieldsFunc(password, func(r rune) bool { return !unicode.IsLetter(r) }) {
+			assert.Contains(t, []string{"bat", "cat", "dog", "hog", "eel", "elk"}, word, password)
+		}
+	}
+
+	// The target search counts random substitutions: 1+(5+log2(3))/3 bits per
+	// word take 7 words to 20 bits instead of 20
+	resolved, err := gen.ResolveTarget(Options{WordCount: 3, MinWordlists: 3, Substitutions: &SubstitutionsLeet, TargetEntropyBits: 20})
+	require.NoError(t, err)
+	assert.Equal(t, 7, resolved.WordCount)
+}
+
+func TestTemplateTransforms(t *testing.T) {
+	gen := setupTaggedGenerator(t)
+
+	opts := Options{Template: "{adj} {noun}", Placement: PlaceBetween, AddNumbers: true, NumberCount: 1, Substitutions: &SubstitutionsSafe}
+	for range 50 {
+		password, err := gen.Generate(opts)
+		require.NoError(t, err)
+		assert.Len(t, strings.Fields(password), 2, password)
+		assert.Len(t, password, 1+len(strings.Join(strings.Fields(password), "")), password)
+	}
+
+	// Three units and one digit give four gaps, but the safe table uses 3 too
+	b, err := gen.EntropyBreakdown(opts)
+	require.NoError(t, err)
+	assert.Zero(t, b.Placement)
+	assert.Positive(t, b.Substitutions)
+	require.Len(t, b.Warnings, 1)
+	assert.Contains(t, b.Warnings[0], "digits")
+
+	opts.Substitutions = nil
+	b, err = gen.EntropyBreakdown(opts)
+	require.NoError(t, err)
+	assert.InDelta(t, 2, b.Placement, 1e-9)
+
+	// {num} slots make inserted digits ambiguous
+	opts.Template = "{num} {noun}"
+	b, err = gen.EntropyBreakdown(opts)
+	require.NoError(t, err)
+	assert.Zero(t, b.Placement)
+}
+
+func TestValidateTransforms(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		err  error
+	}{
+		{"policy", Options{WordCount: 4, MinWordlists: 1, Substitutions: &SubstitutionsLeet, Policy: &PolicyAD}, ErrTransformUnsupported},
+		{"placement", Options{WordCount: 4, MinWordlists: 1, Placement: Placement(7)}, ErrUnknownPlacement},
+		{"table", Options{WordCount: 4, MinWordlists: 1, Substitutions: &Substitutions{Table: map[rune]string{'a': "b"}}}, ErrInvalidSubstitutions},
+		{"strategy", Options{Strategy: StrategyPIN, PINLength: 6, Substitutions: &SubstitutionsLeet}, ErrStrategyOption},
+		{"template policy", Options{Template: "{adj}", Placement: PlaceAnywhere, Policy: &PolicyAD}, ErrTemplateUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorIs(t, tt.opts.Validate(), tt.err)
+		})
+	}
+
+	gen := setupTestGenerator(t)
+	_, err := gen.DicePlan(Options{WordCount: 3, MinWordlists: 3, Substitutions: &SubstitutionsLeet})
+	require.ErrorIs(t, err, ErrDiceUnsupported)
+}
@@ -1,5 +1,8 @@
 // Package security provides cryptographic primitives and secure memory handling
-// for the glyphic password generator. All random operations use crypto/rand only.
+// for the glyphic password generator. Random choices read from a RandomSource,
+// which is crypto/rand unless a caller supplies another: a KeyedSource stretched
+// from a secret for derived passwords, or an InsecureSeededSource that replays
+// a seed for golden tests and audits and must never produce real passwords.
 package security
 
 import (
@@ -7,6 +10,8 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
+	"math/big"
 	"runtime"
 
 	"golang.org/x/sys/unix"
@@ -19,6 +24,9 @@ var (
 	// ErrCryptoRandFailed indicates crypto/rand failed to generate random bytes
 	ErrCryptoRandFailed = errors.New("crypto/rand failed")
 
+	// ErrInvalidKey indicates a key of the wrong length
+	ErrInvalidKey = errors.New("invalid key")
+
 	// ErrMemoryLockFailed indicates memory locking failed
 	ErrMemoryLockFailed = errors.New("failed to lock memory")
 
@@ -27,29 +35,78 @@ var (
 )
 
 // SecureRandomIndex returns a cryptographically secure random index in the range [0, max).
-// It uses crypto/rand and avoids modulo bias.
+// It uses crypto/rand and rejection sampling, so every index is exactly equally likely.
 func SecureRandomIndex(max int) (int, error) {
+	return randomIndex(rand.Reader, max)
+}
+
+// RandomIndex returns a uniform index in [0, max) drawn from src, or from
+// crypto/rand when src is nil
+func RandomIndex(src RandomSource, max int) (int, error) {
+	return randomIndex(orCrypto(src), max)
+}
+
+// randomIndex draws a uniform index in [0, max) from r.
+// The 2^64 mod max smallest 64-bit values are rejected and redrawn, which leaves
+// a range that is an exact multiple of max and removes modulo bias.
+func randomIndex(r io.Reader, max int) (int, error) {
 	if max <= 0 {
 		return 0, ErrInvalidRange
 	}
 
-	// For small ranges, use simple approach
-	if max <= 256 {
-		var buf [1]byte
-		if _, err := rand.Read(buf[:]); err != nil {
+	bound := uint64(max)
+	threshold := -bound % bound // (2^64 - bound) mod bound == 2^64 mod bound
+
+	var buf [8]byte
+	for {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
 			return 0, fmt.Errorf("%w: %v", ErrCryptoRandFailed, err)
 		}
-		return int(buf[0]) % max, nil
+
+		n := binary.BigEndian.Uint64(buf[:])
+		if n >= threshold {
+			return int(n % bound), nil
+		}
 	}
+}
 
-	// For larger ranges, use 64-bit random value
-	var buf [8]byte
-	if _, err := rand.Read(buf[:]); err != nil {
-		return 0, fmt.Errorf("%w: %v", ErrCryptoRandFailed, err)
+// SecureShuffle permutes s in place with a Fisher-Yates shuffle driven by SecureRandomIndex,
+// so every permutation is equally likely.
+func SecureShuffle[T any](s []T) error {
+	return Shuffle(Crypto, s)
+}
+
+// Shuffle permutes s in place with a Fisher-Yates shuffle driven by src, or by
+// crypto/rand when src is nil
+func Shuffle[T any](src RandomSource, s []T) error {
+	for i := len(s) - 1; i > 0; i-- {
+		j, err := RandomIndex(src, i+1)
+		if err != nil {
+			return err
+		}
+		s[i], s[j] = s[j], s[i]
+	}
+	return nil
+}
+
+// SecureRandomBigInt returns a cryptographically secure uniform integer in [0, max),
+// for ranges that do not fit in an int.
+func SecureRandomBigInt(max *big.Int) (*big.Int, error) {
+	return RandomBigInt(Crypto, max)
+}
+
+// RandomBigInt returns a uniform integer in [0, max) drawn from src, or from
+// crypto/rand when src is nil
+func RandomBigInt(src RandomSource, max *big.Int) (*big.Int, error) {
+	if max.Sign() <= 0 {
+		return nil, ErrInvalidRange
 	}
 
-	n := binary.BigEndian.Uint64(buf[:])
-	return int(n % uint64(max)), nil
+	n, err := rand.Int(orCrypto(src), max)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCryptoRandFailed, err)
+	}
+	return n, nil
 }
 
 // SecureRandomBytes fills the provided byte slice with cryptographically secure random bytes.
@@ -64,6 +121,14 @@ func SecureRandomBytes(data []byte) error {
 	return nil
 }
 
+// RandomBytes fills data with bytes from src, or from crypto/rand when src is nil
+func RandomBytes(src RandomSource, data []byte) error {
+	if _, err := io.ReadFull(orCrypto(src), data); err != nil {
+		return fmt.Errorf("%w: %v", ErrCryptoRandFailed, err)
+	}
+	return nil
+}
+
 // SecureRandomInt64 returns a cryptographically secure random int64.
 func SecureRandomInt64() (int64, error) {
 	var buf [8]byte
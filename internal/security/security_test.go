@@ -2,6 +2,9 @@ package security
 
 import (
 	"bytes"
+	"encoding/binary"
+	"math"
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -47,6 +50,184 @@ func TestSecureRandomIndex(t *testing.T) {
 	}
 }
 
+// uint64Reader encodes values as big-endian words for randomIndex
+func uint64Reader(values ...uint64) *bytes.Reader {
+	buf := make([]byte, 0, 8*len(values))
+	for _, v := range values {
+		buf = binary.BigEndian.AppendUint64(buf, v)
+	}
+	return bytes.NewReader(buf)
+}
+
+func TestRandomIndexRejection(t *testing.T) {
+	tests := []struct {
+		name   string
+		max    int
+		values []uint64
+		want   int
+	}{
+		// 2^64 mod 10 == 6, so 0-5 are rejected
+		{"rejects below threshold", 10, []uint64{3, 17}, 7},
+		{"accepts threshold", 10, []uint64{6}, 6},
+		{"accepts top of range", 10, []uint64{math.MaxUint64}, 5},
+		// 2^64 mod 3 == 1, so only 0 is rejected
+		{"rejects repeatedly", 3, []uint64{0, 0, 0, 2}, 2},
+		{"single element never rejects", 1, []uint64{0}, 0},
+		// 2^64 mod (2^62+1) == 2^62-3
+		{"large range", 1<<62 + 1, []uint64{5, 1<<62 - 3}, 1<<62 - 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := uint64Reader(tt.values...)
+			got, err := randomIndex(r, tt.max)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Zero(t, r.Len(), "should consume exactly the rejected values plus one")
+		})
+	}
+
+	t.Run("short read", func(t *testing.T) {
+		_, err := randomIndex(bytes.NewReader([]byte{1, 2, 3}), 10)
+		assert.ErrorIs(t, err, ErrCryptoRandFailed)
+	})
+
+	t.Run("exhausted while rejecting", func(t *testing.T) {
+		_, err := randomIndex(uint64Reader(0, 1, 2), 10)
+		assert.ErrorIs(t, err, ErrCryptoRandFailed)
+	})
+}
+
+// chiSquare returns Pearson's statistic for counts against a uniform expectation
+func chiSquare(counts []int, draws int) float64 {
+	expected := float64(draws) / float64(len(counts))
+	var stat float64
+	for _, c := range counts {
+		d := float64(c) - expected
+		stat += d * d / expected
+	}
+	return stat
+}
+
+// chiSquareCritical approximates the upper critical value of the chi-square
+// distribution with df degrees of freedom using the Wilson-Hilferty transform.
+// z is the standard normal quantile; 4.753 corresponds to p = 1e-6, which keeps
+// false alarms negligible while still rejecting byte-modulo bias by a wide margin.
+func chiSquareCritical(df int, z float64) float64 {
+	k := float64(df)
+	h := 2 / (9 * k)
+	return k * math.Pow(1-h+z*math.Sqrt(h), 3)
+}
+
+const chiSquareZ = 4.753
+
+// uniformityModuli are awkward moduli used throughout the generator:
+// digits, special characters and the EFF large wordlist
+var uniformityModuli = []struct {
+	name  string
+	max   int
+	draws int
+}{
+	{"digits", 10, 1_000_000},
+	{"special chars", 24, 1_000_000},
+	{"eff large", 7776, 7776 * 200},
+}
+
+func TestSecureRandomIndexUniformity(t *testing.T) {
+	if testing.Short() {
+		t.Skip("statistical test draws millions of values")
+	}
+
+	for _, tt := range uniformityModuli {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := make([]int, tt.max)
+			for range tt.draws {
+				idx, err := SecureRandomIndex(tt.max)
+				require.NoError(t, err)
+				counts[idx]++
+			}
+
+			stat := chiSquare(counts, tt.draws)
+			critical := chiSquareCritical(tt.max-1, chiSquareZ)
+			assert.Less(t, stat, critical, "chi-square %.1f exceeds %.1f: index distribution is biased", stat, critical)
+		})
+	}
+}
+
+// TestChiSquareDetectsModuloBias checks the uniformity test has the power to
+// catch the old byte-modulo implementation
+func TestChiSquareDetectsModuloBias(t *testing.T) {
+	if testing.Short() {
+		t.Skip("statistical test draws millions of values")
+	}
+
+	for _, tt := range uniformityModuli[:2] {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := make([]byte, tt.draws)
+			require.NoError(t, SecureRandomBytes(buf))
+
+			counts := make([]int, tt.max)
+			for _, b := range buf {
+				counts[int(b)%tt.max]++
+			}
+
+			stat := chiSquare(counts, tt.draws)
+			critical := chiSquareCritical(tt.max-1, chiSquareZ)
+			assert.Greater(t, stat, critical, "byte modulo %d should be detected as biased", tt.max)
+		})
+	}
+}
+
+func TestSecureShuffle(t *testing.T) {
+	t.Run("preserves elements", func(t *testing.T) {
+		s := []int{1, 2, 3, 4, 5, 6, 7, 8}
+		require.NoError(t, SecureShuffle(s))
+		assert.ElementsMatch(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, s)
+	})
+
+	t.Run("empty and single", func(t *testing.T) {
+		assert.NoError(t, SecureShuffle([]string{}))
+		single := []string{"a"}
+		require.NoError(t, SecureShuffle(single))
+		assert.Equal(t, []string{"a"}, single)
+	})
+
+	t.Run("permutations are uniform", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("statistical test")
+		}
+
+		const draws = 240_000
+		counts := make(map[string]int)
+		for range draws {
+			s := []byte("abcd")
+			require.NoError(t, SecureShuffle(s))
+			counts[string(s)]++
+		}
+		require.Len(t, counts, 24)
+
+		flat := make([]int, 0, len(counts))
+		for _, c := range counts {
+			flat = append(flat, c)
+		}
+		stat := chiSquare(flat, draws)
+		assert.Less(t, stat, chiSquareCritical(23, chiSquareZ))
+	})
+}
+
+func TestSecureRandomBigInt(t *testing.T) {
+	max := new(big.Int).Lsh(big.NewInt(1), 100)
+	for range 100 {
+		n, err := SecureRandomBigInt(max)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, n.Sign(), 0)
+		assert.Negative(t, n.Cmp(max))
+	}
+
+	_, err := SecureRandomBigInt(big.NewInt(0))
+	assert.ErrorIs(t, err, ErrInvalidRange)
+}
+
 func TestSecureRandomBytes(t *testing.T) {
 	tests := []struct {
 		name string
@@ -239,6 +420,12 @@ func BenchmarkSecureRandomIndex(b *testing.B) {
 	}
 }
 
+func BenchmarkSecureRandomIndexSmall(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _ = SecureRandomIndex(24)
+	}
+}
+
 func BenchmarkSecureRandomBytes(b *testing.B) {
 	data := make([]byte, 64)
 	b.ResetTimer()
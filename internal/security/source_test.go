@@ -0,0 +1,79 @@
+package security
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsecureSeededSource(t *testing.T) {
+	read := func(src RandomSource, n int) []byte {
+		buf := make([]byte, n)
+		_, err := src.Read(buf)
+		require.NoError(t, err)
+		return buf
+	}
+
+	a, b := NewInsecureSeededSource("golden"), NewInsecureSeededSource("golden")
+	assert.True(t, a.Deterministic())
+	assert.Equal(t, read(a, 64), read(b, 64))
+
+	// Reads continue the keystream regardless of how it is split
+	c := NewInsecureSeededSource("golden")
+	whole := read(c, 128)
+	assert.Equal(t, whole[64:96], read(a, 32))
+	assert.Equal(t, whole[96:], read(a, 32))
+
+	other := NewInsecureSeededSource("other")
+	assert.NotEqual(t, whole[:32], read(other, 32))
+}
+
+func TestInsecureSeededSourceGolden(t *testing.T) {
+	src := NewInsecureSeededSource("glyphic")
+
+	buf := make([]byte, 16)
+	_, err := src.Read(buf)
+	require.NoError(t, err)
+	// ChaCha20 keystream (RFC 8439, zero nonce and counter) under SHA-256("glyphic")
+	assert.Equal(t, "b8f4e0d3b8d44ddcc26887b251445bbb", hex.EncodeToString(buf))
+
+	var indices []int
+	for range 8 {
+		idx, err := RandomIndex(src, 7776)
+		require.NoError(t, err)
+		indices = append(indices, idx)
+	}
+	assert.Equal(t, []int{4539, 1693, 845, 3004, 6333, 4602, 3015, 4001}, indices)
+}
+
+func TestCryptoSource(t *testing.T) {
+	assert.False(t, Crypto.Deterministic())
+
+	idx, err := RandomIndex(nil, 10)
+	require.NoError(t, err)
+	assert.Less(t, idx, 10)
+
+	s := []int{1, 2, 3}
+	require.NoError(t, Shuffle(nil, s))
+	assert.ElementsMatch(t, []int{1, 2, 3}, s)
+}
+
+func TestKeyedSource(t *testing.T) {
+	// Keyed by the SHA-256 of a seed, it produces the seeded keystream
+	key := sha256.Sum256([]byte("glyphic"))
+	keyed, err := NewKeyedSource(key[:])
+	require.NoError(t, err)
+	assert.True(t, keyed.Deterministic())
+
+	want := make([]byte, 64)
+	_, _ = NewInsecureSeededSource("glyphic").Read(want)
+	got := make([]byte, 64)
+	_, _ = keyed.Read(got)
+	assert.Equal(t, want, got)
+
+	_, err = NewKeyedSource(key[:16])
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
@@ -112,6 +112,16 @@ func GetScheme(name string) ColorScheme {
 	}
 }
 
+// LookupScheme returns the color scheme with the given name and whether it exists
+func LookupScheme(name string) (ColorScheme, bool) {
+	for _, scheme := range AllSchemes() {
+		if scheme.Name == name {
+			return scheme, true
+		}
+	}
+	return ColorScheme{}, false
+}
+
 // SchemeNames returns all available scheme names
 func SchemeNames() []string {
 	schemes := AllSchemes()
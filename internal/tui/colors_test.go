@@ -0,0 +1,19 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupScheme(t *testing.T) {
+	for _, name := range SchemeNames() {
+		scheme, ok := LookupScheme(name)
+		assert.True(t, ok)
+		assert.Equal(t, name, scheme.Name)
+		assert.Equal(t, GetScheme(name), scheme)
+	}
+
+	_, ok := LookupScheme("plaid")
+	assert.False(t, ok)
+}
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -20,12 +21,31 @@ const (
 	SpeedFast   Speed = 30
 )
 
+// ErrUnknownSpeed indicates an unrecognised animation speed name
+var ErrUnknownSpeed = errors.New("unknown animation speed")
+
+// ParseSpeed returns the speed preset with the given name (slow, normal, fast)
+func ParseSpeed(name string) (Speed, error) {
+	switch name {
+	case "slow":
+		return SpeedSlow, nil
+	case "normal":
+		return SpeedNormal, nil
+	case "fast":
+		return SpeedFast, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnknownSpeed, name)
+	}
+}
+
 // RevealOptions configures the reveal animation
 type RevealOptions struct {
-	Scheme       ColorScheme       // Color scheme to use
-	Speed        Speed             // Animation speed
-	TerminalMode font.TerminalMode // Terminal capability level
-	ShowEntropy  bool              // Show entropy calculation
+	Scheme       ColorScheme           // Color scheme to use
+	Speed        Speed                 // Animation speed
+	TerminalMode font.TerminalMode     // Terminal capability level
+	ShowEntropy  bool                  // Show entropy calculation
+	EntropyBits  float64               // Entropy to display when ShowEntropy is set (0 = length only)
+	Rand         security.RandomSource // Source for the scramble, crypto/rand when nil
 }
 
 // DefaultRevealOptions provides sensible defaults
@@ -76,6 +96,7 @@ func NewRevealModel(password string, opts RevealOptions) RevealModel {
 
 	// Get appropriate glyph set for terminal
 	glyphSet := font.GetGlyphSet(opts.TerminalMode)
+	glyphSet.Rand = opts.Rand
 
 	return RevealModel{
 		password:    password,
@@ -169,7 +190,7 @@ func (m *RevealModel) updateReveal() {
 
 			if localProgress > 0.7 {
 				// High chance of revealing
-				shouldReveal, _ := security.SecureRandomIndex(10)
+				shouldReveal, _ := security.RandomIndex(m.opts.Rand, 10)
 				if shouldReveal > 2 { // 70% chance
 					m.chars[i].Current = m.chars[i].Target
 				} else {
@@ -241,6 +262,9 @@ func (m RevealModel) View() string {
 	if m.opts.ShowEntropy && m.done {
 		result += "\n\n"
 		entropyInfo := fmt.Sprintf("Length: %d characters", len(m.password))
+		if m.opts.EntropyBits > 0 {
+			entropyInfo += fmt.Sprintf(" • Entropy: %.1f bits", m.opts.EntropyBits)
+		}
 		result += lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#888888")).
 			Render(entropyInfo)
@@ -7,6 +7,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/greysquirr3l/glyphic/internal/font"
+	"github.com/greysquirr3l/glyphic/internal/security"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -261,6 +262,21 @@ func TestRevealModelView(t *testing.T) {
 		view := model.View()
 		assert.Contains(t, view, "Length")
 	})
+
+	t.Run("shows entropy bits when provided", func(t *testing.T) {
+		model := NewRevealModel("test", RevealOptions{
+			Scheme:       MatrixScheme,
+			Speed:        SpeedNormal,
+			TerminalMode: font.TerminalFull,
+			ShowEntropy:  true,
+			EntropyBits:  77.5,
+		})
+
+		model.done = true
+
+		view := model.View()
+		assert.Contains(t, view, "77.5 bits")
+	})
 }
 
 func TestSpeedConstants(t *testing.T) {
@@ -364,6 +380,31 @@ func TestDefaultRevealOptions(t *testing.T) {
 	assert.LessOrEqual(t, int(opts.TerminalMode), int(font.TerminalFull))
 }
 
+func TestParseSpeed(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Speed
+		wantErr bool
+	}{
+		{"slow", SpeedSlow, false},
+		{"normal", SpeedNormal, false},
+		{"fast", SpeedFast, false},
+		{"ludicrous", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSpeed(tt.name)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrUnknownSpeed)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestTickCommand(t *testing.T) {
 	cmd := tick(SpeedFast)
 	assert.NotNil(t, cmd)
@@ -411,3 +452,27 @@ func BenchmarkUpdateReveal(b *testing.B) {
 		model.updateReveal()
 	}
 }
+
+func TestRevealModelSeededFrames(t *testing.T) {
+	opts := RevealOptions{
+		Scheme:       MatrixScheme,
+		Speed:        SpeedFast,
+		TerminalMode: font.TerminalDumb,
+		Rand:         security.NewInsecureSeededSource("golden"),
+	}
+	model := NewRevealModel("abc", opts)
+
+	var frames []string
+	for range 6 {
+		updated, _ := model.Update(tickMsg{})
+		model = updated.(RevealModel)
+
+		var frame strings.Builder
+		for _, char := range model.chars {
+			frame.WriteRune(char.Current)
+		}
+		frames = append(frames, frame.String())
+	}
+
+	assert.Equal(t, []string{"+?+", "~+|", "|_/", ":_<", "`@\\", ".#?"}, frames)
+}
@@ -0,0 +1,96 @@
+package wordlist
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultTTL is how long a downloaded wordlist is used before it is revalidated
+const DefaultTTL = 30 * 24 * time.Hour
+
+// cacheMeta is the sidecar stored next to each downloaded wordlist
+type cacheMeta struct {
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	SHA256       string    `json:"sha256"`     // checksum of the cached file
+	FetchedAt    time.Time `json:"fetched_at"` // last download or successful revalidation
+}
+
+// SetTTL sets how long downloaded wordlists are used before EnsureWordlists
+// revalidates them with a conditional request. A TTL of zero or less
+// revalidates on every call.
+func (m *Manager) SetTTL(ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ttl = ttl
+}
+
+// metaPath returns the path of the cache sidecar for a wordlist ID
+func (m *Manager) metaPath(id string) string {
+	return filepath.Join(m.cacheDir, id+".meta.json")
+}
+
+// readMeta reads the cache sidecar of a wordlist ID, nil when there is none
+// or it is unreadable
+func (m *Manager) readMeta(id string) *cacheMeta {
+	data, err := os.ReadFile(m.metaPath(id))
+	if err != nil {
+		return nil
+	}
+	var meta cacheMeta
+	if json.Unmarshal(data, &meta) != nil {
+		return nil
+	}
+	return &meta
+}
+
+// writeMeta atomically replaces the cache sidecar of a wordlist ID
+func (m *Manager) writeMeta(id string, meta *cacheMeta) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cache metadata: %w", err)
+	}
+	if err := writeFileAtomic(m.metaPath(id), append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write cache metadata: %w", err)
+	}
+	return nil
+}
+
+// fetchedAt returns when the cached copy of a wordlist was last downloaded
+// or revalidated, or modTime, the cached file's modification time, for
+// caches written without a sidecar
+func (m *Manager) fetchedAt(id string, modTime time.Time) time.Time {
+	if meta := m.readMeta(id); meta != nil && !meta.FetchedAt.IsZero() {
+		return meta.FetchedAt
+	}
+	return modTime
+}
+
+// revalidatable returns the sidecar of a cached wordlist whose validators may
+// be sent with a conditional request: the cached file must still match the
+// checksum recorded when it was downloaded and pass verification, so a 304
+// response can never bless a file that was changed or is no longer trusted
+func (m *Manager) revalidatable(source WordlistSource) *cacheMeta {
+	meta := m.readMeta(source.ID)
+	if meta == nil || meta.ETag == "" && meta.LastModified == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(m.cachePath(source.ID))
+	if err != nil {
+		return nil
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != meta.SHA256 {
+		return nil
+	}
+	if !source.Pinned() && !m.allowUnpinned || m.verifyCached(data, source) != nil {
+		return nil
+	}
+	return meta
+}
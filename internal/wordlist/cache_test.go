@@ -0,0 +1,265 @@
+package wordlist
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conditionalServer serves one wordlist with an ETag and Last-Modified date
+// and records the conditional headers and status of every request
+type conditionalServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	data     string
+	etag     string
+	modified time.Time
+	requests []conditionalRequest
+}
+
+// conditionalRequest is one request seen by a conditionalServer
+type conditionalRequest struct {
+	ifNoneMatch     string
+	ifModifiedSince string
+	status          int
+}
+
+// newConditionalServer starts a TLS server for data, closed when the test ends
+func newConditionalServer(t *testing.T, data string, useETag bool) *conditionalServer {
+	t.Helper()
+	cs := &conditionalServer{modified: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)}
+	cs.set(data, useETag)
+	cs.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+		// ServeContent answers If-None-Match and If-Modified-Since with 304
+		rec := httptest.NewRecorder()
+		if cs.etag != "" {
+			rec.Header().Set("ETag", cs.etag)
+		}
+		http.ServeContent(rec, r, "list.txt", cs.modified, strings.NewReader(cs.data))
+		cs.requests = append(cs.requests, conditionalRequest{
+			ifNoneMatch:     r.Header.Get("If-None-Match"),
+			ifModifiedSince: r.Header.Get("If-Modified-Since"),
+			status:          rec.Code,
+		})
+		for key, values := range rec.Header() {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(rec.Code)
+		_, _ = w.Write(rec.Body.Bytes())
+	}))
+	t.Cleanup(cs.Close)
+	return cs
+}
+
+// set replaces the served list, with a new ETag when useETag is set
+func (cs *conditionalServer) set(data string, useETag bool) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.data = data
+	cs.etag = ""
+	if useETag {
+		cs.etag = `"` + sha256Hex([]byte(data))[:16] + `"`
+	}
+	cs.modified = cs.modified.Add(time.Hour)
+}
+
+// last returns the most recent request
+func (cs *conditionalServer) last() conditionalRequest {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.requests[len(cs.requests)-1]
+}
+
+// count returns the number of requests
+func (cs *conditionalServer) count() int {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return len(cs.requests)
+}
+
+// newConditionalManager returns a manager with the server's list as its only
+// source, pinned to data or unpinned when data is empty
+func newConditionalManager(t *testing.T, dir string, cs *conditionalServer, data string) *Manager {
+	t.Helper()
+	m, err := NewManager(dir)
+	require.NoError(t, err)
+	m.client = cs.Client()
+
+	source := WordlistSource{ID: "list", URL: cs.URL + "/list.txt"}
+	if data != "" {
+		source.SHA256 = sha256Hex([]byte(data))
+	} else {
+		m.SetAllowUnpinned(true)
+	}
+	m.sources = []WordlistSource{source}
+	return m
+}
+
+func TestConditionalRefresh(t *testing.T) {
+	const list = "otter\nheron\n"
+	cs := newConditionalServer(t, list, true)
+	dir := t.TempDir()
+	m := newConditionalManager(t, dir, cs, list)
+	ctx := context.Background()
+
+	require.NoError(t, m.EnsureWordlists(ctx))
+	assert.Equal(t, http.StatusOK, cs.last().status)
+	assert.Empty(t, cs.last().ifNoneMatch)
+
+	meta := m.readMeta("list")
+	require.NotNil(t, meta)
+	assert.Equal(t, cs.etag, meta.ETag)
+	assert.Equal(t, cs.modified.Format(http.TimeFormat), meta.LastModified)
+	assert.Equal(t, sha256Hex([]byte(list)), meta.SHA256)
+	assert.WithinDuration(t, time.Now(), meta.FetchedAt, time.Minute)
+
+	// Within the TTL nothing is requested
+	require.NoError(t, m.EnsureWordlists(ctx))
+	assert.Equal(t, 1, cs.count())
+
+	// Past it the list is revalidated and the 304 keeps the cached copy
+	m.SetTTL(0)
+	first := meta.FetchedAt
+	require.NoError(t, m.EnsureWordlists(ctx))
+	assert.Equal(t, 2, cs.count())
+	assert.Equal(t, conditionalRequest{ifNoneMatch: cs.etag, ifModifiedSince: meta.LastModified, status: http.StatusNotModified}, cs.last())
+	meta = m.readMeta("list")
+	assert.True(t, meta.FetchedAt.After(first) || meta.FetchedAt.Equal(first))
+	assert.Equal(t, cs.etag, meta.ETag)
+	require.NoError(t, m.LoadAll())
+	wl, _ := m.Loaded("list")
+	assert.Equal(t, []string{"heron", "otter"}, wl.Words)
+
+	// Update revalidates too
+	require.NoError(t, m.Update(ctx))
+	assert.Equal(t, http.StatusNotModified, cs.last().status)
+}
+
+func TestConditionalRefreshChanged(t *testing.T) {
+	cs := newConditionalServer(t, "otter\nheron\n", true)
+	dir := t.TempDir()
+	m := newConditionalManager(t, dir, cs, "")
+	m.SetTTL(0)
+	ctx := context.Background()
+	require.NoError(t, m.EnsureWordlists(ctx))
+
+	// A changed list is transferred in full with its new validators
+	cs.set("otter\nheron\nbadger\n", true)
+	require.NoError(t, m.EnsureWordlists(ctx))
+	assert.Equal(t, http.StatusOK, cs.last().status)
+	assert.NotEmpty(t, cs.last().ifNoneMatch)
+	data, err := os.ReadFile(filepath.Join(dir, "list.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "otter\nheron\nbadger\n", string(data))
+	assert.Equal(t, cs.etag, m.readMeta("list").ETag)
+
+	// A cache changed on disk is never revalidated, only replaced
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "list.txt"), []byte("mole\nvole\n"), 0600))
+	require.NoError(t, m.EnsureWordlists(ctx))
+	assert.Equal(t, conditionalRequest{status: http.StatusOK}, cs.last())
+	data, err = os.ReadFile(filepath.Join(dir, "list.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "otter\nheron\nbadger\n", string(data))
+}
+
+func TestConditionalRefreshLastModified(t *testing.T) {
+	const list = "otter\nheron\n"
+	cs := newConditionalServer(t, list, false)
+	m := newConditionalManager(t, t.TempDir(), cs, list)
+	m.SetTTL(0)
+	ctx := context.Background()
+
+	require.NoError(t, m.EnsureWordlists(ctx))
+	require.NoError(t, m.EnsureWordlists(ctx))
+	assert.Equal(t, conditionalRequest{ifModifiedSince: cs.modified.Format(http.TimeFormat), status: http.StatusNotModified}, cs.last())
+}
+
+func TestRefreshKeepsCacheOnFailure(t *testing.T) {
+	const list = "otter\nheron\n"
+	cs := newConditionalServer(t, list, true)
+	dir := t.TempDir()
+	m := newConditionalManager(t, dir, cs, list)
+	require.NoError(t, m.EnsureWordlists(context.Background()))
+
+	// The server now serves a list that fails the pinned checksum
+	cs.set("mole\nvole\n", true)
+	require.ErrorIs(t, m.Update(context.Background()), ErrChecksumMismatch)
+
+	data, err := os.ReadFile(filepath.Join(dir, "list.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, list, string(data))
+	assert.Equal(t, sha256Hex([]byte(list)), m.readMeta("list").SHA256)
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{"list.txt", "list.meta.json"}, names)
+}
+
+func TestCacheTTL(t *testing.T) {
+	const list = "otter\nheron\n"
+	cs := newConditionalServer(t, list, true)
+	dir := t.TempDir()
+	m := newConditionalManager(t, dir, cs, list)
+	source := m.sources[0]
+	path := m.cachePath("list")
+
+	// Without a sidecar the file's modification time decides
+	require.NoError(t, os.WriteFile(path, []byte(list), 0600))
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(path, old, old))
+	assert.True(t, m.isValidCache(path, source))
+	m.SetTTL(time.Hour)
+	assert.False(t, m.isValidCache(path, source))
+
+	// With one its fetch time does, whatever the file's time
+	require.NoError(t, m.writeMeta("list", &cacheMeta{SHA256: sha256Hex([]byte(list)), FetchedAt: time.Now()}))
+	assert.True(t, m.isValidCache(path, source))
+	require.NoError(t, m.writeMeta("list", &cacheMeta{SHA256: sha256Hex([]byte(list)), FetchedAt: old}))
+	assert.False(t, m.isValidCache(path, source))
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "list.txt")
+
+	require.NoError(t, writeFileAtomic(path, []byte("first")))
+	require.NoError(t, writeFileAtomic(path, []byte("second")))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// A failed rename leaves no temporary file
+	require.Error(t, writeFileAtomic(filepath.Join(dir, "missing", "list.txt"), []byte("x")))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "target"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "target", "keep"), nil, 0600))
+	require.Error(t, writeFileAtomic(filepath.Join(dir, "target"), []byte("x")))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestSyncDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, syncDir(dir))
+	require.Error(t, syncDir(filepath.Join(dir, "missing")))
+}
@@ -0,0 +1,230 @@
+package wordlist
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"math/big"
+	"path"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/greysquirr3l/glyphic/internal/bip39"
+)
+
+// codecMarker is prefixed to the data before encoding so that leading zero
+// bytes survive the conversion to a number and corrupted input is detected
+const codecMarker = 0x01
+
+var (
+	// ErrCodecList indicates a wordlist that cannot be used by a codec
+	ErrCodecList = errors.New("wordlist cannot be used for encoding")
+
+	// ErrUnknownWord indicates a decoded word that is not in the codec's list
+	ErrUnknownWord = errors.New("unknown word")
+
+	// ErrChecksumWord indicates a checksum word that does not match the decoded data
+	ErrChecksumWord = errors.New("checksum word mismatch")
+
+	// ErrCorruptWords indicates words that no encoding could have produced
+	ErrCorruptWords = errors.New("words do not decode to any data")
+)
+
+// Codec converts arbitrary bytes to words of a wordlist and back. The data,
+// prefixed with a marker byte, is read as a big-endian number and written in
+// base len(words), one word per digit, so every list size works. Both sides
+// must use the same words in the same order, so built-in lists encode over a
+// frozen word array rather than whatever the loader currently keeps.
+type Codec struct {
+	words    []string
+	index    map[string]int
+	checksum bool
+}
+
+// codecFiles holds the frozen word arrays of the built-in lists, one word per
+// line in codec order. They are kept in slim builds, so decoding a backup
+// never depends on a download.
+//
+//go:embed codec/*.txt
+var codecFiles embed.FS
+
+// codecArrays pins the SHA-256 of every frozen codec array, taken over its
+// words each followed by a newline. Changing an array changes every encoding
+// made with it and breaks decoding of existing backups, so a list whose words
+// change needs a new ID instead.
+var codecArrays = map[string]string{
+	"eff-large":         "18586c092f641ecd1a471dd6ab35618ab69f0aa7483486424f7caf0996d06259",
+	"eff-short-2":       "f6a587145fd0d7aaeff9849e5168d63ef795b03c2e0ce7efe7b2546c1c975e9f",
+	"diceware-de":       "199941eea4269a6fe1aa6de9ae6fc18e9280e6fe2c946e736ab33786feca9417",
+	"diceware-de-short": "d5ae513e39bbfb58305cf9aa68e0a446d57ee7e7930569d1368a85cda4a9ce73",
+	"bip39-es":          "0c639b0d58b6e56c18dcf418017ff341418a129e45fbf303361e8569edb02efe",
+	"bip39-fr":          "431c1d074225d2b7e82db857d7c3ea58051df546e7c8b74c1f6dcab36351fd56",
+	"bip39-it":          "d392c49fdb700a24cd1fceb237c1f65dcc128f6b34a8aacb58b59384b5c648c2",
+	"bip39-ja":          "c58cb719f782910cfcedb6ab64dc506322bd7ce4f7cede5a0563ac06d3d5c884",
+}
+
+// CodecIDs returns the sorted IDs of the lists with a frozen codec array
+func CodecIDs() []string {
+	return slices.Sorted(maps.Keys(codecArrays))
+}
+
+// BuiltinCodec creates a codec over the frozen word array of a built-in list.
+// With checksum, Encode appends a word derived from the SHA-256 of the data
+// and Decode verifies it.
+func BuiltinCodec(id string, checksum bool) (*Codec, error) {
+	words, err := codecArray(id)
+	if err != nil {
+		return nil, err
+	}
+	return newCodec(id, words, checksum)
+}
+
+// codecArray returns the frozen word array of id after checking its pin. The
+// BIP-39 arrays are the official lists in index order, in NFC.
+func codecArray(id string) ([]string, error) {
+	want, ok := codecArrays[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s has no codec word array (choose one of %s)", ErrSourceNotFound, id, strings.Join(CodecIDs(), ", "))
+	}
+
+	var data []byte
+	var err error
+	if lang, ok := bip39Sources[id]; ok {
+		data, err = bip39.File(lang)
+		data = norm.NFC.Bytes(data)
+	} else {
+		data, err = codecFiles.ReadFile(path.Join("codec", id+".txt"))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read codec words of %s: %w", id, err)
+	}
+
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return nil, fmt.Errorf("%w: codec words of %s have SHA-256 %s, want %s", ErrCodecList, id, got, want)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"), nil
+}
+
+// NewCodec creates a codec over the loaded words of a user list, which change
+// whenever the file does. Built-in lists use BuiltinCodec instead. With
+// checksum, Encode appends a word derived from the SHA-256 of the data and
+// Decode verifies it.
+func NewCodec(list *Wordlist, checksum bool) (*Codec, error) {
+	return newCodec(list.Source.ID, slices.Clone(list.Words), checksum)
+}
+
+// newCodec creates a codec over words, which it takes ownership of
+func newCodec(id string, words []string, checksum bool) (*Codec, error) {
+	if len(words) < 2 {
+		return nil, fmt.Errorf("%w: %s has %d words", ErrCodecList, id, len(words))
+	}
+
+	index := make(map[string]int, len(words))
+	for i, word := range words {
+		word = strings.ToLower(word)
+		if _, dup := index[word]; dup {
+			return nil, fmt.Errorf("%w: %s lists %q twice", ErrCodecList, id, word)
+		}
+		words[i] = word
+		index[word] = i
+	}
+
+	return &Codec{words: words, index: index, checksum: checksum}, nil
+}
+
+// BitsPerWord returns the data bits each word carries
+func (c *Codec) BitsPerWord() float64 {
+	return math.Log2(float64(len(c.words)))
+}
+
+// Encode returns the words for data, most significant first
+func (c *Codec) Encode(data []byte) []string {
+	n := new(big.Int).SetBytes(append([]byte{codecMarker}, data...))
+	base := big.NewInt(int64(len(c.words)))
+
+	var digits []string
+	digit := new(big.Int)
+	for n.Sign() > 0 {
+		n.DivMod(n, base, digit)
+		digits = append(digits, c.words[digit.Int64()])
+	}
+	slices.Reverse(digits)
+
+	if c.checksum {
+		digits = append(digits, c.checksumWord(data))
+	}
+	return digits
+}
+
+// Decode returns the data encoded by text. Words are matched case-insensitively
+// and may be separated by any run of characters other than letters, so
+// "Correct-Horse battery_STAPLE" splits into four words.
+func (c *Codec) Decode(text string) ([]byte, error) {
+	words := SplitWords(text)
+	if c.checksum {
+		if len(words) < 2 {
+			return nil, fmt.Errorf("%w: need data words and a checksum word", ErrCorruptWords)
+		}
+	} else if len(words) == 0 {
+		return nil, fmt.Errorf("%w: no words", ErrCorruptWords)
+	}
+
+	digits := words
+	if c.checksum {
+		digits = words[:len(words)-1]
+	}
+
+	n := new(big.Int)
+	base := big.NewInt(int64(len(c.words)))
+	for i, word := range digits {
+		digit, ok := c.index[word]
+		if !ok {
+			return nil, fmt.Errorf("%w: word %d %q", ErrUnknownWord, i+1, word)
+		}
+		if i == 0 && digit == 0 {
+			return nil, ErrCorruptWords // Encode never writes a leading zero digit
+		}
+		n.Mul(n, base)
+		n.Add(n, big.NewInt(int64(digit)))
+	}
+
+	raw := n.Bytes()
+	if len(raw) == 0 || raw[0] != codecMarker {
+		return nil, ErrCorruptWords
+	}
+	data := raw[1:]
+
+	if c.checksum {
+		last := words[len(words)-1]
+		if _, ok := c.index[last]; !ok {
+			return nil, fmt.Errorf("%w: word %d %q", ErrUnknownWord, len(words), last)
+		}
+		if last != c.checksumWord(data) {
+			return nil, fmt.Errorf("%w: a word was misheard or mistyped", ErrChecksumWord)
+		}
+	}
+	return data, nil
+}
+
+// checksumWord picks a word from the SHA-256 of data
+func (c *Codec) checksumWord(data []byte) string {
+	sum := sha256.Sum256(data)
+	n := new(big.Int).SetBytes(sum[:])
+	return c.words[n.Mod(n, big.NewInt(int64(len(c.words)))).Int64()]
+}
+
+// SplitWords lowercases text in NFC and splits it into runs of letters and
+// their combining marks
+func SplitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(norm.NFC.String(text)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.Is(unicode.M, r)
+	})
+}
@@ -0,0 +1,175 @@
+package wordlist
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// codecList returns a list of n distinct letter-only words
+func codecList(n int) *Wordlist {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = fmt.Sprintf("w%c%c%c", 'a'+i/676, 'a'+i/26%26, 'a'+i%26)
+	}
+	return &Wordlist{Source: &WordlistSource{ID: "codec"}, Words: words}
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	inputs := [][]byte{
+		{},
+		{0x00},
+		{0x00, 0x00, 0x01},
+		{0xff},
+		[]byte("recovery key"),
+		bytes.Repeat([]byte{0xa5}, 64),
+	}
+
+	for _, size := range []int{2, 256, 1296, 4096, 7776} {
+		for _, checksum := range []bool{false, true} {
+			codec, err := NewCodec(codecList(size), checksum)
+			require.NoError(t, err)
+
+			for _, data := range inputs {
+				t.Run(fmt.Sprintf("%d words checksum %v len %d", size, checksum, len(data)), func(t *testing.T) {
+					words := codec.Encode(data)
+					decoded, err := codec.Decode(strings.Join(words, " "))
+					require.NoError(t, err)
+					assert.Equal(t, data, decoded)
+				})
+			}
+		}
+	}
+}
+
+func TestCodecWordCount(t *testing.T) {
+	codec, err := NewCodec(codecList(7776), false)
+	require.NoError(t, err)
+	assert.InDelta(t, 12.925, codec.BitsPerWord(), 0.001)
+
+	// The marker makes 32 zero bytes the number 2^256, which takes ceil(257 / 12.925) words
+	assert.Len(t, codec.Encode(make([]byte, 32)), 20)
+}
+
+func TestCodecDecodeTolerance(t *testing.T) {
+	codec, err := NewCodec(codecList(1296), true)
+	require.NoError(t, err)
+
+	data := []byte{0xde, 0xad, 0xbe, 0xef}
+	words := codec.Encode(data)
+
+	inputs := []string{
+		strings.Join(words, " "),
+		strings.ToUpper(strings.Join(words, "-")),
+		"  " + strings.Join(words, "_\n") + ".",
+		strings.Join(words, ", "),
+	}
+	for _, input := range inputs {
+		decoded, err := codec.Decode(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, data, decoded)
+	}
+}
+
+func TestCodecDecodeErrors(t *testing.T) {
+	codec, err := NewCodec(codecList(1296), true)
+	require.NoError(t, err)
+
+	words := codec.Encode([]byte("fingerprint"))
+	swapped := append([]string{}, words...)
+	swapped[1], swapped[2] = swapped[2], swapped[1]
+	wrongSum := append(append([]string{}, words[:len(words)-1]...), "waaa")
+	if wrongSum[len(wrongSum)-1] == words[len(words)-1] {
+		wrongSum[len(wrongSum)-1] = "waab"
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  error
+	}{
+		{"empty", "", ErrCorruptWords},
+		{"checksum only", words[len(words)-1], ErrCorruptWords},
+		{"unknown word", "wzzz " + strings.Join(words[1:], " "), ErrUnknownWord},
+		{"swapped words", strings.Join(swapped, " "), ErrChecksumWord},
+		{"wrong checksum", strings.Join(wrongSum, " "), ErrChecksumWord},
+		{"leading zero digit", "waaa " + strings.Join(words, " "), ErrCorruptWords},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := codec.Decode(tt.input)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestNewCodecErrors(t *testing.T) {
+	_, err := NewCodec(codecList(1), false)
+	assert.ErrorIs(t, err, ErrCodecList)
+
+	dup := &Wordlist{Source: &WordlistSource{ID: "dup"}, Words: []string{"apple", "Apple"}}
+	_, err = NewCodec(dup, false)
+	assert.ErrorIs(t, err, ErrCodecList)
+}
+
+func TestCodecArraysPinned(t *testing.T) {
+	// Pinned here as well as in codecArrays, so that changing an array takes
+	// two deliberate edits: every backup encoded over it stops decoding
+	want := map[string]string{
+		"eff-large":         "18586c092f641ecd1a471dd6ab35618ab69f0aa7483486424f7caf0996d06259",
+		"eff-short-2":       "f6a587145fd0d7aaeff9849e5168d63ef795b03c2e0ce7efe7b2546c1c975e9f",
+		"diceware-de":       "199941eea4269a6fe1aa6de9ae6fc18e9280e6fe2c946e736ab33786feca9417",
+		"diceware-de-short": "d5ae513e39bbfb58305cf9aa68e0a446d57ee7e7930569d1368a85cda4a9ce73",
+		"bip39-es":          "0c639b0d58b6e56c18dcf418017ff341418a129e45fbf303361e8569edb02efe",
+		"bip39-fr":          "431c1d074225d2b7e82db857d7c3ea58051df546e7c8b74c1f6dcab36351fd56",
+		"bip39-it":          "d392c49fdb700a24cd1fceb237c1f65dcc128f6b34a8aacb58b59384b5c648c2",
+		"bip39-ja":          "c58cb719f782910cfcedb6ab64dc506322bd7ce4f7cede5a0563ac06d3d5c884",
+	}
+	assert.Equal(t, want, codecArrays)
+
+	sizes := map[string]int{
+		"eff-large":         7772,
+		"eff-short-2":       1295,
+		"diceware-de":       7776,
+		"diceware-de-short": 1296,
+		"bip39-es":          2048,
+		"bip39-fr":          2048,
+		"bip39-it":          2048,
+		"bip39-ja":          2048,
+	}
+	for _, id := range CodecIDs() {
+		t.Run(id, func(t *testing.T) {
+			words, err := codecArray(id)
+			require.NoError(t, err)
+			assert.Len(t, words, sizes[id])
+			assert.Equal(t, want[id], sha256Hex([]byte(strings.Join(words, "\n")+"\n")))
+
+			_, err = BuiltinCodec(id, true)
+			require.NoError(t, err)
+		})
+	}
+
+	// Every default list can be encoded over, in every build
+	for _, source := range DefaultSources {
+		assert.Contains(t, codecArrays, source.ID)
+	}
+
+	_, err := BuiltinCodec("team", false)
+	assert.ErrorIs(t, err, ErrSourceNotFound)
+}
+
+func TestCodecEFFLargeGolden(t *testing.T) {
+	// A change to the frozen array changes every encoding and must be deliberate
+	codec, err := BuiltinCodec("eff-large", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alphabet", "uptight", "frown"}, codec.Encode([]byte{0xde, 0xad, 0xbe, 0xef}))
+
+	codec, err = BuiltinCodec("eff-large", true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alphabet", "uptight", "frown", "defiant"}, codec.Encode([]byte{0xde, 0xad, 0xbe, 0xef}))
+}
@@ -0,0 +1,145 @@
+package wordlist
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// maxDice bounds the dice per roll accepted in a dice index
+const maxDice = 8
+
+var (
+	// ErrInvalidRoll indicates dice input that is not a roll for the list
+	ErrInvalidRoll = errors.New("invalid dice roll")
+
+	// ErrUnusableRoll indicates a roll whose word cannot be used, so the dice must be rolled again
+	ErrUnusableRoll = errors.New("roll gives an unusable word")
+)
+
+// diceIndex maps the dice rolls of an EFF-style list ("11111 word") to their
+// words in form. Rolls whose word fails validation, or normalises to the word
+// of an earlier roll, map to "". It returns nil unless every entry has a roll
+// and every roll of a single dice count appears exactly once.
+func diceIndex(entries []Entry, form wordForm) map[string]string {
+	index := make(map[string]string, len(entries))
+	seen := make(map[string]bool)
+	dice := 0
+
+	for _, entry := range entries {
+		roll := entry.Roll
+		if !isDiceRoll(roll) {
+			return nil
+		}
+		if dice == 0 {
+			dice = len(roll)
+		}
+		if len(roll) != dice || dice > maxDice {
+			return nil
+		}
+		if _, dup := index[roll]; dup {
+			return nil
+		}
+
+		word, ok := form.normalize(entry.Word)
+		if !ok || seen[word] {
+			word = ""
+		}
+		seen[word] = true
+		index[roll] = word
+	}
+
+	if dice == 0 || len(index) != rollCount(dice) {
+		return nil
+	}
+	return index
+}
+
+// isDiceRoll reports whether s consists only of the digits 1 to 6
+func isDiceRoll(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '1' || r > '6' {
+			return false
+		}
+	}
+	return true
+}
+
+// rollCount returns the number of distinct rolls of the given number of dice
+func rollCount(dice int) int {
+	n := 1
+	for range dice {
+		n *= 6
+	}
+	return n
+}
+
+// DiceCount returns the number of dice rolled per word, or 0 when the list has
+// no complete dice index
+func (w *Wordlist) DiceCount() int {
+	for roll := range w.Dice {
+		return len(roll)
+	}
+	return 0
+}
+
+// NormalizeRoll strips spaces, commas and dashes from dice input and checks
+// that the rest is a roll of the given number of dice
+func NormalizeRoll(input string, dice int) (string, error) {
+	roll := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', ',', '-':
+			return -1
+		}
+		return r
+	}, input)
+
+	if !isDiceRoll(roll) {
+		return "", fmt.Errorf("%w: %q: use the digits 1 to 6", ErrInvalidRoll, input)
+	}
+	if len(roll) != dice {
+		return "", fmt.Errorf("%w: %q: need %d dice, got %d", ErrInvalidRoll, input, dice, len(roll))
+	}
+	return roll, nil
+}
+
+// DiceWord returns the word for dice input such as "1 6 3 2 4"
+func (w *Wordlist) DiceWord(input string) (string, error) {
+	dice := w.DiceCount()
+	if dice == 0 {
+		return "", fmt.Errorf("%w: wordlist %s has no dice index", ErrInvalidRoll, w.Source.ID)
+	}
+
+	roll, err := NormalizeRoll(input, dice)
+	if err != nil {
+		return "", err
+	}
+
+	word := w.Dice[roll]
+	if word == "" {
+		return "", fmt.Errorf("%w: %s in %s", ErrUnusableRoll, roll, w.Source.ID)
+	}
+	return word, nil
+}
+
+// DiceLists returns the loaded lists with a complete dice index, sorted by ID
+func (m *Manager) DiceLists() []*Wordlist {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var lists []*Wordlist
+	for _, wl := range m.loaded {
+		if wl.Dice != nil {
+			lists = append(lists, wl)
+		}
+	}
+
+	slices.SortFunc(lists, func(a, b *Wordlist) int {
+		return strings.Compare(a.Source.ID, b.Source.ID)
+	})
+	return lists
+}
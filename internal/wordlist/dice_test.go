@@ -0,0 +1,133 @@
+package wordlist
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// diceData returns a complete two-dice index whose words are "w" plus the roll
+// spelled in letters, such as "wab" for 12
+func diceData(t *testing.T) string {
+	t.Helper()
+
+	var b strings.Builder
+	for first := '1'; first <= '6'; first++ {
+		for second := '1'; second <= '6'; second++ {
+			_, _ = fmt.Fprintf(&b, "%c%c\tw%c%c\n", first, second, first-'1'+'a', second-'1'+'a')
+		}
+	}
+	return b.String()
+}
+
+func TestParseDiceIndex(t *testing.T) {
+	full := diceData(t)
+
+	tests := []struct {
+		name string
+		data string
+		dice int // expected dice count, 0 for no index
+	}{
+		{name: "complete", data: full, dice: 2},
+		{name: "comments and blank lines", data: "# dice\n\n" + full, dice: 2},
+		{name: "missing roll", data: strings.Join(strings.Split(full, "\n")[1:], "\n")},
+		{name: "duplicate roll", data: strings.Replace(full, "12\twab", "11\twab", 1)},
+		{name: "mixed dice counts", data: strings.Replace(full, "66\twff", "666\twff", 1)},
+		{name: "digit out of range", data: strings.Replace(full, "66\twff", "67\twff", 1)},
+		{name: "plain words", data: "apple\nbanana\n"},
+		{name: "empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := diceIndex(decodeEntries(t, []byte(tt.data)), wordForm{})
+			wl := &Wordlist{Source: &WordlistSource{ID: "test"}, Dice: index}
+			assert.Equal(t, tt.dice, wl.DiceCount())
+			if tt.dice > 0 {
+				assert.Len(t, index, rollCount(tt.dice))
+			}
+		})
+	}
+}
+
+func TestParseDiceIndexInvalidWord(t *testing.T) {
+	data := strings.Replace(diceData(t), "34\twcd", "34\tyo-yo", 1)
+	index := diceIndex(decodeEntries(t, []byte(data)), wordForm{})
+	require.NotNil(t, index)
+
+	wl := &Wordlist{Source: &WordlistSource{ID: "test"}, Dice: index}
+	_, err := wl.DiceWord("34")
+	assert.ErrorIs(t, err, ErrUnusableRoll)
+}
+
+func TestNormalizeRoll(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		dice    int
+		want    string
+		wantErr bool
+	}{
+		{name: "digits", input: "16324", dice: 5, want: "16324"},
+		{name: "spaces", input: " 1 6 3 2 4 ", dice: 5, want: "16324"},
+		{name: "commas and dashes", input: "1,6-3,2-4", dice: 5, want: "16324"},
+		{name: "too few", input: "1632", dice: 5, wantErr: true},
+		{name: "too many", input: "16324", dice: 4, wantErr: true},
+		{name: "zero", input: "10324", dice: 5, wantErr: true},
+		{name: "seven", input: "17324", dice: 5, wantErr: true},
+		{name: "letters", input: "abcde", dice: 5, wantErr: true},
+		{name: "empty", input: "", dice: 5, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeRoll(tt.input, tt.dice)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidRoll)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDiceWord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dice.txt")
+	require.NoError(t, os.WriteFile(path, []byte(diceData(t)), 0600))
+
+	m, err := NewManager(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, m.AddUserWordlist(path, "dice"))
+
+	plain := filepath.Join(t.TempDir(), "plain.txt")
+	require.NoError(t, os.WriteFile(plain, []byte("apple\nbanana\n"), 0600))
+	require.NoError(t, m.AddUserWordlist(plain, "plain"))
+
+	lists := m.DiceLists()
+	require.Len(t, lists, 1)
+	wl := lists[0]
+	assert.Equal(t, 2, wl.DiceCount())
+
+	word, err := wl.DiceWord("1 1")
+	require.NoError(t, err)
+	assert.Equal(t, "waa", word)
+
+	word, err = wl.DiceWord("6-5")
+	require.NoError(t, err)
+	assert.Equal(t, "wfe", word)
+
+	_, err = wl.DiceWord("123")
+	assert.ErrorIs(t, err, ErrInvalidRoll)
+
+	m.mu.RLock()
+	plainList := m.loaded["plain"]
+	m.mu.RUnlock()
+	_, err = plainList.DiceWord("11")
+	assert.ErrorIs(t, err, ErrInvalidRoll)
+}
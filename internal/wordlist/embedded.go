@@ -0,0 +1,87 @@
+package wordlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/greysquirr3l/glyphic/internal/bip39"
+)
+
+// bip39Sources maps the BIP-39 default sources to the lists the bip39 package
+// already embeds, so the binary carries one copy of each
+var bip39Sources = map[string]bip39.Language{
+	"bip39-es": bip39.Spanish,
+	"bip39-fr": bip39.French,
+	"bip39-it": bip39.Italian,
+	"bip39-ja": bip39.Japanese,
+}
+
+// embeddedCopy is an embedded wordlist that matched its manifest pin
+type embeddedCopy struct {
+	sha256 string
+	data   []byte
+}
+
+// verifiedEmbedded reads and hashes every embedded default wordlist once,
+// keeping the copies that match their pinned checksum keyed by source ID
+var verifiedEmbedded = sync.OnceValue(func() map[string]embeddedCopy {
+	verified := make(map[string]embeddedCopy)
+	for _, source := range DefaultSources {
+		if source.SHA256 == "" {
+			continue
+		}
+		data, err := readEmbedded(source.ID)
+		if err != nil || verifyChecksum(data, source) != nil {
+			continue
+		}
+		verified[source.ID] = embeddedCopy{sha256: source.SHA256, data: data}
+	}
+	return verified
+})
+
+// embeddedData returns the embedded copy of a source if one exists and matches its pin.
+// Sources without a pinned checksum are never served from the embedded set.
+func embeddedData(source WordlistSource) ([]byte, bool) {
+	embedded, ok := verifiedEmbedded()[source.ID]
+	if !ok || source.SHA256 != embedded.sha256 {
+		return nil, false
+	}
+	return embedded.data, true
+}
+
+// IsEmbedded reports whether the source has a verified copy compiled into the binary
+func IsEmbedded(source WordlistSource) bool {
+	_, ok := embeddedData(source)
+	return ok
+}
+
+// EmbeddedIDs returns the IDs of the wordlists in language compiled into the
+// binary, or of all of them when the language is empty
+func EmbeddedIDs(language string) []string {
+	var ids []string
+	for _, source := range sourcesFor(DefaultSources, language) {
+		if IsEmbedded(source) {
+			ids = append(ids, source.ID)
+		}
+	}
+	return ids
+}
+
+// Update refreshes every enabled source over HTTPS into the cache, regardless
+// of whether it is embedded or within its TTL. Cached copies are revalidated
+// with a conditional request; each download must match its pinned checksum
+// and signature, and unpinned sources are refused unless allowed.
+func (m *Manager) Update(ctx context.Context) error {
+	var errs []error
+	for _, source := range m.sources {
+		if !m.Enabled(source.ID) {
+			continue
+		}
+		if _, err := m.fetchAndCache(ctx, source); err != nil {
+			errs = append(errs, fmt.Errorf("source %s: %w", source.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,24 @@
+//go:build !slim
+
+package wordlist
+
+import (
+	"embed"
+	"path"
+
+	"github.com/greysquirr3l/glyphic/internal/bip39"
+)
+
+// embeddedWordlists holds offline copies of the default wordlists, named <id>.txt.
+// Build with -tags slim to leave them out.
+//
+//go:embed embedded/*.txt
+var embeddedWordlists embed.FS
+
+// readEmbedded returns the embedded file of a default source
+func readEmbedded(id string) ([]byte, error) {
+	if lang, ok := bip39Sources[id]; ok {
+		return bip39.File(lang)
+	}
+	return embeddedWordlists.ReadFile(path.Join("embedded", id+".txt"))
+}
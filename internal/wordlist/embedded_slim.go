@@ -0,0 +1,17 @@
+//go:build slim
+
+package wordlist
+
+import (
+	"embed"
+	"path"
+)
+
+// embeddedWordlists is empty in slim builds, so every wordlist is fetched over HTTPS
+var embeddedWordlists embed.FS
+
+// readEmbedded returns the embedded file of a default source, which slim
+// builds never have
+func readEmbedded(id string) ([]byte, error) {
+	return embeddedWordlists.ReadFile(path.Join("embedded", id+".txt"))
+}
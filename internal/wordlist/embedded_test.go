@@ -0,0 +1,219 @@
+//go:build !slim
+
+package wordlist
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/greysquirr3l/glyphic/internal/bip39"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedWordlistsMatchManifest(t *testing.T) {
+	entries, err := embeddedWordlists.ReadDir("embedded")
+	require.NoError(t, err)
+
+	sources := make(map[string]WordlistSource)
+	for _, source := range DefaultSources {
+		sources[source.ID] = source
+	}
+
+	ids := slices.Sorted(maps.Keys(bip39Sources))
+	for _, entry := range entries {
+		ids = append(ids, strings.TrimSuffix(entry.Name(), ".txt"))
+	}
+
+	for _, id := range ids {
+		t.Run(id, func(t *testing.T) {
+			source, ok := sources[id]
+			require.True(t, ok, "embedded file has no manifest entry")
+			require.True(t, source.Pinned(), "embedded wordlists must be pinned")
+
+			data, err := readEmbedded(id)
+			require.NoError(t, err)
+			assert.NoError(t, verifyChecksum(data, source))
+			assert.True(t, IsEmbedded(source))
+
+			// Every line is a word, optionally after a dice index, and every
+			// word survives loading
+			lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+			assert.Len(t, lines, source.WordCount)
+			for _, line := range lines {
+				fields := strings.Fields(line)
+				require.NotEmpty(t, fields, line)
+				require.LessOrEqual(t, len(fields), 2, line)
+				if len(fields) == 2 {
+					assert.True(t, isAllDigits(fields[0]), line)
+				}
+			}
+		})
+	}
+
+	// The dice index survives loading, with the hyphenated words unusable
+	m, err := NewManager(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, m.LoadAll())
+	dice := make(map[string]*Wordlist)
+	for _, wl := range m.DiceLists() {
+		dice[wl.Source.ID] = wl
+	}
+	require.Contains(t, dice, "eff-large")
+	require.Contains(t, dice, "eff-short-2")
+	assert.Equal(t, 5, dice["eff-large"].DiceCount())
+	assert.Equal(t, 4, dice["eff-short-2"].DiceCount())
+
+	word, err := dice["eff-large"].DiceWord("11111")
+	require.NoError(t, err)
+	assert.Equal(t, "abacus", word)
+	_, err = dice["eff-large"].DiceWord("61534")
+	assert.ErrorIs(t, err, ErrUnusableRoll)
+
+	assert.Contains(t, EmbeddedIDs(DefaultLanguage), "eff-large")
+	assert.Contains(t, EmbeddedIDs(DefaultLanguage), "eff-short-2")
+}
+
+func TestEmbeddedWordlistsLoadEveryWord(t *testing.T) {
+	// The hyphenated EFF words, such as "t-shirt" and "yo-yo", are the only
+	// entries a default list loses
+	hyphenated := map[string]int{"eff-large": 4, "eff-short-2": 1}
+
+	m, err := NewManager(t.TempDir())
+	require.NoError(t, err)
+	m.SetLanguage("")
+	require.NoError(t, m.LoadAll())
+
+	ids := EmbeddedIDs("")
+	require.NotEmpty(t, ids)
+	for _, id := range ids {
+		t.Run(id, func(t *testing.T) {
+			wl, ok := m.Loaded(id)
+			require.True(t, ok)
+			assert.Len(t, wl.Words, wl.Source.WordCount-hyphenated[id])
+			if wl.Dice != nil {
+				assert.Equal(t, hyphenated[id], countHoles(wl.Dice), "dice rolls without a word")
+			}
+		})
+	}
+}
+
+// countHoles returns the rolls of a dice index that map to no word
+func countHoles(dice map[string]string) int {
+	holes := 0
+	for _, word := range dice {
+		if word == "" {
+			holes++
+		}
+	}
+	return holes
+}
+
+func TestBIP39SourcesShareBIP39Files(t *testing.T) {
+	for id, lang := range bip39Sources {
+		t.Run(id, func(t *testing.T) {
+			i := slices.IndexFunc(DefaultSources, func(s WordlistSource) bool { return s.ID == id })
+			require.GreaterOrEqual(t, i, 0, "no manifest entry")
+			source := DefaultSources[i]
+
+			data, err := bip39.File(lang)
+			require.NoError(t, err)
+			assert.Equal(t, source.SHA256, sha256Hex(data), "manifest and bip39 package pin different files")
+			assert.True(t, IsEmbedded(source))
+
+			// The file is embedded once, by the bip39 package
+			_, err = embeddedWordlists.ReadFile(path.Join("embedded", id+".txt"))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestIsEmbeddedRequiresPin(t *testing.T) {
+	source := DefaultSources[0]
+	require.True(t, IsEmbedded(source))
+
+	repinned := source
+	repinned.SHA256 = strings.Repeat("0", 64)
+	assert.False(t, IsEmbedded(repinned))
+
+	unpinned := source
+	unpinned.SHA256 = ""
+	assert.False(t, IsEmbedded(unpinned))
+}
+
+func TestEFFLargeTags(t *testing.T) {
+	m, err := NewManager(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, m.LoadAll())
+
+	// Every word of the sidecar is in the list, and each template tag has a
+	// pool of its own
+	list := m.loaded["eff-large"]
+	tagged := 0
+	for _, tag := range []string{"adj", "noun", "verb", "adv"} {
+		assert.GreaterOrEqual(t, len(list.Tags[tag]), 100, tag)
+		tagged += len(list.Tags[tag])
+	}
+	assert.Equal(t, len(tagSidecars()["eff-large"]), tagged)
+}
+
+func TestLoadAllPrefersEmbedded(t *testing.T) {
+	m, err := NewManager(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, m.LoadAll())
+	for _, id := range EmbeddedIDs(DefaultLanguage) {
+		require.Contains(t, m.loaded, id)
+		assert.NotEmpty(t, m.loaded[id].Words)
+	}
+	assert.Greater(t, len(m.loaded["eff-large"].Words), 7000)
+}
+
+func TestEnsureWordlistsReportsFailedFetches(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	m, err := NewManager(t.TempDir())
+	require.NoError(t, err)
+	m.client = server.Client()
+	m.sources = append(m.sources, WordlistSource{ID: "extra", SHA256: sha256Hex([]byte("x"))})
+	for i := range m.sources {
+		m.sources[i].URL = server.URL + "/" + m.sources[i].ID + ".txt"
+	}
+
+	// Embedded sources are skipped, so the one fetched source failing is a total failure
+	err = m.EnsureWordlists(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "source extra")
+}
+
+func TestEnsureWordlistsSkipsEmbedded(t *testing.T) {
+	var hits int
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	m, err := NewManager(t.TempDir())
+	require.NoError(t, err)
+	m.client = server.Client()
+	for i := range m.sources {
+		m.sources[i].URL = server.URL + "/" + m.sources[i].ID + ".txt"
+	}
+
+	// Embedded sources need no network, and unpinned ones are refused before fetching
+	require.NoError(t, m.EnsureWordlists(context.Background()))
+	assert.Zero(t, hits)
+
+	// Update always goes to the network
+	err = m.Update(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, len(m.sources)-len(m.UnpinnedSources()), hits)
+}
@@ -8,6 +8,9 @@ import (
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 //go:embed exclusions/*.txt
@@ -15,8 +18,9 @@ var embeddedExclusions embed.FS
 
 // ExclusionList manages words to exclude from password generation
 type ExclusionList struct {
-	words []string // Sorted for binary search
-	mu    sync.RWMutex
+	words   []string // Sorted for binary search
+	version atomic.Uint64
+	mu      sync.RWMutex
 }
 
 // NewExclusionList creates a new exclusion list
@@ -50,7 +54,7 @@ func loadDefaultExclusions() []string {
 		for scanner.Scan() {
 			line := strings.TrimSpace(scanner.Text())
 			if line != "" && !strings.HasPrefix(line, "#") {
-				words = append(words, strings.ToLower(line))
+				words = append(words, exclusionKey(line))
 			}
 		}
 	}
@@ -75,13 +79,14 @@ func (e *ExclusionList) LoadFile(path string) error {
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 		if line != "" && !strings.HasPrefix(line, "#") {
-			e.words = append(e.words, strings.ToLower(line))
+			e.words = append(e.words, exclusionKey(line))
 		}
 	}
 
 	// Re-sort and compact after adding new words
 	slices.Sort(e.words)
 	e.words = slices.Compact(e.words)
+	e.version.Add(1)
 
 	return scanner.Err()
 }
@@ -92,11 +97,12 @@ func (e *ExclusionList) Add(words ...string) {
 	defer e.mu.Unlock()
 
 	for _, word := range words {
-		e.words = append(e.words, strings.ToLower(word))
+		e.words = append(e.words, exclusionKey(word))
 	}
 
 	slices.Sort(e.words)
 	e.words = slices.Compact(e.words)
+	e.version.Add(1)
 }
 
 // Contains checks if a word is in the exclusion list
@@ -104,19 +110,35 @@ func (e *ExclusionList) Contains(word string) bool {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
-	_, found := slices.BinarySearch(e.words, strings.ToLower(word))
+	_, found := slices.BinarySearch(e.words, exclusionKey(word))
 	return found
 }
 
-// Filter removes excluded words from a slice
+// Filter returns a new slice holding the words that are not excluded.
+// The input slice is never modified.
 func (e *ExclusionList) Filter(words []string) []string {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
-	return slices.DeleteFunc(words, func(word string) bool {
-		_, found := slices.BinarySearch(e.words, strings.ToLower(word))
-		return found
-	})
+	filtered := make([]string, 0, len(words))
+	for _, word := range words {
+		if _, found := slices.BinarySearch(e.words, exclusionKey(word)); !found {
+			filtered = append(filtered, word)
+		}
+	}
+	return filtered
+}
+
+// Words returns a sorted copy of the excluded words
+func (e *ExclusionList) Words() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return slices.Clone(e.words)
+}
+
+// Version returns a counter that changes whenever the exclusion list is modified
+func (e *ExclusionList) Version() uint64 {
+	return e.version.Load()
 }
 
 // Count returns the number of excluded words
@@ -131,4 +153,10 @@ func (e *ExclusionList) Disable() {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	e.words = nil
+	e.version.Add(1)
+}
+
+// exclusionKey returns the form words are compared in: lower case in NFC
+func exclusionKey(word string) string {
+	return strings.ToLower(norm.NFC.String(word))
 }
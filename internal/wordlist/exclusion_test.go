@@ -71,6 +71,17 @@ func TestExclusionListAdd(t *testing.T) {
 	}
 }
 
+func TestExclusionListWords(t *testing.T) {
+	list := NewExclusionList(false)
+	list.Add("Cherry", "apple")
+
+	words := list.Words()
+	assert.Equal(t, []string{"apple", "cherry"}, words)
+
+	words[0] = "changed"
+	assert.True(t, list.Contains("apple"), "Words must return a copy")
+}
+
 func TestExclusionListContains(t *testing.T) {
 	list := NewExclusionList(false)
 	list.Add("apple", "banana", "cherry")
@@ -224,3 +235,42 @@ func TestFilterPreservesOrder(t *testing.T) {
 	got := list.Filter(input)
 	assert.Equal(t, expected, got)
 }
+
+func TestFilterDoesNotModifyInput(t *testing.T) {
+	list := NewExclusionList(false)
+	list.Add("bad")
+
+	input := []string{"bad", "good", "bad", "fine"}
+	got := list.Filter(input)
+	assert.Equal(t, []string{"good", "fine"}, got)
+	assert.Equal(t, []string{"bad", "good", "bad", "fine"}, input)
+
+	// Filtering the same input again gives the same result
+	assert.Equal(t, got, list.Filter(input))
+
+	empty := list.Filter([]string{"bad"})
+	assert.NotNil(t, empty)
+	assert.Empty(t, empty)
+}
+
+func TestExclusionListVersion(t *testing.T) {
+	list := NewExclusionList(false)
+	v := list.Version()
+
+	list.Add("bad")
+	assert.Greater(t, list.Version(), v)
+	v = list.Version()
+
+	path := filepath.Join(t.TempDir(), "exclusions.txt")
+	require.NoError(t, os.WriteFile(path, []byte("worse\n"), 0600))
+	require.NoError(t, list.LoadFile(path))
+	assert.Greater(t, list.Version(), v)
+	v = list.Version()
+
+	list.Contains("bad")
+	list.Filter([]string{"bad"})
+	assert.Equal(t, v, list.Version())
+
+	list.Disable()
+	assert.Greater(t, list.Version(), v)
+}
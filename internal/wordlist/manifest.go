@@ -0,0 +1,98 @@
+package wordlist
+
+import (
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+// ErrInvalidManifest indicates the wordlist manifest is malformed
+var ErrInvalidManifest = errors.New("invalid wordlist manifest")
+
+// validID matches source IDs, which name files in the cache directory
+var validID = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+//go:embed manifest.json
+var manifestData []byte
+
+// Manifest is the versioned list of wordlist sources shipped with glyphic
+type Manifest struct {
+	Version int              `json:"version"`
+	Sources []WordlistSource `json:"sources"`
+}
+
+// embeddedManifest is the manifest compiled into the binary
+var embeddedManifest = mustParseManifest(manifestData)
+
+// ManifestVersion is the version of the embedded manifest
+var ManifestVersion = embeddedManifest.Version
+
+// ParseManifest decodes and validates a wordlist manifest
+func ParseManifest(data []byte) (*Manifest, error) {
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidManifest, err)
+	}
+
+	if manifest.Version < 1 {
+		return nil, fmt.Errorf("%w: version must be at least 1", ErrInvalidManifest)
+	}
+
+	seen := make(map[string]bool)
+	for _, source := range manifest.Sources {
+		if err := validateSource(source); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidManifest, err)
+		}
+		if seen[source.ID] {
+			return nil, fmt.Errorf("%w: duplicate source %q", ErrInvalidManifest, source.ID)
+		}
+		seen[source.ID] = true
+	}
+
+	return &manifest, nil
+}
+
+// validateSource checks a single manifest entry
+func validateSource(source WordlistSource) error {
+	if !validID.MatchString(source.ID) {
+		return fmt.Errorf("source id %q must be lowercase letters, digits, '.', '_' or '-'", source.ID)
+	}
+
+	u, err := url.Parse(source.URL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("source %s: url must be https", source.ID)
+	}
+
+	if source.SHA256 != "" {
+		sum, err := hex.DecodeString(source.SHA256)
+		if err != nil || len(sum) != 32 || hex.EncodeToString(sum) != source.SHA256 {
+			return fmt.Errorf("source %s: sha256 must be 64 lowercase hex characters", source.ID)
+		}
+	}
+
+	if source.PublicKey != "" {
+		if _, err := ParsePublicKey(source.PublicKey); err != nil {
+			return fmt.Errorf("source %s: %w", source.ID, err)
+		}
+	}
+	if source.SignatureURL != "" {
+		if u, err := url.Parse(source.SignatureURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("source %s: signature url must be https", source.ID)
+		}
+	}
+
+	return nil
+}
+
+// mustParseManifest parses the embedded manifest, which is validated by tests
+func mustParseManifest(data []byte) *Manifest {
+	manifest, err := ParseManifest(data)
+	if err != nil {
+		panic(err)
+	}
+	return manifest
+}
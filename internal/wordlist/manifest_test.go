@@ -0,0 +1,70 @@
+package wordlist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedManifest(t *testing.T) {
+	manifest, err := ParseManifest(manifestData)
+	require.NoError(t, err)
+
+	assert.Equal(t, ManifestVersion, manifest.Version)
+	assert.Equal(t, manifest.Sources, DefaultSources)
+
+	ids := make([]string, 0, len(manifest.Sources))
+	for _, source := range manifest.Sources {
+		ids = append(ids, source.ID)
+	}
+	assert.Contains(t, ids, "eff-large")
+
+	// A default without a pin would be skipped on every run unless
+	// --insecure-unpinned is given
+	for _, source := range DefaultSources {
+		assert.True(t, source.Pinned(), "default source %s has no pinned checksum or key", source.ID)
+	}
+
+	for _, source := range manifest.Sources {
+		if source.ID == "eff-large" {
+			assert.True(t, source.Pinned())
+			assert.Equal(t, 7776, source.WordCount)
+		}
+	}
+}
+
+func TestParseManifestErrors(t *testing.T) {
+	const sum = "addd35536511597a02fa0a9ff1e5284677b8883b83e986e43f15a3db996b903e"
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"malformed json", `{"version": 1,`},
+		{"missing version", `{"sources": []}`},
+		{"missing id", `{"version": 1, "sources": [{"url": "https://example.com/a.txt"}]}`},
+		{"plain http", `{"version": 1, "sources": [{"id": "a", "url": "http://example.com/a.txt"}]}`},
+		{"short checksum", `{"version": 1, "sources": [{"id": "a", "url": "https://example.com/a.txt", "sha256": "abcd"}]}`},
+		{"uppercase checksum", `{"version": 1, "sources": [{"id": "a", "url": "https://example.com/a.txt", "sha256": "ADDD35536511597A02FA0A9FF1E5284677B8883B83E986E43F15A3DB996B903E"}]}`},
+		{"placeholder checksum", `{"version": 1, "sources": [{"id": "a", "url": "https://example.com/a.txt", "sha256": "replacewithactual"}]}`},
+		{"duplicate id", `{"version": 1, "sources": [
+			{"id": "a", "url": "https://example.com/a.txt", "sha256": "` + sum + `"},
+			{"id": "a", "url": "https://example.com/b.txt"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseManifest([]byte(tt.data))
+			assert.ErrorIs(t, err, ErrInvalidManifest)
+		})
+	}
+}
+
+func TestParseManifestUnpinned(t *testing.T) {
+	manifest, err := ParseManifest([]byte(`{"version": 2, "sources": [{"id": "a", "url": "https://example.com/a.txt"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, 2, manifest.Version)
+	require.Len(t, manifest.Sources, 1)
+	assert.False(t, manifest.Sources[0].Pinned())
+}
@@ -0,0 +1,111 @@
+package wordlist
+
+import (
+	"cmp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultLanguage is the language of the sources a new Manager draws from
+const DefaultLanguage = "en"
+
+// foldings spells out letters that have no decomposition into a base letter
+// and a diacritic
+var foldings = map[rune]string{
+	'ß': "ss",
+	'æ': "ae",
+	'œ': "oe",
+	'ø': "o",
+	'ł': "l",
+	'đ': "d",
+	'ð': "d",
+	'þ': "th",
+	'ı': "i",
+}
+
+// wordForm controls how the words of a list are normalised
+type wordForm struct {
+	language  string // selects the case rules, see CaseRules
+	fold      bool   // strip diacritics from Latin letters
+	maxLength int    // longest word in characters, maxWordLength when 0
+}
+
+// normalize returns word in NFC and lower case, with diacritics folded when
+// requested. ok is false when the result is not a valid word.
+func (f wordForm) normalize(word string) (string, bool) {
+	word = strings.ToLowerSpecial(CaseRules(f.language), norm.NFC.String(word))
+	if f.fold {
+		word = foldDiacritics(word)
+	}
+	if !isValidWord(word, cmp.Or(f.maxLength, maxWordLength)) {
+		return "", false
+	}
+	return word, true
+}
+
+// foldDiacritics strips the diacritics of Latin letters, so "café" becomes
+// "cafe" and "straße" becomes "strasse". Marks on other scripts, such as the
+// dakuten of Japanese kana, change the letter and are kept.
+func foldDiacritics(word string) string {
+	var b strings.Builder
+	latin := false
+	for _, r := range norm.NFD.String(word) {
+		if unicode.Is(unicode.Mn, r) {
+			if !latin {
+				b.WriteRune(r)
+			}
+			continue
+		}
+		latin = unicode.Is(unicode.Latin, r)
+		if s, ok := foldings[r]; ok {
+			b.WriteString(s)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return norm.NFC.String(b.String())
+}
+
+// CaseRules returns the case mapping of a language such as "tr" or "tr-TR".
+// Turkish and Azerbaijani map dotted İ to i and dotless I to ı; other
+// languages use the default Unicode mapping and get nil.
+func CaseRules(language string) unicode.SpecialCase {
+	switch baseLanguage(language) {
+	case "tr", "az":
+		return unicode.TurkishCase
+	default:
+		return nil
+	}
+}
+
+// baseLanguage returns the lowercase primary subtag of a language tag, "pt"
+// for "pt-BR"
+func baseLanguage(language string) string {
+	base, _, _ := strings.Cut(strings.ReplaceAll(language, "_", "-"), "-")
+	return strings.ToLower(base)
+}
+
+// maxWordLength is the longest word, in characters, a list contributes when
+// its source declares no max_length. Sources that declare one keep every word
+// up to it, so German compounds of up to 19 letters are not dropped.
+const maxWordLength = 12
+
+// isValidWord validates a word for inclusion in the wordlist: 2 to maxLength
+// characters, a letter followed by letters or combining marks
+func isValidWord(s string, maxLength int) bool {
+	if n := utf8.RuneCountInString(s); n < 2 || n > maxLength {
+		return false
+	}
+
+	for i, r := range s {
+		if unicode.IsLetter(r) || i > 0 && unicode.Is(unicode.M, r) {
+			continue
+		}
+		return false
+	}
+
+	return true
+}
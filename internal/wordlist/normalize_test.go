@@ -0,0 +1,99 @@
+package wordlist
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		form wordForm
+		word string
+		want string
+		ok   bool
+	}{
+		{name: "lower case", word: "Apple", want: "apple", ok: true},
+		{name: "composes", word: "an\u0303o", want: "año", ok: true},
+		{name: "keeps diacritics", word: "Straße", want: "straße", ok: true},
+		{name: "folds", form: wordForm{fold: true}, word: "Éléphant", want: "elephant", ok: true},
+		{name: "folds ligature", form: wordForm{fold: true}, word: "Straße", want: "strasse", ok: true},
+		{name: "keeps dakuten", form: wordForm{fold: true}, word: "がき", want: "がき", ok: true},
+		{name: "turkish dotted", form: wordForm{language: "tr"}, word: "İZMİR", want: "izmir", ok: true},
+		{name: "turkish dotless", form: wordForm{language: "tr-TR"}, word: "KIŞ", want: "kış", ok: true},
+		{name: "default dotless", word: "KIŞ", want: "kiş", ok: true},
+		{name: "folds dotless", form: wordForm{language: "tr", fold: true}, word: "KIŞ", want: "kis", ok: true},
+		{name: "invalid", word: "l'eau"},
+		{name: "too long once folded", form: wordForm{fold: true}, word: "großbaustelle"},
+		{name: "declared length", form: wordForm{maxLength: 19}, word: "Abfallbeseitigung", want: "abfallbeseitigung", ok: true},
+		{name: "beyond declared length", form: wordForm{maxLength: 8}, word: "aufdrehen"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.form.normalize(tt.word)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCaseRules(t *testing.T) {
+	assert.NotNil(t, CaseRules("tr"))
+	assert.NotNil(t, CaseRules("AZ"))
+	assert.NotNil(t, CaseRules("tr_TR"))
+	assert.Nil(t, CaseRules("en"))
+	assert.Nil(t, CaseRules(""))
+}
+
+func TestParseWordlistUnicode(t *testing.T) {
+	data := []byte("1 café\n2 café\n3 cafe\n4 Ñandú\n5 déjà\n6 niño\n")
+
+	assert.Equal(t, []string{"cafe", "café", "déjà", "niño", "ñandú"}, entryWords(decodeEntries(t, data), wordForm{}))
+	assert.Equal(t, []string{"cafe", "deja", "nandu", "nino"}, entryWords(decodeEntries(t, data), wordForm{fold: true}))
+
+	// Rolls whose word merges with an earlier one are unusable
+	index := diceIndex(decodeEntries(t, data), wordForm{fold: true})
+	require.NotNil(t, index)
+	assert.Equal(t, "cafe", index["1"])
+	assert.Empty(t, index["2"])
+	assert.Empty(t, index["3"])
+	assert.Equal(t, "nandu", index["4"])
+}
+
+func TestManagerLanguage(t *testing.T) {
+	m, err := NewManager(t.TempDir())
+	require.NoError(t, err)
+
+	m.SetLanguage("es")
+	for _, source := range m.ListSources() {
+		assert.Equal(t, "es", source.Language)
+	}
+	require.NoError(t, m.LoadAll())
+	if len(EmbeddedIDs("es")) > 0 {
+		wl, ok := m.Loaded("bip39-es")
+		require.True(t, ok)
+		assert.Len(t, wl.Words, 2048)
+		assert.Contains(t, wl.Words, "café")
+	}
+
+	m.SetLanguage("")
+	assert.Len(t, m.ListSources(), len(DefaultSources))
+	assert.Contains(t, Languages(), "de")
+	assert.Contains(t, Languages(), "en")
+
+	// User wordlists follow the language and folding of the manager
+	path := filepath.Join(t.TempDir(), "tr.txt")
+	require.NoError(t, os.WriteFile(path, []byte("IŞIK\nİĞNE\nKAPI\n"), 0600))
+	m.SetLanguage("tr")
+	m.SetFoldDiacritics(true)
+	require.NoError(t, m.AddUserWordlist(path, "turkish"))
+	wl, ok := m.Loaded("turkish")
+	require.True(t, ok)
+	assert.Equal(t, "tr", wl.Source.Language)
+	assert.Equal(t, []string{"igne", "isik", "kapi"}, wl.Words)
+}
@@ -0,0 +1,553 @@
+package wordlist
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// maxWordlistSize bounds the decompressed size of a wordlist file
+const maxWordlistSize = 64 << 20
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Entry is one word of a wordlist file with its optional columns
+type Entry struct {
+	Word  string
+	Roll  string            // dice roll such as "11111", empty when the format has none
+	Tags  []string          // part-of-speech tags such as "noun"
+	Extra map[string]string // other columns, such as "frequency"
+}
+
+// Header describes a wordlist file. Empty fields are not declared.
+type Header struct {
+	Name     string
+	Language string
+	Category string
+	Count    int // expected number of entries
+}
+
+// File is a decoded wordlist file
+type File struct {
+	Format  string // name of the parser that decoded it
+	Header  Header
+	Entries []Entry
+}
+
+// Parser decodes one wordlist file format
+type Parser interface {
+	// Name identifies the format, such as "plain" or "json"
+	Name() string
+
+	// Detect reports whether the file looks like this format. The path has
+	// any compression extension removed and may be empty.
+	Detect(path string, data []byte) bool
+
+	// Parse decodes the file. Line-based formats get the data after any
+	// front matter; JSON may declare its own header.
+	Parse(data []byte) (*File, error)
+}
+
+var (
+	parsersMu sync.RWMutex
+
+	// parsers are tried in order; PlainParser accepts anything
+	parsers = []Parser{JSONParser{}, CSVParser{}, DiceParser{}, PlainParser{}}
+)
+
+// RegisterParser adds a parser that is tried before the built-in ones
+func RegisterParser(p Parser) {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+	parsers = append([]Parser{p}, parsers...)
+}
+
+// Decode decompresses a wordlist file, reads its front matter and decodes it
+// with the first parser that detects it. The path selects the format by
+// extension (.gz, .json, .csv, .tsv) and may be empty. A declared count must
+// match the number of entries.
+func Decode(path string, data []byte) (*File, error) {
+	path, data, err := decompress(path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	header, body, err := splitFrontMatter(data)
+	if err != nil {
+		return nil, err
+	}
+
+	parsersMu.RLock()
+	candidates := slices.Clone(parsers)
+	parsersMu.RUnlock()
+
+	for _, p := range candidates {
+		if !p.Detect(path, body) {
+			continue
+		}
+
+		file, err := p.Parse(body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidWordlist, p.Name(), err)
+		}
+		file.Format = p.Name()
+		file.Header = header.merge(file.Header)
+
+		if file.Header.Count > 0 && file.Header.Count != len(file.Entries) {
+			return nil, fmt.Errorf("%w: header declares %d words, found %d", ErrInvalidWordlist, file.Header.Count, len(file.Entries))
+		}
+		return file, nil
+	}
+	return nil, fmt.Errorf("%w: unknown format", ErrInvalidWordlist)
+}
+
+// decompress unpacks gzip and zstd data, detected by extension or magic
+// bytes, and strips the compression extension from path
+func decompress(path string, data []byte) (string, []byte, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	var r io.Reader
+	switch {
+	case ext == ".zst" || bytes.HasPrefix(data, zstdMagic):
+		zr, err := zstd.NewReader(bytes.NewReader(data), zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(maxWordlistSize))
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: %w", ErrInvalidWordlist, err)
+		}
+		defer zr.Close()
+		r = zr
+	case ext == ".gz" || bytes.HasPrefix(data, gzipMagic):
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: %w", ErrInvalidWordlist, err)
+		}
+		defer func() { _ = zr.Close() }()
+		r = zr
+	default:
+		return path, data, nil
+	}
+
+	out, err := io.ReadAll(io.LimitReader(r, maxWordlistSize+1))
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %w", ErrInvalidWordlist, err)
+	}
+	if len(out) > maxWordlistSize {
+		return "", nil, fmt.Errorf("%w: decompresses to more than %d bytes", ErrInvalidWordlist, maxWordlistSize)
+	}
+	if ext == ".gz" || ext == ".zst" {
+		path = strings.TrimSuffix(path, filepath.Ext(path))
+	}
+	return path, out, nil
+}
+
+// splitFrontMatter reads an optional header between two "---" lines at the
+// start of data, as in
+//
+//	---
+//	name: Garden words
+//	language: en
+//	category: nature
+//	count: 1296
+//	---
+//
+// Unknown keys are ignored.
+func splitFrontMatter(data []byte) (Header, []byte, error) {
+	var header Header
+	rest, ok := cutLine(data, "---")
+	if !ok {
+		return header, data, nil
+	}
+
+	for len(rest) > 0 {
+		line, next, _ := bytes.Cut(rest, []byte("\n"))
+		rest = next
+
+		text := strings.TrimSpace(string(line))
+		if text == "---" {
+			return header, rest, nil
+		}
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(text, ":")
+		if !found {
+			return header, nil, fmt.Errorf("%w: front matter line %q is not key: value", ErrInvalidWordlist, text)
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "name":
+			header.Name = value
+		case "language":
+			header.Language = value
+		case "category":
+			header.Category = value
+		case "count":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return header, nil, fmt.Errorf("%w: front matter count %q", ErrInvalidWordlist, value)
+			}
+			header.Count = n
+		}
+	}
+	return header, nil, fmt.Errorf("%w: front matter is not closed by ---", ErrInvalidWordlist)
+}
+
+// cutLine returns the data after its first line when that line is want
+func cutLine(data []byte, want string) ([]byte, bool) {
+	line, rest, _ := bytes.Cut(data, []byte("\n"))
+	if strings.TrimSpace(string(line)) != want {
+		return nil, false
+	}
+	return rest, true
+}
+
+// merge fills the empty fields of h from other
+func (h Header) merge(other Header) Header {
+	if h.Name == "" {
+		h.Name = other.Name
+	}
+	if h.Language == "" {
+		h.Language = other.Language
+	}
+	if h.Category == "" {
+		h.Category = other.Category
+	}
+	if h.Count == 0 {
+		h.Count = other.Count
+	}
+	return h
+}
+
+// hasExt reports whether path ends in one of exts, ignoring case
+func hasExt(path string, exts ...string) bool {
+	return slices.Contains(exts, strings.ToLower(filepath.Ext(path)))
+}
+
+// PlainParser reads one word per line with an optional tag column, as in
+// "apple" or "run noun,verb". Blank lines and # comments are skipped, and a
+// leading number column is dropped.
+type PlainParser struct{}
+
+// Name implements Parser
+func (PlainParser) Name() string { return "plain" }
+
+// Detect implements Parser and accepts any file
+func (PlainParser) Detect(string, []byte) bool { return true }
+
+// Parse implements Parser
+func (PlainParser) Parse(data []byte) (*File, error) {
+	entries := parseLines(data)
+	for i := range entries {
+		entries[i].Roll = ""
+	}
+	return &File{Entries: entries}, nil
+}
+
+// DiceParser reads EFF-style lists of a dice roll, a word and an optional
+// tag column, as in "11111 abacus", keeping the rolls
+type DiceParser struct{}
+
+// Name implements Parser
+func (DiceParser) Name() string { return "dice" }
+
+// Detect implements Parser: the first word line starts with a dice roll
+func (DiceParser) Detect(_ string, data []byte) bool {
+	entries := parseLines(firstLines(data, 1))
+	return len(entries) == 1 && isDiceRoll(entries[0].Roll)
+}
+
+// Parse implements Parser
+func (DiceParser) Parse(data []byte) (*File, error) {
+	return &File{Entries: parseLines(data)}, nil
+}
+
+// firstLines returns the data up to and including the first n lines that are
+// neither blank nor comments
+func firstLines(data []byte, n int) []byte {
+	end := 0
+	for n > 0 && end < len(data) {
+		line, _, found := bytes.Cut(data[end:], []byte("\n"))
+		end += len(line)
+		if found {
+			end++
+		}
+		if text := strings.TrimSpace(string(line)); text != "" && !strings.HasPrefix(text, "#") {
+			n--
+		}
+	}
+	return data[:end]
+}
+
+// parseLines reads the entries of a line-based list, recording a leading
+// number column as the roll. Malformed lines are skipped.
+func parseLines(data []byte) []Entry {
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var entry Entry
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && isAllDigits(fields[0]) {
+			entry.Roll, fields = fields[0], fields[1:]
+		}
+
+		switch {
+		case len(fields) == 1:
+			entry.Word = fields[0]
+		case len(fields) == 2 && isTagColumn(fields[1]):
+			entry.Word = fields[0]
+			entry.Tags, _ = splitTags(fields[1])
+		case entry.Roll != "":
+			entry.Word = fields[0] // "11111 word" followed by notes
+		default:
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// JSONParser reads a JSON array of words or of objects with a "word" key, or
+// an object holding such an array under "words" alongside "name",
+// "language", "category" and "count". Objects may have "roll" and "tags"
+// keys; other keys are kept as extra columns.
+type JSONParser struct{}
+
+// Name implements Parser
+func (JSONParser) Name() string { return "json" }
+
+// Detect implements Parser
+func (JSONParser) Detect(path string, data []byte) bool {
+	if hasExt(path, ".json") {
+		return true
+	}
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{')
+}
+
+// Parse implements Parser
+func (JSONParser) Parse(data []byte) (*File, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var doc struct {
+			Name     string            `json:"name"`
+			Language string            `json:"language"`
+			Category string            `json:"category"`
+			Count    int               `json:"count"`
+			Words    []json.RawMessage `json:"words"`
+		}
+		if err := json.Unmarshal(trimmed, &doc); err != nil {
+			return nil, err
+		}
+		entries, err := jsonEntries(doc.Words)
+		if err != nil {
+			return nil, err
+		}
+		header := Header{Name: doc.Name, Language: doc.Language, Category: doc.Category, Count: doc.Count}
+		return &File{Header: header, Entries: entries}, nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(trimmed, &items); err != nil {
+		return nil, err
+	}
+	entries, err := jsonEntries(items)
+	if err != nil {
+		return nil, err
+	}
+	return &File{Entries: entries}, nil
+}
+
+// jsonEntries decodes array items that are words or word objects
+func jsonEntries(items []json.RawMessage) ([]Entry, error) {
+	entries := make([]Entry, 0, len(items))
+	for i, item := range items {
+		var word string
+		if err := json.Unmarshal(item, &word); err == nil {
+			entries = append(entries, Entry{Word: word})
+			continue
+		}
+
+		var fields map[string]any
+		if err := json.Unmarshal(item, &fields); err != nil {
+			return nil, fmt.Errorf("item %d is neither a word nor an object", i)
+		}
+
+		var entry Entry
+		for key, value := range fields {
+			switch key {
+			case "word":
+				entry.Word, _ = value.(string)
+			case "roll":
+				entry.Roll = jsonScalar(value)
+			case "tags":
+				tags, err := jsonTags(value)
+				if err != nil {
+					return nil, fmt.Errorf("item %d: %w", i, err)
+				}
+				entry.Tags = tags
+			default:
+				if entry.Extra == nil {
+					entry.Extra = make(map[string]string)
+				}
+				entry.Extra[key] = jsonScalar(value)
+			}
+		}
+		if entry.Word == "" {
+			return nil, fmt.Errorf("item %d has no word", i)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// jsonScalar formats a decoded JSON value as a column string
+func jsonScalar(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		out, _ := json.Marshal(v)
+		return string(out)
+	}
+}
+
+// jsonTags reads tags given as an array or a comma-separated string
+func jsonTags(value any) ([]string, error) {
+	var column string
+	switch v := value.(type) {
+	case string:
+		column = v
+	case []any:
+		parts := make([]string, len(v))
+		for i, tag := range v {
+			s, ok := tag.(string)
+			if !ok {
+				return nil, errors.New("tags must be strings")
+			}
+			parts[i] = s
+		}
+		column = strings.Join(parts, ",")
+	default:
+		return nil, errors.New("tags must be a string or an array")
+	}
+
+	tags, ok := splitTags(column)
+	if !ok {
+		return nil, fmt.Errorf("invalid tags %q", column)
+	}
+	return tags, nil
+}
+
+// CSVParser reads comma-separated lists, or tab-separated ones with a .tsv
+// extension. The first row names the columns and must include "word"; "roll"
+// and "tags" (separated by commas or semicolons) are recognised and other
+// columns are kept as extra columns.
+type CSVParser struct{}
+
+// Name implements Parser
+func (CSVParser) Name() string { return "csv" }
+
+// Detect implements Parser: a .csv or .tsv extension, or a first row with a
+// "word" column
+func (CSVParser) Detect(path string, data []byte) bool {
+	if hasExt(path, ".csv", ".tsv") {
+		return true
+	}
+	first := strings.TrimSpace(string(firstLines(data, 1)))
+	for _, sep := range []string{",", "\t"} {
+		if !strings.Contains(first, sep) {
+			continue
+		}
+		for column := range strings.SplitSeq(first, sep) {
+			if strings.EqualFold(strings.TrimSpace(column), "word") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Parse implements Parser
+func (CSVParser) Parse(data []byte) (*File, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	if first := firstLines(data, 1); !bytes.Contains(first, []byte(",")) && bytes.Contains(first, []byte("\t")) {
+		reader.Comma = '\t'
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return &File{}, nil
+	}
+
+	columns := records[0]
+	for i := range columns {
+		columns[i] = strings.ToLower(strings.TrimSpace(columns[i]))
+	}
+	if !slices.Contains(columns, "word") {
+		return nil, errors.New(`header row has no "word" column`)
+	}
+
+	entries := make([]Entry, 0, len(records)-1)
+	for line, record := range records[1:] {
+		var entry Entry
+		for i, value := range record {
+			if i >= len(columns) {
+				break
+			}
+			value = strings.TrimSpace(value)
+			switch columns[i] {
+			case "word":
+				entry.Word = value
+			case "roll":
+				entry.Roll = value
+			case "tags":
+				if value == "" {
+					continue
+				}
+				tags, ok := splitTags(strings.ReplaceAll(value, ";", ","))
+				if !ok {
+					return nil, fmt.Errorf("row %d: invalid tags %q", line+2, value)
+				}
+				entry.Tags = tags
+			default:
+				if entry.Extra == nil {
+					entry.Extra = make(map[string]string)
+				}
+				entry.Extra[columns[i]] = value
+			}
+		}
+		if entry.Word != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return &File{Entries: entries}, nil
+}
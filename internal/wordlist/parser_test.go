@@ -0,0 +1,244 @@
+package wordlist
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeEntries decodes data as an unnamed file, failing the test on error
+func decodeEntries(t *testing.T, data []byte) []Entry {
+	t.Helper()
+	file, err := Decode("", data)
+	require.NoError(t, err)
+	return file.Entries
+}
+
+// gzipped compresses data with gzip
+func gzipped(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+// zstded compresses data with zstd
+func zstded(t *testing.T, data string) []byte {
+	t.Helper()
+	zw, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	defer func() { _ = zw.Close() }()
+	return zw.EncodeAll([]byte(data), nil)
+}
+
+func TestDecodeFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		data    string
+		format  string
+		entries []Entry
+	}{
+		{
+			name:    "plain",
+			data:    "# words\napple\nrun noun,verb\n",
+			format:  "plain",
+			entries: []Entry{{Word: "apple"}, {Word: "run", Tags: []string{"noun", "verb"}}},
+		},
+		{
+			name:    "dice",
+			data:    "11111\tabacus\n11112 abdomen noun\n",
+			format:  "dice",
+			entries: []Entry{{Word: "abacus", Roll: "11111"}, {Word: "abdomen", Roll: "11112", Tags: []string{"noun"}}},
+		},
+		{
+			name:    "plain drops numbers",
+			data:    "apple\n12 banana\n",
+			format:  "plain",
+			entries: []Entry{{Word: "apple"}, {Word: "banana"}},
+		},
+		{
+			name:    "json words",
+			data:    `["apple", "banana"]`,
+			format:  "json",
+			entries: []Entry{{Word: "apple"}, {Word: "banana"}},
+		},
+		{
+			name:   "json objects",
+			path:   "list.json",
+			data:   `[{"word": "apple", "roll": 11, "tags": ["noun"], "frequency": 1234}, {"word": "run", "tags": "noun,verb", "note": "x"}]`,
+			format: "json",
+			entries: []Entry{
+				{Word: "apple", Roll: "11", Tags: []string{"noun"}, Extra: map[string]string{"frequency": "1234"}},
+				{Word: "run", Tags: []string{"noun", "verb"}, Extra: map[string]string{"note": "x"}},
+			},
+		},
+		{
+			name:   "csv",
+			data:   "word,tags,frequency\napple,noun,1234\nrun,noun;verb,99\n",
+			format: "csv",
+			entries: []Entry{
+				{Word: "apple", Tags: []string{"noun"}, Extra: map[string]string{"frequency": "1234"}},
+				{Word: "run", Tags: []string{"noun", "verb"}, Extra: map[string]string{"frequency": "99"}},
+			},
+		},
+		{
+			name:    "tsv by extension",
+			path:    "list.tsv",
+			data:    "roll\tword\n1\tant\n2\tbee\n",
+			format:  "csv",
+			entries: []Entry{{Word: "ant", Roll: "1"}, {Word: "bee", Roll: "2"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := Decode(tt.path, []byte(tt.data))
+			require.NoError(t, err)
+			assert.Equal(t, tt.format, file.Format)
+			assert.Equal(t, tt.entries, file.Entries)
+		})
+	}
+}
+
+func TestDecodeHeader(t *testing.T) {
+	data := "---\nname: Garden words\nlanguage: de\ncategory: nature\ncount: 2\nauthor: ignored\n---\n1 apfel\n2 birne\n"
+	file, err := Decode("", []byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, "dice", file.Format)
+	assert.Equal(t, Header{Name: "Garden words", Language: "de", Category: "nature", Count: 2}, file.Header)
+
+	// JSON declares its own header, which front matter overrides
+	file, err = Decode("", []byte("---\nname: Override\n---\n"+`{"name": "Fruit", "language": "es", "count": 1, "words": ["manzana"]}`))
+	require.NoError(t, err)
+	assert.Equal(t, Header{Name: "Override", Language: "es", Count: 1}, file.Header)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		data []byte
+		err  error
+	}{
+		{name: "count mismatch", data: []byte("---\ncount: 3\n---\napple\nbanana\n"), err: ErrInvalidWordlist},
+		{name: "unclosed front matter", data: []byte("---\nname: x\napple\n"), err: ErrInvalidWordlist},
+		{name: "bad count", data: []byte("---\ncount: many\n---\napple\n"), err: ErrInvalidWordlist},
+		{name: "malformed json", data: []byte(`["apple",`), err: ErrInvalidWordlist},
+		{name: "json without word", data: []byte(`[{"tags": "noun"}]`), err: ErrInvalidWordlist},
+		{name: "csv without word column", path: "list.csv", data: []byte("term,tags\napple,noun\n"), err: ErrInvalidWordlist},
+		{name: "csv bad tags", data: []byte("word,tags\napple,n0un\n"), err: ErrInvalidWordlist},
+		{name: "corrupt gzip", path: "list.txt.gz", data: []byte("not gzip"), err: ErrInvalidWordlist},
+		{name: "corrupt zstd", path: "list.txt.zst", data: []byte("not zstd"), err: ErrInvalidWordlist},
+		{name: "truncated zstd", data: []byte{0x28, 0xb5, 0x2f, 0xfd, 0x00}, err: ErrInvalidWordlist},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(tt.path, tt.data)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestDecodeGzip(t *testing.T) {
+	// The extension inside .gz still selects the format
+	file, err := Decode("list.csv.gz", gzipped(t, "word\napple\nbanana\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "csv", file.Format)
+	assert.Len(t, file.Entries, 2)
+
+	// Magic bytes are enough without an extension
+	file, err = Decode("", gzipped(t, "11 apple\n12 banana\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "dice", file.Format)
+}
+
+func TestDecodeZstd(t *testing.T) {
+	file, err := Decode("list.csv.zst", zstded(t, "word\napple\nbanana\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "csv", file.Format)
+	assert.Len(t, file.Entries, 2)
+
+	file, err = Decode("", zstded(t, "11 apple\n12 banana\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "dice", file.Format)
+
+	// The decompressed size is bounded like gzip
+	_, err = Decode("big.txt.zst", zstded(t, strings.Repeat("apple\n", maxWordlistSize/6+1)))
+	assert.ErrorIs(t, err, ErrInvalidWordlist)
+}
+
+// upperParser reads a list of upper-case words separated by spaces
+type upperParser struct{}
+
+func (upperParser) Name() string { return "upper" }
+
+func (upperParser) Detect(path string, _ []byte) bool { return strings.HasSuffix(path, ".upper") }
+
+func (upperParser) Parse(data []byte) (*File, error) {
+	var file File
+	for _, word := range strings.Fields(string(data)) {
+		file.Entries = append(file.Entries, Entry{Word: word})
+	}
+	return &file, nil
+}
+
+func TestRegisterParser(t *testing.T) {
+	saved := parsers
+	t.Cleanup(func() { parsers = saved })
+
+	RegisterParser(upperParser{})
+	file, err := Decode("list.upper", []byte("APPLE BANANA"))
+	require.NoError(t, err)
+	assert.Equal(t, "upper", file.Format)
+	assert.Len(t, file.Entries, 2)
+
+	// Other files still reach the built-in parsers
+	file, err = Decode("list.txt", []byte("apple\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "plain", file.Format)
+}
+
+func TestAddUserWordlistFormats(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "fruit.csv.gz")
+	require.NoError(t, os.WriteFile(csvPath, gzipped(t, "---\nname: Fruit\nlanguage: tr\ncategory: food\n---\nword,tags,frequency\nIŞIK,noun,10\nelma,noun,99\n"), 0600))
+
+	m, err := NewManager(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, m.AddUserWordlist(csvPath, "fruit"))
+
+	wl, ok := m.Loaded("fruit")
+	require.True(t, ok)
+	assert.Equal(t, "Fruit", wl.Source.Name)
+	assert.Equal(t, "tr", wl.Source.Language)
+	assert.Equal(t, "food", wl.Source.Category)
+	assert.Equal(t, 2, wl.Source.WordCount)
+	assert.Equal(t, []string{"elma", "ışık"}, wl.Words)
+	assert.Equal(t, map[string][]string{"noun": {"elma", "ışık"}}, wl.Tags)
+	assert.Equal(t, map[string]string{"frequency": "10"}, wl.Extra["ışık"])
+
+	// Without a header the file name and the manager's language apply
+	plain := filepath.Join(dir, "plain.txt")
+	require.NoError(t, os.WriteFile(plain, []byte("apple\n"), 0600))
+	require.NoError(t, m.AddUserWordlist(plain, "plain"))
+	wl, _ = m.Loaded("plain")
+	assert.Equal(t, "plain.txt", wl.Source.Name)
+	assert.Equal(t, "custom", wl.Source.Category)
+	assert.Equal(t, DefaultLanguage, wl.Source.Language)
+
+	bad := filepath.Join(dir, "bad.txt")
+	require.NoError(t, os.WriteFile(bad, []byte("---\ncount: 5\n---\napple\n"), 0600))
+	assert.ErrorIs(t, m.AddUserWordlist(bad, "bad"), ErrInvalidWordlist)
+}
@@ -0,0 +1,135 @@
+package wordlist
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// registryFile is the name of the file in the cache directory that lists the
+// sources registered with AddSource, in manifest format
+const registryFile = "sources.json"
+
+// ErrDuplicateSource indicates a source ID is already in use
+var ErrDuplicateSource = errors.New("wordlist source already exists")
+
+// AddSource downloads, verifies and caches a remote wordlist, then records it
+// in the registry so that later runs fetch, verify and refresh it like the
+// default sources. The source must be pinned by a checksum, a signing key or
+// both. An empty Name, Language or Category is taken from the list's header,
+// and WordCount from its words. It returns the source as registered.
+func (m *Manager) AddSource(ctx context.Context, source WordlistSource) (WordlistSource, error) {
+	if err := validateSource(source); err != nil {
+		return source, fmt.Errorf("%w: %w", ErrInvalidManifest, err)
+	}
+	if source.SHA256 == "" && source.PublicKey == "" {
+		return source, fmt.Errorf("%w: %s", ErrUnpinnedSource, source.ID)
+	}
+	if m.knownSource(source.ID) {
+		return source, fmt.Errorf("%w: %s", ErrDuplicateSource, source.ID)
+	}
+
+	file, err := m.fetchAndCache(ctx, source)
+	if err != nil {
+		return source, fmt.Errorf("source %s: %w", source.ID, err)
+	}
+
+	source.Name = cmp.Or(source.Name, file.Header.Name, source.ID)
+	source.Language = cmp.Or(source.Language, file.Header.Language, DefaultLanguage)
+	source.Category = cmp.Or(source.Category, file.Header.Category, "custom")
+	source.Description = cmp.Or(source.Description, "Registered wordlist")
+	source.WordCount = len(entryWords(file.Entries, wordForm{language: source.Language, maxLength: source.MaxLength}))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	registry, err := m.readRegistry()
+	if err != nil {
+		return source, err
+	}
+	if slices.ContainsFunc(registry.Sources, func(s WordlistSource) bool { return s.ID == source.ID }) {
+		return source, fmt.Errorf("%w: %s", ErrDuplicateSource, source.ID)
+	}
+	registry.Sources = append(registry.Sources, source)
+	if err := m.writeRegistry(registry); err != nil {
+		return source, err
+	}
+
+	m.registered = registry.Sources
+	if len(sourcesFor([]WordlistSource{source}, m.language)) > 0 {
+		m.sources = append(m.sources, source)
+	}
+	return source, nil
+}
+
+// RemoveSource deletes a registered source from the registry and its files
+// from the cache. Default sources cannot be removed, only disabled.
+func (m *Manager) RemoveSource(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	registry, err := m.readRegistry()
+	if err != nil {
+		return err
+	}
+
+	match := func(s WordlistSource) bool { return s.ID == id }
+	if !slices.ContainsFunc(registry.Sources, match) {
+		return fmt.Errorf("%w: %s is not a registered source", ErrSourceNotFound, id)
+	}
+	registry.Sources = slices.DeleteFunc(registry.Sources, match)
+	if err := m.writeRegistry(registry); err != nil {
+		return err
+	}
+
+	for _, path := range []string{m.cachePath(id), m.signaturePath(id), m.metaPath(id)} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove cache: %w", err)
+		}
+	}
+
+	m.registered = registry.Sources
+	m.sources = slices.DeleteFunc(m.sources, match)
+	delete(m.loaded, id)
+	m.version.Add(1)
+	return nil
+}
+
+// readRegistry reads the registry, returning an empty one when there is none.
+// Registered sources that now clash with a default source are ignored.
+func (m *Manager) readRegistry() (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(m.cacheDir, registryFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Manifest{Version: 1}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry: %w", err)
+	}
+
+	registry, err := ParseManifest(data)
+	if err != nil {
+		return nil, fmt.Errorf("registry %s: %w", registryFile, err)
+	}
+	registry.Sources = slices.DeleteFunc(registry.Sources, func(s WordlistSource) bool {
+		return slices.ContainsFunc(DefaultSources, func(d WordlistSource) bool { return d.ID == s.ID })
+	})
+	return registry, nil
+}
+
+// writeRegistry atomically replaces the registry
+func (m *Manager) writeRegistry(registry *Manifest) error {
+	data, err := json.MarshalIndent(registry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode registry: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(m.cacheDir, registryFile), append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write registry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package wordlist
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fileServer serves files by path over HTTPS and counts the requests for each
+type fileServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	files    map[string][]byte
+	requests map[string]int
+}
+
+// newFileServer starts a TLS server for files, closed when the test ends
+func newFileServer(t *testing.T, files map[string][]byte) *fileServer {
+	t.Helper()
+	fs := &fileServer{files: files, requests: make(map[string]int)}
+	fs.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fs.mu.Lock()
+		defer fs.mu.Unlock()
+		fs.requests[r.URL.Path]++
+		data, ok := fs.files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(fs.Close)
+	return fs
+}
+
+// set replaces the content served at path
+func (fs *fileServer) set(path string, data []byte) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.files[path] = data
+}
+
+// count returns how often path was requested
+func (fs *fileServer) count(path string) int {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.requests[path]
+}
+
+// newServerManager returns a manager in dir that trusts the server
+func newServerManager(t *testing.T, dir string, server *fileServer) *Manager {
+	t.Helper()
+	m, err := NewManager(dir)
+	require.NoError(t, err)
+	m.client = server.Client()
+	return m
+}
+
+func TestAddSource(t *testing.T) {
+	list := []byte("---\nname: Team words\ncategory: team\n---\notter\nheron\nbadger\n")
+	server := newFileServer(t, map[string][]byte{"/team.txt": list})
+	dir := t.TempDir()
+	m := newServerManager(t, dir, server)
+
+	source, err := m.AddSource(context.Background(), WordlistSource{
+		ID:     "team",
+		URL:    server.URL + "/team.txt",
+		SHA256: sha256Hex(list),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Team words", source.Name)
+	assert.Equal(t, "team", source.Category)
+	assert.Equal(t, DefaultLanguage, source.Language)
+	assert.Equal(t, 3, source.WordCount)
+	assert.FileExists(t, filepath.Join(dir, "team.txt"))
+	assert.Contains(t, m.ListSources(), source)
+
+	// Later runs see the source once the state is loaded and use the cache
+	other := newServerManager(t, dir, server)
+	assert.NotContains(t, other.ListSources(), source)
+	require.NoError(t, other.LoadState())
+	assert.Contains(t, other.ListSources(), source)
+	require.NoError(t, other.UseSources("team"))
+	require.NoError(t, other.EnsureWordlists(context.Background()))
+	assert.Equal(t, 1, server.count("/team.txt"))
+	require.NoError(t, other.LoadAll())
+	wl, ok := other.Loaded("team")
+	require.True(t, ok)
+	assert.Equal(t, []string{"badger", "heron", "otter"}, wl.Words)
+
+	// It refreshes like the other sources and can be disabled like them
+	require.NoError(t, other.Update(context.Background()))
+	assert.Equal(t, 2, server.count("/team.txt"))
+	require.NoError(t, other.EnableSource("team", false))
+	assert.False(t, other.Enabled("team"))
+
+	// Other languages leave it out
+	other.SetLanguage("de")
+	assert.NotContains(t, other.ListSources(), source)
+}
+
+func TestAddSourceSigned(t *testing.T) {
+	key := newMinisignKey(t)
+	list := []byte("otter\nheron\n")
+	server := newFileServer(t, map[string][]byte{
+		"/team.txt":         list,
+		"/team.txt.minisig": key.sign(list, true),
+		"/forged.txt":       []byte("mole\nvole\n"),
+		"/forged.txt.sig":   key.sign(list, true),
+	})
+	dir := t.TempDir()
+	m := newServerManager(t, dir, server)
+
+	_, err := m.AddSource(context.Background(), WordlistSource{ID: "team", URL: server.URL + "/team.txt", PublicKey: key.public})
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, "team.sig"))
+
+	// The signature is checked before anything is cached or registered
+	_, err = m.AddSource(context.Background(), WordlistSource{
+		ID:           "forged",
+		URL:          server.URL + "/forged.txt",
+		SignatureURL: server.URL + "/forged.txt.sig",
+		PublicKey:    key.public,
+	})
+	require.ErrorIs(t, err, ErrInvalidSignature)
+	assert.NoFileExists(t, filepath.Join(dir, "forged.txt"))
+
+	// A cached list that no longer matches its signature is refused
+	require.NoError(t, m.LoadState())
+	require.NoError(t, m.UseSources("team"))
+	require.NoError(t, m.LoadAll())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "team.txt"), []byte("mole\nvole\n"), 0600))
+	assert.ErrorIs(t, m.LoadAll(), ErrInvalidSignature)
+	assert.False(t, m.isValidCache(m.cachePath("team"), m.ListSources()[0]))
+
+	// A new signature for new content is fetched on the next refresh
+	newList := []byte("otter\nheron\nbadger\n")
+	server.set("/team.txt", newList)
+	server.set("/team.txt.minisig", key.sign(newList, false))
+	require.NoError(t, m.EnsureWordlists(context.Background()))
+	require.NoError(t, m.LoadAll())
+	wl, _ := m.Loaded("team")
+	assert.Len(t, wl.Words, 3)
+}
+
+func TestAddSourceErrors(t *testing.T) {
+	list := []byte("otter\nheron\n")
+	big := bytes.Repeat([]byte("otter\n"), maxWordlistSize/6+1)
+	server := newFileServer(t, map[string][]byte{"/team.txt": list, "/big.txt": big})
+	m := newServerManager(t, t.TempDir(), server)
+	url := server.URL + "/team.txt"
+
+	tests := []struct {
+		name   string
+		source WordlistSource
+		err    error
+	}{
+		{name: "unpinned", source: WordlistSource{ID: "team", URL: url}, err: ErrUnpinnedSource},
+		{name: "plain http", source: WordlistSource{ID: "team", URL: "http://example.com/team.txt", SHA256: sha256Hex(list)}, err: ErrInvalidManifest},
+		{name: "bad id", source: WordlistSource{ID: "../team", URL: url, SHA256: sha256Hex(list)}, err: ErrInvalidManifest},
+		{name: "bad key", source: WordlistSource{ID: "team", URL: url, PublicKey: "bm90IGEga2V5"}, err: ErrInvalidManifest},
+		{name: "default id", source: WordlistSource{ID: "eff-large", URL: url, SHA256: sha256Hex(list)}, err: ErrDuplicateSource},
+		{name: "checksum mismatch", source: WordlistSource{ID: "team", URL: url, SHA256: sha256Hex([]byte("other"))}, err: ErrChecksumMismatch},
+		{name: "oversized", source: WordlistSource{ID: "team", URL: server.URL + "/big.txt", SHA256: sha256Hex(big)}, err: ErrInvalidWordlist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := m.AddSource(context.Background(), tt.source)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	_, err := m.AddSource(context.Background(), WordlistSource{ID: "team", URL: url, SHA256: sha256Hex(list)})
+	require.NoError(t, err)
+	_, err = m.AddSource(context.Background(), WordlistSource{ID: "team", URL: url, SHA256: sha256Hex(list)})
+	assert.ErrorIs(t, err, ErrDuplicateSource)
+}
+
+func TestRemoveSource(t *testing.T) {
+	key := newMinisignKey(t)
+	list := []byte("otter\nheron\n")
+	server := newFileServer(t, map[string][]byte{"/team.txt": list, "/team.txt.minisig": key.sign(list, true)})
+	dir := t.TempDir()
+	m := newServerManager(t, dir, server)
+
+	_, err := m.AddSource(context.Background(), WordlistSource{ID: "team", URL: server.URL + "/team.txt", PublicKey: key.public})
+	require.NoError(t, err)
+	require.NoError(t, m.LoadAll())
+
+	require.NoError(t, m.RemoveSource("team"))
+	assert.NoFileExists(t, filepath.Join(dir, "team.txt"))
+	assert.NoFileExists(t, filepath.Join(dir, "team.sig"))
+	_, ok := m.Loaded("team")
+	assert.False(t, ok)
+
+	other := newServerManager(t, dir, server)
+	require.NoError(t, other.LoadState())
+	for _, source := range other.ListSources() {
+		assert.NotEqual(t, "team", source.ID)
+	}
+
+	assert.ErrorIs(t, m.RemoveSource("team"), ErrSourceNotFound)
+	assert.ErrorIs(t, m.RemoveSource("eff-large"), ErrSourceNotFound)
+}
+
+func TestLoadStateInvalidRegistry(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, registryFile), []byte(`{"version": 1, "sources": [{"id": "a", "url": "http://example.com/a.txt"}]}`), 0600))
+
+	m, err := NewManager(dir)
+	require.NoError(t, err)
+	assert.ErrorIs(t, m.LoadState(), ErrInvalidManifest)
+}
@@ -0,0 +1,145 @@
+package wordlist
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// ErrInvalidSignature indicates a wordlist failed detached signature verification
+var ErrInvalidSignature = errors.New("wordlist signature verification failed")
+
+const (
+	// minisignKeyLen is the length of a decoded minisign public key: the
+	// "Ed" algorithm, an 8-byte key ID and the Ed25519 key
+	minisignKeyLen = 2 + 8 + ed25519.PublicKeySize
+
+	// minisignSigLen is the length of a decoded minisign signature: the
+	// algorithm, the key ID and the Ed25519 signature
+	minisignSigLen = 2 + 8 + ed25519.SignatureSize
+
+	untrustedComment = "untrusted comment:"
+	trustedComment   = "trusted comment: "
+)
+
+// PublicKey is an Ed25519 key that signs wordlists. Keys in minisign format
+// carry a key ID and verify minisign signature files; bare keys verify raw
+// or base64 Ed25519 signatures.
+type PublicKey struct {
+	keyID []byte // nil for bare keys
+	key   ed25519.PublicKey
+}
+
+// ParsePublicKey parses a minisign public key, optionally with its untrusted
+// comment line as in a .pub file, or a base64 Ed25519 public key
+func ParsePublicKey(s string) (*PublicKey, error) {
+	var encoded string
+	for line := range strings.Lines(s) {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, untrustedComment) {
+			encoded = line
+			break
+		}
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: public key is not base64", ErrInvalidSignature)
+	}
+
+	switch {
+	case len(data) == minisignKeyLen && string(data[:2]) == "Ed":
+		return &PublicKey{keyID: data[2:10], key: ed25519.PublicKey(data[10:])}, nil
+	case len(data) == ed25519.PublicKeySize:
+		return &PublicKey{key: ed25519.PublicKey(data)}, nil
+	default:
+		return nil, fmt.Errorf("%w: not a minisign or Ed25519 public key", ErrInvalidSignature)
+	}
+}
+
+// Minisign reports whether the key is in minisign format
+func (k *PublicKey) Minisign() bool {
+	return k.keyID != nil
+}
+
+// String returns the key in the base64 form ParsePublicKey accepts
+func (k *PublicKey) String() string {
+	if k.Minisign() {
+		return base64.StdEncoding.EncodeToString(slices.Concat([]byte("Ed"), k.keyID, k.key))
+	}
+	return base64.StdEncoding.EncodeToString(k.key)
+}
+
+// Verify checks a detached signature of data
+func (k *PublicKey) Verify(data, signature []byte) error {
+	if k.Minisign() {
+		return k.verifyMinisign(data, signature)
+	}
+
+	sig := signature
+	if len(sig) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(sig)))
+		if err != nil {
+			return fmt.Errorf("%w: signature is neither raw nor base64", ErrInvalidSignature)
+		}
+		sig = decoded
+	}
+	if len(sig) != ed25519.SignatureSize || !ed25519.Verify(k.key, data, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// verifyMinisign checks a minisign signature file: the signature of data, or
+// of its BLAKE2b-512 hash for prehashed "ED" signatures, and the global
+// signature over the trusted comment
+func (k *PublicKey) verifyMinisign(data, signature []byte) error {
+	lines := strings.Split(strings.TrimSpace(string(signature)), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, "\r")
+	}
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], untrustedComment) || !strings.HasPrefix(lines[2], trustedComment) {
+		return fmt.Errorf("%w: malformed minisign signature", ErrInvalidSignature)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(sig) != minisignSigLen {
+		return fmt.Errorf("%w: malformed minisign signature", ErrInvalidSignature)
+	}
+	if !bytes.Equal(sig[2:10], k.keyID) {
+		return fmt.Errorf("%w: signed by key %X, expected %X", ErrInvalidSignature, reverse(sig[2:10]), reverse(k.keyID))
+	}
+
+	message := data
+	switch string(sig[:2]) {
+	case "Ed":
+	case "ED":
+		sum := blake2b.Sum512(data)
+		message = sum[:]
+	default:
+		return fmt.Errorf("%w: unknown minisign algorithm %q", ErrInvalidSignature, sig[:2])
+	}
+	if !ed25519.Verify(k.key, message, sig[10:]) {
+		return ErrInvalidSignature
+	}
+
+	global, err := base64.StdEncoding.DecodeString(lines[3])
+	comment := strings.TrimPrefix(lines[2], trustedComment)
+	if err != nil || !ed25519.Verify(k.key, slices.Concat(sig[10:], []byte(comment)), global) {
+		return fmt.Errorf("%w: trusted comment does not verify", ErrInvalidSignature)
+	}
+	return nil
+}
+
+// reverse returns a reversed copy of b; minisign prints key IDs little-endian
+func reverse(b []byte) []byte {
+	r := slices.Clone(b)
+	slices.Reverse(r)
+	return r
+}
@@ -0,0 +1,125 @@
+package wordlist
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+)
+
+// minisignKey is a test key pair in minisign format
+type minisignKey struct {
+	keyID   []byte
+	private ed25519.PrivateKey
+	public  string // as in the second line of a minisign .pub file
+}
+
+// newMinisignKey generates a minisign key pair
+func newMinisignKey(t *testing.T) *minisignKey {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	keyID := make([]byte, 8)
+	_, err = rand.Read(keyID)
+	require.NoError(t, err)
+
+	return &minisignKey{
+		keyID:   keyID,
+		private: private,
+		public:  base64.StdEncoding.EncodeToString(slices.Concat([]byte("Ed"), keyID, public)),
+	}
+}
+
+// sign returns a minisign signature file for data, prehashed as minisign
+// does by default or over the raw data as legacy signatures are
+func (k *minisignKey) sign(data []byte, prehash bool) []byte {
+	algorithm, message := "Ed", data
+	if prehash {
+		sum := blake2b.Sum512(data)
+		algorithm, message = "ED", sum[:]
+	}
+
+	signature := ed25519.Sign(k.private, message)
+	comment := "timestamp:1700000000\tfile:list.txt"
+	global := ed25519.Sign(k.private, slices.Concat(signature, []byte(comment)))
+
+	return fmt.Appendf(nil, "untrusted comment: signature from minisign secret key\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(slices.Concat([]byte(algorithm), k.keyID, signature)),
+		comment,
+		base64.StdEncoding.EncodeToString(global))
+}
+
+func TestParsePublicKey(t *testing.T) {
+	key := newMinisignKey(t)
+
+	parsed, err := ParsePublicKey("untrusted comment: minisign public key\n" + key.public + "\n")
+	require.NoError(t, err)
+	assert.True(t, parsed.Minisign())
+	assert.Equal(t, key.public, parsed.String())
+
+	bare := base64.StdEncoding.EncodeToString(key.private.Public().(ed25519.PublicKey))
+	parsed, err = ParsePublicKey(bare)
+	require.NoError(t, err)
+	assert.False(t, parsed.Minisign())
+	assert.Equal(t, bare, parsed.String())
+
+	for _, invalid := range []string{"", "not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		_, err := ParsePublicKey(invalid)
+		assert.ErrorIs(t, err, ErrInvalidSignature, invalid)
+	}
+}
+
+func TestVerifyMinisign(t *testing.T) {
+	key := newMinisignKey(t)
+	other := newMinisignKey(t)
+	data := []byte("apple\nbanana\n")
+
+	public, err := ParsePublicKey(key.public)
+	require.NoError(t, err)
+
+	require.NoError(t, public.Verify(data, key.sign(data, true)))
+	require.NoError(t, public.Verify(data, key.sign(data, false)))
+
+	tamperedComment := slices.Clone(key.sign(data, true))
+	i := slices.Index(tamperedComment, '\t')
+	tamperedComment[i] = ' '
+
+	tests := []struct {
+		name      string
+		data      []byte
+		signature []byte
+	}{
+		{name: "tampered data", data: []byte("apple\ncherry\n"), signature: key.sign(data, true)},
+		{name: "other key", data: data, signature: other.sign(data, true)},
+		{name: "tampered trusted comment", data: data, signature: tamperedComment},
+		{name: "malformed", data: data, signature: []byte("untrusted comment: x\n")},
+		{name: "not base64", data: data, signature: []byte("untrusted comment: x\n!!\ntrusted comment: y\n!!\n")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, public.Verify(tt.data, tt.signature), ErrInvalidSignature)
+		})
+	}
+}
+
+func TestVerifyEd25519(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := ParsePublicKey(base64.StdEncoding.EncodeToString(public))
+	require.NoError(t, err)
+
+	data := []byte("apple\nbanana\n")
+	signature := ed25519.Sign(private, data)
+
+	assert.NoError(t, key.Verify(data, signature))
+	assert.NoError(t, key.Verify(data, []byte(base64.StdEncoding.EncodeToString(signature)+"\n")))
+	assert.ErrorIs(t, key.Verify([]byte("apple\n"), signature), ErrInvalidSignature)
+	assert.ErrorIs(t, key.Verify(data, []byte("garbage")), ErrInvalidSignature)
+}
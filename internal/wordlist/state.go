@@ -0,0 +1,202 @@
+package wordlist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// stateFile is the name of the source state file in the cache directory
+const stateFile = "state.json"
+
+// ErrInvalidState indicates a malformed source state file
+var ErrInvalidState = errors.New("invalid wordlist state")
+
+// sourceState is the persisted state of one source
+type sourceState struct {
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// stateData is the content of the state file
+type stateData struct {
+	Version int                    `json:"version"`
+	Sources map[string]sourceState `json:"sources"`
+}
+
+// SourceInfo describes a configured source for listings
+type SourceInfo struct {
+	Source   WordlistSource
+	Enabled  bool
+	Embedded bool      // a verified copy is compiled into the binary
+	CachedAt time.Time // last download or revalidation of the cached file, zero when not cached
+}
+
+// LoadState reads which sources are disabled from the state file in the cache
+// directory and adds the sources registered with AddSource in the selected
+// language. Until it is called every source is enabled and only the default
+// sources are configured, so callers that need a fixed set of lists, such as
+// derived passwords, never see the state.
+func (m *Manager) LoadState() error {
+	state, err := m.readState()
+	if err != nil {
+		return err
+	}
+	registry, err := m.readRegistry()
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.disabled = state.disabledIDs()
+	m.registered = registry.Sources
+	m.sources = sourcesFor(slices.Concat(DefaultSources, m.registered), m.language)
+	return nil
+}
+
+// EnableSource enables or disables a source and persists the choice in the
+// state file. Disabled sources are neither fetched, loaded, counted nor
+// selected once the state is loaded.
+func (m *Manager) EnableSource(id string, enabled bool) error {
+	if !m.knownSource(id) {
+		return fmt.Errorf("%w: %s", ErrSourceNotFound, id)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, err := m.readState()
+	if err != nil {
+		return err
+	}
+	if enabled {
+		delete(state.Sources, id)
+	} else {
+		state.Sources[id] = sourceState{Disabled: true}
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(m.cacheDir, stateFile), append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+
+	m.disabled = state.disabledIDs()
+	if !enabled {
+		delete(m.loaded, id)
+		m.version.Add(1)
+	}
+	return nil
+}
+
+// Enabled reports whether a source is enabled in the loaded state
+func (m *Manager) Enabled(id string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return !m.disabled[id]
+}
+
+// SourceInfo describes every configured source with its state and cache age
+func (m *Manager) SourceInfo() []SourceInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	infos := make([]SourceInfo, 0, len(m.sources))
+	for _, source := range m.sources {
+		info := SourceInfo{Source: source, Enabled: !m.disabled[source.ID], Embedded: IsEmbedded(source)}
+		if stat, err := os.Stat(m.cachePath(source.ID)); err == nil {
+			info.CachedAt = m.fetchedAt(source.ID, stat.ModTime())
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// knownSource reports whether id names a default, registered or configured source
+func (m *Manager) knownSource(id string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	match := func(s WordlistSource) bool { return s.ID == id }
+	return slices.ContainsFunc(DefaultSources, match) || slices.ContainsFunc(m.registered, match) ||
+		slices.ContainsFunc(m.sources, match)
+}
+
+// readState reads the state file, returning an empty state when there is none
+func (m *Manager) readState() (*stateData, error) {
+	state := &stateData{Version: 1, Sources: make(map[string]sourceState)}
+
+	data, err := os.ReadFile(filepath.Join(m.cacheDir, stateFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidState, err)
+	}
+	if state.Sources == nil {
+		state.Sources = make(map[string]sourceState)
+	}
+	return state, nil
+}
+
+// disabledIDs returns the set of disabled source IDs
+func (s *stateData) disabledIDs() map[string]bool {
+	disabled := make(map[string]bool)
+	for id, source := range s.Sources {
+		if source.Disabled {
+			disabled[id] = true
+		}
+	}
+	return disabled
+}
+
+// writeFileAtomic writes data to a temporary file in the target directory,
+// syncs it, renames it over path and syncs the directory, so readers never see
+// a partial file and the rename survives a crash
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+// syncDir flushes the directory entry changes made in dir to disk
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	if err := d.Sync(); err != nil {
+		_ = d.Close()
+		return err
+	}
+	return d.Close()
+}
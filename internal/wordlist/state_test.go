@@ -0,0 +1,130 @@
+package wordlist
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnableSource(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewManager(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, m.EnableSource("eff-short-2", false))
+	assert.False(t, m.Enabled("eff-short-2"))
+	assert.FileExists(t, filepath.Join(dir, stateFile))
+
+	// Lists of other languages can be disabled too
+	require.NoError(t, m.EnableSource("bip39-fr", false))
+	require.ErrorIs(t, m.EnableSource("no-such-list", false), ErrSourceNotFound)
+
+	// A new manager sees the state only once it loads it
+	other, err := NewManager(dir)
+	require.NoError(t, err)
+	assert.True(t, other.Enabled("eff-short-2"))
+	require.NoError(t, other.LoadState())
+	assert.False(t, other.Enabled("eff-short-2"))
+	assert.False(t, other.Enabled("bip39-fr"))
+	assert.True(t, other.Enabled("eff-large"))
+
+	require.NoError(t, other.EnableSource("eff-short-2", true))
+	require.NoError(t, m.LoadState())
+	assert.True(t, m.Enabled("eff-short-2"))
+	assert.False(t, m.Enabled("bip39-fr"))
+}
+
+func TestDisabledSourcesSkipped(t *testing.T) {
+	if len(EmbeddedIDs(DefaultLanguage)) < 2 {
+		t.Skip("slim build has no embedded wordlists")
+	}
+
+	m, err := NewManager(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, m.LoadAll())
+	available := m.AvailableCount()
+
+	require.NoError(t, m.EnableSource("eff-short-2", false))
+	assert.Equal(t, available-1, m.AvailableCount())
+
+	// Disabling drops the loaded list at once, and LoadAll leaves it out
+	_, ok := m.Loaded("eff-short-2")
+	assert.False(t, ok)
+	require.NoError(t, m.LoadAll())
+	_, ok = m.Loaded("eff-short-2")
+	assert.False(t, ok)
+
+	lists, err := m.SelectRandomLists(1)
+	require.NoError(t, err)
+	assert.Equal(t, "eff-large", lists[0].Source.ID)
+	_, err = m.SelectRandomLists(2)
+	assert.ErrorIs(t, err, ErrInsufficientLists)
+}
+
+func TestDisabledSourcesNotFetched(t *testing.T) {
+	var requested []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	m, err := NewManager(t.TempDir())
+	require.NoError(t, err)
+	m.client = server.Client()
+	m.SetAllowUnpinned(true)
+	for i := range m.sources {
+		m.sources[i].URL = server.URL + "/" + m.sources[i].ID + ".txt"
+	}
+
+	for _, source := range m.ListSources() {
+		if source.ID != "eff-short-2" {
+			require.NoError(t, m.EnableSource(source.ID, false))
+		}
+	}
+	_ = m.EnsureWordlists(context.Background())
+	_ = m.Update(context.Background())
+	for _, path := range requested {
+		assert.Equal(t, "/eff-short-2.txt", path)
+	}
+	assert.NotEmpty(t, requested)
+}
+
+func TestSourceInfo(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewManager(dir)
+	require.NoError(t, err)
+
+	cached := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	path := m.cachePath("eff-short-2")
+	require.NoError(t, os.WriteFile(path, []byte("apple\n"), 0600))
+	require.NoError(t, os.Chtimes(path, cached, cached))
+	require.NoError(t, m.EnableSource("eff-short-2", false))
+
+	infos := make(map[string]SourceInfo)
+	for _, info := range m.SourceInfo() {
+		infos[info.Source.ID] = info
+	}
+	require.Contains(t, infos, "eff-short-2")
+	assert.False(t, infos["eff-short-2"].Enabled)
+	assert.True(t, infos["eff-short-2"].CachedAt.Equal(cached))
+	assert.True(t, infos["eff-large"].Enabled)
+	assert.True(t, infos["eff-large"].CachedAt.IsZero())
+}
+
+func TestLoadStateInvalid(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, stateFile), []byte("{"), 0600))
+
+	m, err := NewManager(dir)
+	require.NoError(t, err)
+	assert.ErrorIs(t, m.LoadState(), ErrInvalidState)
+	assert.ErrorIs(t, m.EnableSource("eff-large", false), ErrInvalidState)
+}
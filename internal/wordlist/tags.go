@@ -0,0 +1,138 @@
+package wordlist
+
+import (
+	"embed"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// maxTagLength bounds the length of a part-of-speech tag
+const maxTagLength = 16
+
+// embeddedTags holds part-of-speech tags for default lists published without
+// them, named <id>.txt in the plain "word tag,tag" format. They are small and
+// kept in slim builds, so downloaded lists are tagged too.
+//
+//go:embed tags/*.txt
+var embeddedTags embed.FS
+
+// tagSidecars parses every embedded tag file once, keyed by source ID
+var tagSidecars = sync.OnceValue(func() map[string][]Entry {
+	sidecars := make(map[string][]Entry)
+	files, _ := embeddedTags.ReadDir("tags")
+	for _, f := range files {
+		name := path.Join("tags", f.Name())
+		data, err := embeddedTags.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		if file, err := Decode(name, data); err == nil {
+			sidecars[strings.TrimSuffix(f.Name(), ".txt")] = file.Entries
+		}
+	}
+	return sidecars
+})
+
+// addSidecarTags merges the embedded tag sidecar of wl's source into its tags.
+// Only words the list already has are tagged, so a sidecar never adds words.
+func addSidecarTags(wl *Wordlist, form wordForm) {
+	entries, ok := tagSidecars()[wl.Source.ID]
+	if !ok {
+		return
+	}
+
+	for tag, words := range entryTags(entries, form) {
+		for _, word := range words {
+			if _, found := slices.BinarySearch(wl.Words, word); !found {
+				continue
+			}
+			if wl.Tags == nil {
+				wl.Tags = make(map[string][]string)
+			}
+			wl.Tags[tag] = append(wl.Tags[tag], word)
+		}
+	}
+	for tag, words := range wl.Tags {
+		slices.Sort(words)
+		wl.Tags[tag] = slices.Compact(words)
+	}
+}
+
+// entryTags maps the part-of-speech tags of a tagged list to their sorted
+// words in form. It returns nil for untagged lists.
+func entryTags(entries []Entry, form wordForm) map[string][]string {
+	var tags map[string][]string
+
+	for _, entry := range entries {
+		if len(entry.Tags) == 0 {
+			continue
+		}
+		word, ok := form.normalize(entry.Word)
+		if !ok {
+			continue
+		}
+
+		if tags == nil {
+			tags = make(map[string][]string)
+		}
+		for _, tag := range entry.Tags {
+			tags[tag] = append(tags[tag], word)
+		}
+	}
+
+	for tag, words := range tags {
+		slices.Sort(words)
+		tags[tag] = slices.Compact(words)
+	}
+	return tags
+}
+
+// isTagColumn reports whether field is a valid comma-separated tag column
+func isTagColumn(field string) bool {
+	_, ok := splitTags(field)
+	return ok
+}
+
+// splitTags splits a comma-separated tag column into lowercase tags. Tags are
+// short ASCII letter strings such as "adj" or "noun".
+func splitTags(column string) ([]string, bool) {
+	var tags []string
+	for tag := range strings.SplitSeq(strings.ToLower(column), ",") {
+		if tag == "" || len(tag) > maxTagLength || strings.Trim(tag, "abcdefghijklmnopqrstuvwxyz") != "" {
+			return nil, false
+		}
+		tags = append(tags, tag)
+	}
+	return tags, true
+}
+
+// TaggedWords returns the sorted union of the words tagged with tag across
+// every loaded list
+func (m *Manager) TaggedWords(tag string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var words []string
+	for _, wl := range m.loaded {
+		words = append(words, wl.Tags[strings.ToLower(tag)]...)
+	}
+	slices.Sort(words)
+	return slices.Compact(words)
+}
+
+// Tags returns the sorted part-of-speech tags of the loaded lists
+func (m *Manager) Tags() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	set := make(map[string]bool)
+	for _, wl := range m.loaded {
+		for tag := range wl.Tags {
+			set[tag] = true
+		}
+	}
+	return slices.Sorted(maps.Keys(set))
+}
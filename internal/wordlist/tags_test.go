@@ -0,0 +1,80 @@
+package wordlist
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTags(t *testing.T) {
+	data := []byte(`# tagged list
+brave adj
+Otter noun
+run noun,verb
+quietly ADV
+plain
+bad-tag noun
+odd n0un
+11 swift adj
+12 swim verb
+`)
+
+	tags := entryTags(decodeEntries(t, data), wordForm{})
+	assert.Equal(t, map[string][]string{
+		"adj":  {"brave", "swift"},
+		"noun": {"otter", "run"},
+		"verb": {"run", "swim"},
+		"adv":  {"quietly"},
+	}, tags)
+
+	assert.Nil(t, entryTags(decodeEntries(t, []byte("apple\nbanana\n11111 cherry\n")), wordForm{}))
+}
+
+func TestParseWordlistTagged(t *testing.T) {
+	words := entryWords(decodeEntries(t, []byte("brave adj\nrun noun,verb\nplain\n11 swift adj\nodd n0un\nthree word line\n")), wordForm{})
+	assert.Equal(t, []string{"brave", "plain", "run", "swift"}, words)
+}
+
+func TestParseDiceIndexTagged(t *testing.T) {
+	index := diceIndex(decodeEntries(t, []byte("1 ant noun\n2 big adj\n3 cat noun\n4 dim adj\n5 eat verb\n6 fly verb,noun\n")), wordForm{})
+	require.NotNil(t, index)
+	assert.Equal(t, "fly", index["6"])
+}
+
+func TestManagerTaggedWords(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.txt")
+	second := filepath.Join(dir, "second.txt")
+	require.NoError(t, os.WriteFile(first, []byte("brave adj\notter noun\n"), 0600))
+	require.NoError(t, os.WriteFile(second, []byte("calm adj\nbrave adj\nswim verb\nplain\n"), 0600))
+
+	m, err := NewManager(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, m.AddUserWordlist(first, "first"))
+	require.NoError(t, m.AddUserWordlist(second, "second"))
+
+	assert.Equal(t, []string{"brave", "calm"}, m.TaggedWords("ADJ"))
+	assert.Equal(t, []string{"swim"}, m.TaggedWords("verb"))
+	assert.Empty(t, m.TaggedWords("adv"))
+	assert.Equal(t, []string{"adj", "noun", "verb"}, m.Tags())
+
+	wl, ok := m.Loaded("second")
+	require.True(t, ok)
+	assert.Equal(t, []string{"brave", "calm", "plain", "swim"}, wl.Words)
+}
+
+func TestSidecarTags(t *testing.T) {
+	require.NotEmpty(t, tagSidecars()["eff-large"])
+
+	// Only words the list has are tagged
+	wl := &Wordlist{Source: &WordlistSource{ID: "eff-large"}, Words: []string{"abacus", "zorblat"}}
+	addSidecarTags(wl, wordForm{language: DefaultLanguage})
+	assert.Equal(t, map[string][]string{"noun": {"abacus"}}, wl.Tags)
+
+	other := &Wordlist{Source: &WordlistSource{ID: "team"}, Words: []string{"abacus"}}
+	addSidecarTags(other, wordForm{language: DefaultLanguage})
+	assert.Nil(t, other.Tags)
+}
@@ -0,0 +1,90 @@
+package wordlist
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// CacheStatus describes the state of a cached wordlist
+type CacheStatus int
+
+const (
+	// CacheOK means the cached file matches its pinned checksum
+	CacheOK CacheStatus = iota
+	// CacheMissing means no cached file exists
+	CacheMissing
+	// CacheUnpinned means the source has no checksum to verify against, whether
+	// or not it is cached
+	CacheUnpinned
+	// CacheMismatch means the cached file does not match its pinned checksum
+	CacheMismatch
+	// CacheUnreadable means the cached file exists but could not be read
+	CacheUnreadable
+)
+
+// cacheStatusNames maps cache statuses to their display names
+var cacheStatusNames = map[CacheStatus]string{
+	CacheOK:         "ok",
+	CacheMissing:    "missing",
+	CacheUnpinned:   "unpinned",
+	CacheMismatch:   "mismatch",
+	CacheUnreadable: "unreadable",
+}
+
+// String returns the display name of the status
+func (s CacheStatus) String() string {
+	if name, ok := cacheStatusNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// VerifyResult reports the verification status of one cached wordlist
+type VerifyResult struct {
+	Source   WordlistSource
+	Path     string
+	Status   CacheStatus
+	SHA256   string // Actual checksum of the cached file, empty if not read
+	Embedded bool   // A verified copy is compiled into the binary and used in preference
+	Err      error
+}
+
+// VerifyCache re-hashes every cached wordlist and compares it with the manifest
+func (m *Manager) VerifyCache() []VerifyResult {
+	results := make([]VerifyResult, 0, len(m.sources))
+
+	for _, source := range m.sources {
+		result := VerifyResult{Source: source, Path: m.cachePath(source.ID), Embedded: IsEmbedded(source)}
+
+		data, err := os.ReadFile(result.Path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist) && !source.Pinned():
+			result.Status = CacheUnpinned
+		case errors.Is(err, fs.ErrNotExist):
+			result.Status = CacheMissing
+		case err != nil:
+			result.Status = CacheUnreadable
+			result.Err = err
+		default:
+			sum := sha256.Sum256(data)
+			result.SHA256 = hex.EncodeToString(sum[:])
+			result.Err = m.verifyCached(data, source)
+
+			switch {
+			case !source.Pinned():
+				result.Status = CacheUnpinned
+			case result.Err != nil:
+				result.Status = CacheMismatch
+			default:
+				result.Status = CacheOK
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
@@ -0,0 +1,126 @@
+package wordlist
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sha256Hex returns the hex SHA-256 checksum of data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// newPinnedManager returns a manager whose sources are pinned to the given contents
+func newPinnedManager(t *testing.T, contents map[string]string) *Manager {
+	t.Helper()
+
+	m, err := NewManager(t.TempDir())
+	require.NoError(t, err)
+
+	m.sources = nil
+	for id, content := range contents {
+		m.sources = append(m.sources, WordlistSource{
+			ID:     id,
+			URL:    "https://example.com/" + id + ".txt",
+			SHA256: sha256Hex([]byte(content)),
+		})
+	}
+	return m
+}
+
+func TestVerifyCache(t *testing.T) {
+	m := newPinnedManager(t, map[string]string{
+		"good":     "apple\nbanana\n",
+		"tampered": "cherry\ndate\n",
+		"missing":  "elder\nfig\n",
+	})
+	m.sources = append(m.sources,
+		WordlistSource{ID: "loose", URL: "https://example.com/loose.txt"},
+		WordlistSource{ID: "absent", URL: "https://example.com/absent.txt"},
+	)
+
+	require.NoError(t, os.WriteFile(m.cachePath("good"), []byte("apple\nbanana\n"), 0600))
+	require.NoError(t, os.WriteFile(m.cachePath("tampered"), []byte("cherry\nevil\n"), 0600))
+	require.NoError(t, os.WriteFile(m.cachePath("loose"), []byte("grape\n"), 0600))
+
+	statuses := make(map[string]CacheStatus)
+	for _, result := range m.VerifyCache() {
+		statuses[result.Source.ID] = result.Status
+		if result.Status == CacheMismatch {
+			assert.ErrorIs(t, result.Err, ErrChecksumMismatch)
+			assert.Equal(t, sha256Hex([]byte("cherry\nevil\n")), result.SHA256)
+		}
+	}
+
+	assert.Equal(t, map[string]CacheStatus{
+		"good":     CacheOK,
+		"tampered": CacheMismatch,
+		"missing":  CacheMissing,
+		"loose":    CacheUnpinned,
+		"absent":   CacheUnpinned, // flagged even though nothing is cached
+	}, statuses)
+}
+
+func TestCacheStatusString(t *testing.T) {
+	assert.Equal(t, "ok", CacheOK.String())
+	assert.Equal(t, "missing", CacheMissing.String())
+	assert.Equal(t, "unpinned", CacheUnpinned.String())
+	assert.Equal(t, "mismatch", CacheMismatch.String())
+	assert.Equal(t, "unreadable", CacheUnreadable.String())
+	assert.Equal(t, "unknown", CacheStatus(99).String())
+}
+
+func TestLoadAllVerifiesChecksums(t *testing.T) {
+	t.Run("loads verified cache", func(t *testing.T) {
+		m := newPinnedManager(t, map[string]string{"good": "apple\nbanana\n"})
+		require.NoError(t, os.WriteFile(m.cachePath("good"), []byte("apple\nbanana\n"), 0600))
+
+		require.NoError(t, m.LoadAll())
+		assert.Len(t, m.loaded, 1)
+	})
+
+	t.Run("rejects tampered cache", func(t *testing.T) {
+		m := newPinnedManager(t, map[string]string{"good": "apple\nbanana\n"})
+		require.NoError(t, os.WriteFile(m.cachePath("good"), []byte("apple\nbadword\n"), 0600))
+
+		assert.ErrorIs(t, m.LoadAll(), ErrChecksumMismatch)
+		assert.Empty(t, m.loaded)
+	})
+
+	t.Run("skips unpinned unless allowed", func(t *testing.T) {
+		m := newPinnedManager(t, nil)
+		m.sources = []WordlistSource{{ID: "loose", URL: "https://example.com/loose.txt"}}
+		require.NoError(t, os.WriteFile(m.cachePath("loose"), []byte("grape\n"), 0600))
+		assert.Equal(t, []string{"loose"}, m.UnpinnedSources())
+
+		require.NoError(t, m.LoadAll())
+		assert.Empty(t, m.loaded)
+
+		m.SetAllowUnpinned(true)
+		require.NoError(t, m.LoadAll())
+		assert.Len(t, m.loaded, 1)
+	})
+}
+
+func TestIsValidCacheVerifiesChecksum(t *testing.T) {
+	m := newPinnedManager(t, map[string]string{"good": "apple\nbanana\n"})
+	source := m.sources[0]
+	path := m.cachePath("good")
+
+	require.NoError(t, os.WriteFile(path, []byte("apple\nbanana\n"), 0600))
+	assert.True(t, m.isValidCache(path, source))
+
+	require.NoError(t, os.WriteFile(path, []byte("apple\nbanane\n"), 0600))
+	assert.False(t, m.isValidCache(path, source))
+
+	unpinned := WordlistSource{ID: "good"}
+	assert.False(t, m.isValidCache(path, unpinned))
+	m.SetAllowUnpinned(true)
+	assert.True(t, m.isValidCache(path, unpinned))
+}
@@ -3,21 +3,24 @@
 package wordlist
 
 import (
-	"bufio"
-	"bytes"
+	"cmp"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"math/bits"
 	"net/http"
 	"os"
 	"path/filepath"
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/greysquirr3l/glyphic/internal/security"
 )
 
 var (
@@ -32,75 +35,88 @@ var (
 
 	// ErrSourceNotFound indicates a wordlist source was not found
 	ErrSourceNotFound = errors.New("wordlist source not found")
+
+	// ErrUnpinnedSource indicates a source has no pinned checksum and unpinned sources are not allowed
+	ErrUnpinnedSource = errors.New("wordlist source has no pinned checksum")
+
+	// ErrSelectionTooLarge indicates too many lists to compute a weighted selection distribution exactly
+	ErrSelectionTooLarge = errors.New("too many wordlists for exact selection distribution")
 )
 
 // WordlistSource represents a verified source for wordlist data
 type WordlistSource struct {
-	ID          string // Unique identifier
-	Name        string // Display name
-	URL         string // HTTPS download URL
-	SHA256      string // Expected SHA-256 checksum
-	WordCount   int    // Expected number of words
-	MinLength   int    // Minimum word length
-	MaxLength   int    // Maximum word length
-	Description string // Human-readable description
-	Category    string // "general", "technical", "nature", "phonetic", etc.
-	Language    string // "en", "es", etc.
-}
-
-// DefaultSources contains verified wordlist sources
-var DefaultSources = []WordlistSource{
-	{
-		ID:          "eff-large",
-		Name:        "EFF Large Wordlist",
-		URL:         "https://www.eff.org/files/2016/07/18/eff_large_wordlist.txt",
-		SHA256:      "replacewithactual", // Replace with actual checksum
-		WordCount:   7776,
-		MinLength:   3,
-		MaxLength:   9,
-		Description: "EFF's large wordlist for memorable passphrases",
-		Category:    "general",
-		Language:    "en",
-	},
-	{
-		ID:          "eff-short-1",
-		Name:        "EFF Short Wordlist 1",
-		URL:         "https://www.eff.org/files/2016/09/08/eff_short_wordlist_1.txt",
-		SHA256:      "replacewithactual",
-		WordCount:   1296,
-		MinLength:   4,
-		MaxLength:   5,
-		Description: "EFF's short wordlist with unique prefixes",
-		Category:    "general",
-		Language:    "en",
-	},
-	{
-		ID:          "eff-short-2",
-		Name:        "EFF Short Wordlist 2",
-		URL:         "https://www.eff.org/files/2016/09/08/eff_short_wordlist_2_0.txt",
-		SHA256:      "replacewithactual",
-		WordCount:   1296,
-		MinLength:   3,
-		MaxLength:   5,
-		Description: "EFF's alternative short wordlist",
-		Category:    "general",
-		Language:    "en",
-	},
-}
-
-// Wordlist represents a loaded wordlist
+	ID          string `json:"id"`          // Unique identifier
+	Name        string `json:"name"`        // Display name
+	URL         string `json:"url"`         // HTTPS download URL
+	SHA256      string `json:"sha256"`      // Expected SHA-256 checksum, empty if unpinned
+	WordCount   int    `json:"word_count"`  // Expected number of words
+	MinLength   int    `json:"min_length"`  // Minimum word length
+	MaxLength   int    `json:"max_length"`  // Maximum word length
+	Description string `json:"description"` // Human-readable description
+	Category    string `json:"category"`    // "general", "technical", "nature", "phonetic", etc.
+	Language    string `json:"language"`    // "en", "es", etc.
+
+	// PublicKey is a minisign or base64 Ed25519 key whose detached signature
+	// must verify the list, empty for lists pinned by checksum alone
+	PublicKey string `json:"public_key,omitempty"`
+
+	// SignatureURL is the HTTPS URL of the detached signature, by default the
+	// list URL with ".minisig" (minisign keys) or ".sig" (bare keys) appended
+	SignatureURL string `json:"signature_url,omitempty"`
+}
+
+// Pinned reports whether the source has an expected SHA-256 checksum or a
+// signing key
+func (s WordlistSource) Pinned() bool {
+	return s.SHA256 != "" || s.PublicKey != ""
+}
+
+// signatureURL returns the URL of the source's detached signature
+func (s WordlistSource) signatureURL(key *PublicKey) string {
+	if s.SignatureURL != "" {
+		return s.SignatureURL
+	}
+	if key.Minisign() {
+		return s.URL + ".minisig"
+	}
+	return s.URL + ".sig"
+}
+
+// DefaultSources contains verified wordlist sources from the embedded manifest
+var DefaultSources = embeddedManifest.Sources
+
+// Wordlist represents a loaded wordlist. Words is shared and must be treated as read-only.
 type Wordlist struct {
 	Source *WordlistSource
 	Words  []string
+
+	// Dice maps dice rolls such as "11111" to words, nil when the source has no
+	// complete dice index. Rolls of words that failed validation map to "".
+	Dice map[string]string
+
+	// Tags maps part-of-speech tags such as "adj" or "noun" to their sorted
+	// words, nil when the list has no tag column
+	Tags map[string][]string
+
+	// Extra maps words to the extra columns of JSON and CSV lists, such as
+	// "frequency", nil when the list has none
+	Extra map[string]map[string]string
 }
 
 // Manager handles wordlist fetching, caching, and loading
 type Manager struct {
-	cacheDir string
-	sources  []WordlistSource
-	loaded   map[string]*Wordlist
-	client   *http.Client
-	mu       sync.RWMutex
+	cacheDir      string
+	sources       []WordlistSource
+	loaded        map[string]*Wordlist
+	client        *http.Client
+	allowUnpinned bool
+	language      string
+	fold          bool
+	ttl           time.Duration
+	disabled      map[string]bool
+	registered    []WordlistSource
+	version       atomic.Uint64
+	mu            sync.RWMutex
 }
 
 // NewManager creates a new wordlist manager
@@ -121,7 +137,9 @@ func NewManager(cacheDir string) (*Manager, error) {
 
 	return &Manager{
 		cacheDir: cacheDir,
-		sources:  slices.Clone(DefaultSources),
+		sources:  sourcesFor(DefaultSources, DefaultLanguage),
+		language: DefaultLanguage,
+		ttl:      DefaultTTL,
 		loaded:   make(map[string]*Wordlist),
 		client: &http.Client{
 			Timeout: 30 * time.Second,
@@ -129,11 +147,99 @@ func NewManager(cacheDir string) (*Manager, error) {
 	}, nil
 }
 
-// EnsureWordlists fetches any missing wordlists (called on first run)
+// SetAllowUnpinned controls whether sources without a pinned checksum may be
+// fetched and loaded. Unpinned sources are refused by default.
+func (m *Manager) SetAllowUnpinned(allow bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.allowUnpinned = allow
+}
+
+// SetLanguage selects the default and registered sources in language, such
+// as "de", and the case rules of user wordlists. An empty language selects
+// every source. Call it before LoadAll.
+func (m *Manager) SetLanguage(language string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.language = language
+	m.sources = sourcesFor(slices.Concat(DefaultSources, m.registered), language)
+}
+
+// SetFoldDiacritics controls whether diacritics are stripped from Latin
+// letters as lists are loaded, so "café" becomes "cafe". Folded words are
+// easier to type but may merge, which shrinks a list. Call it before LoadAll.
+func (m *Manager) SetFoldDiacritics(fold bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fold = fold
+}
+
+// sourcesFor returns the sources in language, all of them when the language
+// is empty
+func sourcesFor(sources []WordlistSource, language string) []WordlistSource {
+	var matching []WordlistSource
+	for _, source := range sources {
+		if language == "" || baseLanguage(source.Language) == baseLanguage(language) {
+			matching = append(matching, source)
+		}
+	}
+	return matching
+}
+
+// Languages returns the sorted languages of the default sources
+func Languages() []string {
+	var languages []string
+	for _, source := range DefaultSources {
+		languages = append(languages, source.Language)
+	}
+	slices.Sort(languages)
+	return slices.Compact(languages)
+}
+
+// UseSources restricts the configured sources to the given IDs, for callers
+// that must draw from a fixed set of lists
+func (m *Manager) UseSources(ids ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sources := make([]WordlistSource, 0, len(ids))
+	for _, id := range ids {
+		i := slices.IndexFunc(m.sources, func(s WordlistSource) bool { return s.ID == id })
+		if i < 0 {
+			return fmt.Errorf("%w: %s", ErrSourceNotFound, id)
+		}
+		sources = append(sources, m.sources[i])
+	}
+	m.sources = sources
+	return nil
+}
+
+// Version returns a counter that changes whenever the set of loaded wordlists changes
+func (m *Manager) Version() uint64 {
+	return m.version.Load()
+}
+
+// UnpinnedSources returns the IDs of configured sources without a pinned checksum
+func (m *Manager) UnpinnedSources() []string {
+	var ids []string
+	for _, source := range m.sources {
+		if !source.Pinned() {
+			ids = append(ids, source.ID)
+		}
+	}
+	return ids
+}
+
+// EnsureWordlists fetches any missing wordlists that are not embedded in the binary
 func (m *Manager) EnsureWordlists(ctx context.Context) error {
 	var errs []error
+	var fetched int
 
 	for _, source := range m.sources {
+		if IsEmbedded(source) || !m.Enabled(source.ID) {
+			continue
+		}
+
 		cachePath := m.cachePath(source.ID)
 
 		// Check if cached and valid
@@ -142,62 +248,148 @@ func (m *Manager) EnsureWordlists(ctx context.Context) error {
 		}
 
 		// Fetch and cache
-		if err := m.fetchAndCache(ctx, source); err != nil {
+		fetched++
+		if _, err := m.fetchAndCache(ctx, source); err != nil {
 			errs = append(errs, fmt.Errorf("source %s: %w", source.ID, err))
 		}
 	}
 
-	if len(errs) > 0 && len(errs) == len(m.sources) {
-		return fmt.Errorf("failed to fetch all wordlists: %v", errs)
+	if len(errs) > 0 && len(errs) == fetched {
+		return fmt.Errorf("failed to fetch all wordlists: %w", errors.Join(errs...))
 	}
 
 	return nil
 }
 
-// fetchAndCache downloads and caches a wordlist
-func (m *Manager) fetchAndCache(ctx context.Context, source WordlistSource) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source.URL, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+// fetchAndCache downloads a wordlist and, for signed sources, its detached
+// signature, verifies both and caches them with a metadata sidecar, returning
+// the decoded file. A cached copy is revalidated with a conditional request
+// and kept when the server answers 304 Not Modified.
+func (m *Manager) fetchAndCache(ctx context.Context, source WordlistSource) (*File, error) {
+	if !source.Pinned() && !m.allowUnpinned {
+		return nil, ErrUnpinnedSource
 	}
 
-	resp, err := m.client.Do(req)
+	cached := m.revalidatable(source)
+	resp, err := m.download(ctx, source.URL, cached)
 	if err != nil {
-		return fmt.Errorf("failed to fetch wordlist: %w", err)
+		return nil, err
 	}
-	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	data := resp.data
+	var signature []byte
+	if resp.notModified {
+		if data, err = os.ReadFile(m.cachePath(source.ID)); err != nil {
+			return nil, fmt.Errorf("failed to read cache: %w", err)
+		}
+	} else {
+		if err := verifyChecksum(data, source); err != nil {
+			return nil, err
+		}
+
+		if source.PublicKey != "" {
+			key, err := ParsePublicKey(source.PublicKey)
+			if err != nil {
+				return nil, err
+			}
+			sig, err := m.download(ctx, source.signatureURL(key), nil)
+			if err != nil {
+				return nil, fmt.Errorf("signature: %w", err)
+			}
+			if err := key.Verify(data, sig.data); err != nil {
+				return nil, err
+			}
+			signature = sig.data
+		}
 	}
 
-	// Read and compute checksum
-	data, err := io.ReadAll(resp.Body)
+	// Parse and validate wordlist
+	file, err := Decode(source.URL, data)
 	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+		return nil, err
+	}
+	if len(entryWords(file.Entries, wordForm{language: source.Language, maxLength: source.MaxLength})) == 0 {
+		return nil, ErrInvalidWordlist
 	}
 
-	checksum := sha256.Sum256(data)
-	checksumHex := hex.EncodeToString(checksum[:])
+	// Write to cache, the signature first so a list is never cached without it
+	if !resp.notModified {
+		if signature != nil {
+			if err := writeFileAtomic(m.signaturePath(source.ID), signature); err != nil {
+				return nil, fmt.Errorf("failed to write cache: %w", err)
+			}
+		}
+		if err := writeFileAtomic(m.cachePath(source.ID), data); err != nil {
+			return nil, fmt.Errorf("failed to write cache: %w", err)
+		}
+	}
 
-	// Verify checksum if provided
-	if source.SHA256 != "replacewithactual" && source.SHA256 != checksumHex {
-		return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, source.SHA256, checksumHex)
+	sum := sha256.Sum256(data)
+	meta := &cacheMeta{
+		ETag:         resp.etag,
+		LastModified: resp.lastModified,
+		SHA256:       hex.EncodeToString(sum[:]),
+		FetchedAt:    time.Now().UTC(),
+	}
+	if resp.notModified {
+		// A 304 need not repeat the validators
+		meta.ETag = cmp.Or(meta.ETag, cached.ETag)
+		meta.LastModified = cmp.Or(meta.LastModified, cached.LastModified)
+	}
+	if err := m.writeMeta(source.ID, meta); err != nil {
+		return nil, err
 	}
 
-	// Parse and validate wordlist
-	words := parseWordlist(data, source.ID)
-	if len(words) == 0 {
-		return ErrInvalidWordlist
+	return file, nil
+}
+
+// response is a downloaded body with the validators to revalidate it later
+type response struct {
+	data         []byte
+	etag         string
+	lastModified string
+	notModified  bool // the copy described by the request's validators is current
+}
+
+// download fetches a URL, rejecting bodies larger than maxWordlistSize. With
+// cached validators the request is conditional, and a 304 Not Modified answer
+// is returned without a body.
+func (m *Manager) download(ctx context.Context, url string, cached *cacheMeta) (*response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
 	}
 
-	// Write to cache
-	cachePath := m.cachePath(source.ID)
-	if err := os.WriteFile(cachePath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write cache: %w", err)
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch wordlist: %w", err)
 	}
+	defer func() { _ = resp.Body.Close() }()
 
-	return nil
+	result := &response{etag: resp.Header.Get("ETag"), lastModified: resp.Header.Get("Last-Modified")}
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		result.notModified = true
+		return result, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if result.data, err = io.ReadAll(io.LimitReader(resp.Body, maxWordlistSize+1)); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(result.data) > maxWordlistSize {
+		return nil, fmt.Errorf("%w: response exceeds %d bytes", ErrInvalidWordlist, maxWordlistSize)
+	}
+	return result, nil
 }
 
 // cachePath returns the cache file path for a wordlist ID
@@ -205,7 +397,13 @@ func (m *Manager) cachePath(id string) string {
 	return filepath.Join(m.cacheDir, id+".txt")
 }
 
-// isValidCache checks if a cached wordlist is valid
+// signaturePath returns the cache file path for the signature of a wordlist ID
+func (m *Manager) signaturePath(id string) string {
+	return filepath.Join(m.cacheDir, id+".sig")
+}
+
+// isValidCache checks if a cached wordlist is present, within its TTL and
+// matches its pinned checksum and signature
 func (m *Manager) isValidCache(path string, source WordlistSource) bool {
 	info, err := os.Stat(path)
 	if err != nil {
@@ -217,18 +415,69 @@ func (m *Manager) isValidCache(path string, source WordlistSource) bool {
 		return false
 	}
 
-	// Check file age (re-fetch if older than 30 days)
-	if time.Since(info.ModTime()) > 30*24*time.Hour {
+	// Revalidate once the TTL has passed since the last download
+	if time.Since(m.fetchedAt(source.ID, info.ModTime())) >= m.ttl {
 		return false
 	}
 
-	return true
+	if !source.Pinned() {
+		return m.allowUnpinned
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	return m.verifyCached(data, source) == nil
 }
 
-// AvailableCount returns the number of available wordlists
+// verifyChecksum checks data against the source's pinned SHA-256 checksum.
+// Sources without one always pass; callers decide whether to trust them.
+func verifyChecksum(data []byte, source WordlistSource) error {
+	if source.SHA256 == "" {
+		return nil
+	}
+
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != source.SHA256 {
+		return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, source.SHA256, got)
+	}
+	return nil
+}
+
+// verifyCached checks a cached list against its pinned checksum and, for
+// signed sources, the cached signature
+func (m *Manager) verifyCached(data []byte, source WordlistSource) error {
+	if err := verifyChecksum(data, source); err != nil {
+		return err
+	}
+	if source.PublicKey == "" {
+		return nil
+	}
+
+	key, err := ParsePublicKey(source.PublicKey)
+	if err != nil {
+		return err
+	}
+	signature, err := os.ReadFile(m.signaturePath(source.ID))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	return key.Verify(data, signature)
+}
+
+// AvailableCount returns the number of enabled wordlists that are embedded or cached
 func (m *Manager) AvailableCount() int {
 	count := 0
 	for _, source := range m.sources {
+		if !m.Enabled(source.ID) {
+			continue
+		}
+		if IsEmbedded(source) {
+			count++
+			continue
+		}
 		if _, err := os.Stat(m.cachePath(source.ID)); err == nil {
 			count++
 		}
@@ -236,12 +485,32 @@ func (m *Manager) AvailableCount() int {
 	return count
 }
 
-// LoadAll loads all available wordlists into memory
+// LoadAll loads all available wordlists into memory. Embedded copies are
+// preferred; cached files are re-verified against their pinned checksum.
+// Unpinned sources are skipped unless allowed, and disabled ones are dropped.
 func (m *Manager) LoadAll() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	for _, source := range m.sources {
+		if m.disabled[source.ID] {
+			delete(m.loaded, source.ID)
+			continue
+		}
+
+		if data, ok := embeddedData(source); ok {
+			wl, err := m.decodeSource(&source, data)
+			if err != nil {
+				return err
+			}
+			m.loaded[source.ID] = wl
+			continue
+		}
+
+		if !source.Pinned() && !m.allowUnpinned {
+			continue
+		}
+
 		cachePath := m.cachePath(source.ID)
 		if _, err := os.Stat(cachePath); err != nil {
 			continue // Skip missing wordlists
@@ -252,34 +521,200 @@ func (m *Manager) LoadAll() error {
 			return fmt.Errorf("failed to read cache %s: %w", source.ID, err)
 		}
 
-		words := parseWordlist(data, source.ID)
-		m.loaded[source.ID] = &Wordlist{
-			Source: &source,
-			Words:  words,
+		if err := m.verifyCached(data, source); err != nil {
+			return fmt.Errorf("cache %s: %w", source.ID, err)
 		}
+
+		wl, err := m.decodeSource(&source, data)
+		if err != nil {
+			return err
+		}
+		m.loaded[source.ID] = wl
 	}
 
+	m.version.Add(1)
 	return nil
 }
 
-// SelectRandomLists returns n randomly selected wordlists
+// SelectOptions controls how SelectLists chooses wordlists
+type SelectOptions struct {
+	// WeightBySize draws lists with probability proportional to their word count
+	// instead of uniformly, so larger lists contribute more often
+	WeightBySize bool
+
+	// Rand supplies the random choices, crypto/rand when nil
+	Rand security.RandomSource
+}
+
+// SelectionOdds gives the probability that a wordlist fills each position of a selection
+type SelectionOdds struct {
+	List     *Wordlist
+	Position []float64 // Position[j] is the probability the list is the j-th one selected
+}
+
+// maxExactWeightedLists bounds the subset enumeration in SelectionDistribution
+const maxExactWeightedLists = 16
+
+// SelectRandomLists returns n uniformly and securely selected wordlists
 func (m *Manager) SelectRandomLists(n int) ([]*Wordlist, error) {
+	return m.SelectLists(n, SelectOptions{})
+}
+
+// SelectLists returns n distinct wordlists in random order using opts.Rand
+func (m *Manager) SelectLists(n int, opts SelectOptions) ([]*Wordlist, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	if len(m.loaded) < n {
-		return nil, fmt.Errorf("%w: need %d, have %d", ErrInsufficientLists, n, len(m.loaded))
+	available, err := m.selectable(n, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.WeightBySize {
+		if err := security.Shuffle(opts.Rand, available); err != nil {
+			return nil, fmt.Errorf("failed to shuffle wordlists: %w", err)
+		}
+		return available[:n], nil
+	}
+
+	// Successive sampling without replacement, proportional to size
+	total := 0
+	for _, wl := range available {
+		total += len(wl.Words)
+	}
+
+	selected := make([]*Wordlist, 0, n)
+	for range n {
+		r, err := security.RandomIndex(opts.Rand, total)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select wordlist: %w", err)
+		}
+
+		for i, wl := range available {
+			if r < len(wl.Words) {
+				selected = append(selected, wl)
+				total -= len(wl.Words)
+				available = slices.Delete(available, i, i+1)
+				break
+			}
+			r -= len(wl.Words)
+		}
+	}
+
+	return selected, nil
+}
+
+// SelectionDistribution returns, for every list SelectLists may choose, the exact
+// probability of it landing in each of the n positions. Weighted selection over
+// more than maxExactWeightedLists lists returns ErrSelectionTooLarge.
+func (m *Manager) SelectionDistribution(n int, opts SelectOptions) ([]SelectionOdds, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	available, err := m.selectable(n, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	odds := make([]SelectionOdds, len(available))
+	for i, wl := range available {
+		odds[i] = SelectionOdds{List: wl, Position: make([]float64, n)}
+	}
+
+	if !opts.WeightBySize {
+		// A uniform shuffle puts every list in every position equally often
+		for i := range odds {
+			for j := range n {
+				odds[i].Position[j] = 1 / float64(len(available))
+			}
+		}
+		return odds, nil
+	}
+
+	if len(available) > maxExactWeightedLists {
+		return nil, fmt.Errorf("%w: %d lists", ErrSelectionTooLarge, len(available))
+	}
+
+	// prob[mask] is the probability that the first popcount(mask) picks are exactly mask
+	weights := make([]float64, len(available))
+	var total float64
+	for i, wl := range available {
+		weights[i] = float64(len(wl.Words))
+		total += weights[i]
+	}
+
+	prob := make([]float64, 1<<len(available))
+	prob[0] = 1
+	for mask := range prob {
+		pos := bits.OnesCount(uint(mask))
+		if prob[mask] == 0 || pos >= n {
+			continue
+		}
+
+		remaining := total
+		for i := range available {
+			if mask&(1<<i) != 0 {
+				remaining -= weights[i]
+			}
+		}
+
+		for i := range available {
+			if mask&(1<<i) != 0 {
+				continue
+			}
+			p := prob[mask] * weights[i] / remaining
+			odds[i].Position[pos] += p
+			prob[mask|1<<i] += p
+		}
+	}
+
+	return odds, nil
+}
+
+// selectable returns the loaded lists eligible for selection, sorted by ID so the
+// result never depends on map iteration order. Callers must hold m.mu.
+func (m *Manager) selectable(n int, opts SelectOptions) ([]*Wordlist, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: need at least 1, requested %d", ErrInsufficientLists, n)
 	}
 
-	// Get all available wordlists
 	available := make([]*Wordlist, 0, len(m.loaded))
 	for _, wl := range m.loaded {
-		available = append(available, wl)
+		if m.isSelectable(wl, opts) {
+			available = append(available, wl)
+		}
 	}
 
-	// TODO: Use secure random selection from internal/security
-	// For now, return first n wordlists
-	return available[:n], nil
+	if len(available) < n {
+		return nil, fmt.Errorf("%w: need %d, have %d", ErrInsufficientLists, n, len(available))
+	}
+
+	slices.SortFunc(available, func(a, b *Wordlist) int {
+		return strings.Compare(a.Source.ID, b.Source.ID)
+	})
+
+	return available, nil
+}
+
+// isSelectable reports whether a loaded list may be selected with opts.
+// Callers must hold m.mu.
+func (m *Manager) isSelectable(wl *Wordlist, opts SelectOptions) bool {
+	// A weighted draw can never pick an empty list
+	return !m.disabled[wl.Source.ID] && (!opts.WeightBySize || len(wl.Words) > 0)
+}
+
+// SelectableCount returns how many loaded lists SelectLists may choose from with opts
+func (m *Manager) SelectableCount(opts SelectOptions) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	count := 0
+	for _, wl := range m.loaded {
+		if m.isSelectable(wl, opts) {
+			count++
+		}
+	}
+	return count
 }
 
 // AddUserWordlist adds a user-provided wordlist
@@ -289,70 +724,80 @@ func (m *Manager) AddUserWordlist(path, id string) error {
 		return fmt.Errorf("failed to read wordlist: %w", err)
 	}
 
-	words := parseWordlist(data, id)
-	if len(words) == 0 {
-		return ErrInvalidWordlist
+	file, err := Decode(path, data)
+	if err != nil {
+		return err
 	}
 
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.loaded[id] = &Wordlist{
-		Source: &WordlistSource{
-			ID:          id,
-			Name:        filepath.Base(path),
-			Description: "User-provided wordlist",
-			Category:    "custom",
-		},
-		Words: words,
+	source := &WordlistSource{
+		ID:          id,
+		Name:        cmp.Or(file.Header.Name, filepath.Base(path)),
+		WordCount:   len(file.Entries),
+		Description: "User-provided wordlist",
+		Category:    cmp.Or(file.Header.Category, "custom"),
+		Language:    cmp.Or(file.Header.Language, m.language),
 	}
+	wl := newWordlist(source, file, m.form(source))
+	if len(wl.Words) == 0 {
+		return ErrInvalidWordlist
+	}
+
+	m.loaded[id] = wl
+	m.version.Add(1)
 
 	return nil
 }
 
+// Loaded returns the loaded wordlist with the given ID
+func (m *Manager) Loaded(id string) (*Wordlist, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	wl, ok := m.loaded[id]
+	return wl, ok
+}
+
 // ListSources returns information about all configured sources
 func (m *Manager) ListSources() []WordlistSource {
 	return slices.Clone(m.sources)
 }
 
-// EnableSource enables or disables a wordlist source
-func (m *Manager) EnableSource(id string, enabled bool) bool {
-	for i := range m.sources {
-		if m.sources[i].ID == id {
-			// Implementation would track enabled/disabled state
-			return true
-		}
-	}
-	return false
+// form returns the normalisation of the words of source. Callers must hold m.mu.
+func (m *Manager) form(source *WordlistSource) wordForm {
+	return wordForm{language: source.Language, fold: m.fold, maxLength: source.MaxLength}
 }
 
-// parseWordlist parses wordlist data into a slice of words
-func parseWordlist(data []byte, sourceID string) []string {
-	scanner := bufio.NewScanner(bytes.NewReader(data))
-	words := make([]string, 0, 8192)
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
+// decodeSource decodes the file of a configured source. Callers must hold m.mu.
+func (m *Manager) decodeSource(source *WordlistSource, data []byte) (*Wordlist, error) {
+	file, err := Decode(source.URL, data)
+	if err != nil {
+		return nil, fmt.Errorf("wordlist %s: %w", source.ID, err)
+	}
+	form := m.form(source)
+	wl := newWordlist(source, file, form)
+	addSidecarTags(wl, form)
+	return wl, nil
+}
 
-		// Handle EFF format (number followed by word)
-		fields := strings.Fields(line)
-		var word string
-		if len(fields) >= 2 && isAllDigits(fields[0]) {
-			word = fields[1] // EFF format: "11111 word"
-		} else if len(fields) == 1 {
-			word = fields[0] // Plain word format
-		} else {
-			continue // Skip invalid lines
-		}
+// newWordlist builds a wordlist from the entries of a decoded file in form
+func newWordlist(source *WordlistSource, file *File, form wordForm) *Wordlist {
+	return &Wordlist{
+		Source: source,
+		Words:  entryWords(file.Entries, form),
+		Dice:   diceIndex(file.Entries, form),
+		Tags:   entryTags(file.Entries, form),
+		Extra:  entryExtra(file.Entries, form),
+	}
+}
 
-		// Validate word
-		if isValidWord(word) {
-			words = append(words, strings.ToLower(word))
+// entryWords returns the valid words of entries in form, sorted and distinct
+func entryWords(entries []Entry, form wordForm) []string {
+	words := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if word, ok := form.normalize(entry.Word); ok {
+			words = append(words, word)
 		}
 	}
 
@@ -363,31 +808,37 @@ func parseWordlist(data []byte, sourceID string) []string {
 	return slices.Compact(words)
 }
 
-// isAllDigits returns true if the string contains only digits
-func isAllDigits(s string) bool {
-	if len(s) == 0 {
-		return false
-	}
-	for _, r := range s {
-		if r < '0' || r > '9' {
-			return false
+// entryExtra maps the valid words of entries in form to their extra columns,
+// nil when no entry has any. The first entry of a word wins.
+func entryExtra(entries []Entry, form wordForm) map[string]map[string]string {
+	var extra map[string]map[string]string
+	for _, entry := range entries {
+		if len(entry.Extra) == 0 {
+			continue
+		}
+		word, ok := form.normalize(entry.Word)
+		if !ok {
+			continue
+		}
+		if extra == nil {
+			extra = make(map[string]map[string]string)
+		}
+		if _, dup := extra[word]; !dup {
+			extra[word] = entry.Extra
 		}
 	}
-	return true
+	return extra
 }
 
-// isValidWord validates a word for inclusion in the wordlist
-func isValidWord(s string) bool {
-	if len(s) < 2 || len(s) > 12 {
+// isAllDigits returns true if the string contains only digits
+func isAllDigits(s string) bool {
+	if len(s) == 0 {
 		return false
 	}
-
-	// Must contain only letters
 	for _, r := range s {
-		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+		if r < '0' || r > '9' {
 			return false
 		}
 	}
-
 	return true
 }
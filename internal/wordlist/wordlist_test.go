@@ -2,6 +2,9 @@ package wordlist
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
@@ -45,10 +48,9 @@ func TestNewManager(t *testing.T) {
 
 func TestParseWordlist(t *testing.T) {
 	tests := []struct {
-		name     string
-		data     []byte
-		sourceID string
-		want     int // expected word count
+		name string
+		data []byte
+		want int // expected word count
 	}{
 		{
 			name: "EFF format",
@@ -56,8 +58,7 @@ func TestParseWordlist(t *testing.T) {
 11112	abdomen
 11113	abdominal
 11114	abide`),
-			sourceID: "test-eff",
-			want:     4,
+			want: 4,
 		},
 		{
 			name: "plain word format",
@@ -65,8 +66,7 @@ func TestParseWordlist(t *testing.T) {
 banana
 cherry
 date`),
-			sourceID: "test-plain",
-			want:     4,
+			want: 4,
 		},
 		{
 			name: "with comments and empty lines",
@@ -76,8 +76,7 @@ apple
 banana
 # Another comment
 cherry`),
-			sourceID: "test-comments",
-			want:     3,
+			want: 3,
 		},
 		{
 			name: "with duplicates",
@@ -85,14 +84,13 @@ cherry`),
 banana
 apple
 cherry`),
-			sourceID: "test-dupes",
-			want:     3, // duplicates removed
+			want: 3, // duplicates removed
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			words := parseWordlist(tt.data, tt.sourceID)
+			words := entryWords(decodeEntries(t, tt.data), wordForm{})
 			assert.Len(t, words, tt.want)
 
 			// Verify words are sorted
@@ -118,11 +116,18 @@ func TestIsValidWord(t *testing.T) {
 		{"mixed case", "Hello", true},
 		{"minimum length", "hi", true},
 		{"maximum length", "abcdefghijkl", true},
+		{"accented", "año", true},
+		{"combining mark", "an\u0303o", true},
+		{"leading mark", "\u0303ano", false},
+		{"kana", "さくら", true},
+		{"maximum runes", "überraschung", true},
+		{"long compound", "abfallbeseitigung", false},
+		{"apostrophe", "aujourd'hui", false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := isValidWord(tt.word)
+			got := isValidWord(tt.word, maxWordLength)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -181,13 +186,34 @@ func TestAddUserWordlist(t *testing.T) {
 	assert.Len(t, wl.Words, 3)
 }
 
+func TestManagerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "custom.txt")
+	require.NoError(t, os.WriteFile(path, []byte("apple\nbanana\n"), 0600))
+
+	m, err := NewManager(t.TempDir())
+	require.NoError(t, err)
+	v := m.Version()
+
+	require.NoError(t, m.AddUserWordlist(path, "custom"))
+	assert.Greater(t, m.Version(), v)
+	v = m.Version()
+
+	require.NoError(t, m.LoadAll())
+	assert.Greater(t, m.Version(), v)
+	v = m.Version()
+
+	_, err = m.SelectRandomLists(1)
+	require.NoError(t, err)
+	assert.Equal(t, v, m.Version())
+}
+
 func TestAvailableCount(t *testing.T) {
 	cacheDir := t.TempDir()
 	m, err := NewManager(cacheDir)
 	require.NoError(t, err)
 
-	// Initially should be 0
-	assert.Equal(t, 0, m.AvailableCount())
+	// Initially only the embedded wordlists are available
+	assert.Equal(t, len(EmbeddedIDs(DefaultLanguage)), m.AvailableCount())
 
 	// Create a fake cached wordlist
 	cachePath := m.cachePath("test")
@@ -205,13 +231,27 @@ func TestManagerListSources(t *testing.T) {
 
 	sources := m.ListSources()
 	assert.NotEmpty(t, sources)
-	assert.Equal(t, len(DefaultSources), len(sources))
+	for _, source := range sources {
+		assert.Equal(t, DefaultLanguage, source.Language)
+	}
 
 	// Verify it's a clone (modifying it doesn't affect manager)
 	sources[0].Name = "modified"
 	assert.NotEqual(t, "modified", m.sources[0].Name)
 }
 
+func TestManagerUseSources(t *testing.T) {
+	m, err := NewManager(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, m.UseSources("eff-large"))
+	sources := m.ListSources()
+	require.Len(t, sources, 1)
+	assert.Equal(t, "eff-large", sources[0].ID)
+
+	assert.ErrorIs(t, m.UseSources("eff-short-2"), ErrSourceNotFound)
+}
+
 func TestEnsureWordlistsWithMockServer(t *testing.T) {
 	// This is a basic structure test
 	// In production, you'd use httptest to mock the server
@@ -228,3 +268,195 @@ func TestEnsureWordlistsWithMockServer(t *testing.T) {
 	// The important thing is it doesn't panic
 	assert.NotNil(t, m)
 }
+
+func TestEnsureWordlistsVerifiesChecksums(t *testing.T) {
+	const content = "apple\nbanana\ncherry\n"
+	var hits int
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		_, _ = io.WriteString(w, content)
+	}))
+	defer server.Close()
+
+	newManager := func(t *testing.T, sum string) *Manager {
+		m, err := NewManager(t.TempDir())
+		require.NoError(t, err)
+		m.client = server.Client()
+		m.sources = []WordlistSource{{ID: "test", URL: server.URL + "/test.txt", SHA256: sum}}
+		return m
+	}
+
+	t.Run("pinned match is cached", func(t *testing.T) {
+		m := newManager(t, sha256Hex([]byte(content)))
+		require.NoError(t, m.EnsureWordlists(context.Background()))
+		data, err := os.ReadFile(m.cachePath("test"))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+	})
+
+	t.Run("pinned mismatch is rejected", func(t *testing.T) {
+		m := newManager(t, sha256Hex([]byte("something else")))
+		err := m.EnsureWordlists(context.Background())
+		assert.ErrorIs(t, err, ErrChecksumMismatch)
+		assert.NoFileExists(t, m.cachePath("test"))
+	})
+
+	t.Run("unpinned refused without fetching", func(t *testing.T) {
+		m := newManager(t, "")
+		before := hits
+		err := m.EnsureWordlists(context.Background())
+		assert.ErrorIs(t, err, ErrUnpinnedSource)
+		assert.Equal(t, before, hits)
+		assert.NoFileExists(t, m.cachePath("test"))
+	})
+
+	t.Run("unpinned allowed by opt-in", func(t *testing.T) {
+		m := newManager(t, "")
+		m.SetAllowUnpinned(true)
+		require.NoError(t, m.EnsureWordlists(context.Background()))
+		assert.FileExists(t, m.cachePath("test"))
+	})
+
+	t.Run("tampered cache is refetched", func(t *testing.T) {
+		m := newManager(t, sha256Hex([]byte(content)))
+		require.NoError(t, os.WriteFile(m.cachePath("test"), []byte("tampered\n"), 0600))
+		before := hits
+		require.NoError(t, m.EnsureWordlists(context.Background()))
+		assert.Equal(t, before+1, hits)
+		data, err := os.ReadFile(m.cachePath("test"))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+	})
+}
+
+// newSelectionManager returns a manager with lists of the given sizes loaded directly
+func newSelectionManager(t *testing.T, sizes map[string]int) *Manager {
+	t.Helper()
+
+	m, err := NewManager(t.TempDir())
+	require.NoError(t, err)
+	for id, size := range sizes {
+		m.loaded[id] = &Wordlist{
+			Source: &WordlistSource{ID: id},
+			Words:  make([]string, size),
+		}
+	}
+	return m
+}
+
+func TestSelectLists(t *testing.T) {
+	m := newSelectionManager(t, map[string]int{"a": 10, "b": 10, "c": 10, "d": 10})
+
+	t.Run("distinct lists", func(t *testing.T) {
+		for range 50 {
+			lists, err := m.SelectRandomLists(3)
+			require.NoError(t, err)
+			require.Len(t, lists, 3)
+			assert.NotEqual(t, lists[0].Source.ID, lists[1].Source.ID)
+			assert.NotEqual(t, lists[1].Source.ID, lists[2].Source.ID)
+			assert.NotEqual(t, lists[0].Source.ID, lists[2].Source.ID)
+		}
+	})
+
+	t.Run("every list can come first", func(t *testing.T) {
+		first := make(map[string]int)
+		for range 400 {
+			lists, err := m.SelectRandomLists(1)
+			require.NoError(t, err)
+			first[lists[0].Source.ID]++
+		}
+		assert.Len(t, first, 4)
+	})
+
+	t.Run("insufficient lists", func(t *testing.T) {
+		_, err := m.SelectRandomLists(5)
+		assert.ErrorIs(t, err, ErrInsufficientLists)
+		_, err = m.SelectRandomLists(0)
+		assert.ErrorIs(t, err, ErrInsufficientLists)
+	})
+}
+
+func TestSelectListsWeighted(t *testing.T) {
+	m := newSelectionManager(t, map[string]int{"small": 100, "medium": 300, "large": 600, "empty": 0})
+	opts := SelectOptions{WeightBySize: true}
+
+	t.Run("skips empty lists", func(t *testing.T) {
+		lists, err := m.SelectLists(3, opts)
+		require.NoError(t, err)
+		ids := []string{lists[0].Source.ID, lists[1].Source.ID, lists[2].Source.ID}
+		assert.ElementsMatch(t, []string{"small", "medium", "large"}, ids)
+
+		_, err = m.SelectLists(4, opts)
+		assert.ErrorIs(t, err, ErrInsufficientLists)
+
+		assert.Equal(t, 3, m.SelectableCount(opts))
+		assert.Equal(t, 4, m.SelectableCount(SelectOptions{}))
+	})
+
+	t.Run("first pick proportional to size", func(t *testing.T) {
+		const draws = 20_000
+		first := make(map[string]int)
+		for range draws {
+			lists, err := m.SelectLists(1, opts)
+			require.NoError(t, err)
+			first[lists[0].Source.ID]++
+		}
+
+		// Binomial standard deviation is at most ~70, so 4% of draws is a generous bound
+		assert.InDelta(t, 0.1*draws, first["small"], 0.04*draws)
+		assert.InDelta(t, 0.3*draws, first["medium"], 0.04*draws)
+		assert.InDelta(t, 0.6*draws, first["large"], 0.04*draws)
+	})
+}
+
+func TestSelectionDistribution(t *testing.T) {
+	t.Run("uniform", func(t *testing.T) {
+		m := newSelectionManager(t, map[string]int{"a": 1, "b": 2, "c": 3, "d": 4})
+		odds, err := m.SelectionDistribution(2, SelectOptions{})
+		require.NoError(t, err)
+		require.Len(t, odds, 4)
+		for _, o := range odds {
+			assert.Equal(t, []float64{0.25, 0.25}, o.Position)
+		}
+	})
+
+	t.Run("weighted", func(t *testing.T) {
+		m := newSelectionManager(t, map[string]int{"a": 1, "b": 1, "c": 2})
+		odds, err := m.SelectionDistribution(2, SelectOptions{WeightBySize: true})
+		require.NoError(t, err)
+		require.Len(t, odds, 3)
+
+		// Second position: P(a) = P(b first)*1/3 + P(c first)*1/2 = 1/12 + 1/4
+		want := map[string][]float64{
+			"a": {0.25, 1.0 / 3},
+			"b": {0.25, 1.0 / 3},
+			"c": {0.5, 1.0 / 3},
+		}
+		for _, o := range odds {
+			assert.InDeltaSlice(t, want[o.List.Source.ID], o.Position, 1e-12, o.List.Source.ID)
+		}
+	})
+
+	t.Run("positions sum to one", func(t *testing.T) {
+		m := newSelectionManager(t, map[string]int{"a": 7, "b": 100, "c": 30, "d": 2, "e": 55})
+		odds, err := m.SelectionDistribution(4, SelectOptions{WeightBySize: true})
+		require.NoError(t, err)
+		for pos := range 4 {
+			var sum float64
+			for _, o := range odds {
+				sum += o.Position[pos]
+			}
+			assert.InDelta(t, 1.0, sum, 1e-12)
+		}
+	})
+
+	t.Run("too many lists", func(t *testing.T) {
+		sizes := make(map[string]int)
+		for i := range maxExactWeightedLists + 1 {
+			sizes[string(rune('a'+i))] = i + 1
+		}
+		m := newSelectionManager(t, sizes)
+		_, err := m.SelectionDistribution(3, SelectOptions{WeightBySize: true})
+		assert.ErrorIs(t, err, ErrSelectionTooLarge)
+	})
+}